@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	. "github.com/streamingfast/cli"
+	"github.com/streamingfast/kvdb/store"
+)
+
+var DriversCmd = Command(driversRunE,
+	"drivers",
+	"List the registered drivers, their capabilities and the DSN options they accept",
+	NoArgs(),
+)
+
+func driversRunE(cmd *cobra.Command, args []string) error {
+	for _, reg := range store.Registrations() {
+		title := reg.Title
+		if title == "" {
+			title = reg.Name
+		}
+
+		schemes := reg.Name + "://"
+		for _, alias := range reg.Aliases {
+			schemes += ", " + alias + "://"
+		}
+
+		fmt.Printf("%s (%s)\n", title, schemes)
+		if capabilities := reg.Capabilities.Names(); len(capabilities) > 0 {
+			fmt.Printf("  Capabilities: %s\n", strings.Join(capabilities, ", "))
+		}
+
+		options := reg.AllOptions()
+		if options == nil {
+			fmt.Println("  Options not declared, DSN is not validated")
+			fmt.Println("")
+			continue
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, option := range options {
+			fmt.Fprintf(writer, "  %s\t%s\t%s\t%s\n", optionName(option), option.Type, optionDefault(option.Default), optionDescription(option))
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		fmt.Println("")
+	}
+
+	return nil
+}
+
+func optionName(option *store.OptionSchema) string {
+	if len(option.Aliases) == 0 {
+		return option.Name
+	}
+	return fmt.Sprintf("%s (alias %s)", option.Name, joinQuoted(option.Aliases))
+}
+
+func optionDefault(value string) string {
+	if value == "" {
+		return "<unset>"
+	}
+	return value
+}
+
+func optionDescription(option *store.OptionSchema) string {
+	if len(option.Values) == 0 {
+		return option.Description
+	}
+	return fmt.Sprintf("%s (one of %s)", option.Description, joinQuoted(option.Values))
+}
+
+func joinQuoted(values []string) (out string) {
+	for i, value := range values {
+		if i > 0 {
+			out += ", "
+		}
+		out += fmt.Sprintf("%q", value)
+	}
+	return
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	. "github.com/streamingfast/cli"
+	"github.com/streamingfast/kvdb/store"
+)
+
+var DSNExplainCmd = Command(dsnExplainRunE,
+	"explain [<dsn>]",
+	"Validate a DSN and print the value each option of its driver resolves to",
+	Description(`
+		The DSN is the one received as argument, or the one of the --dsn flag when none is
+		given. Options set through an alias are reported under their actual name.
+	`),
+	MaximumNArgs(1),
+)
+
+func dsnExplainRunE(cmd *cobra.Command, args []string) error {
+	dsn := viper.GetString("global-dsn")
+	if len(args) > 0 {
+		dsn = args[0]
+	}
+	if dsn == "" {
+		return fmt.Errorf("dsn is required")
+	}
+
+	reg, options, err := store.ExplainDSN(dsn)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Driver\t->\t%s\n", reg.Name)
+	if options == nil {
+		fmt.Println("Driver does not declare its options, DSN is passed as-is")
+		return nil
+	}
+
+	fmt.Println("")
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "OPTION\tVALUE\tSOURCE")
+	for _, option := range options {
+		source := string(option.Source)
+		if option.Source == store.OptionSourceAlias {
+			source = fmt.Sprintf("alias %q", option.SetAs)
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", option.Name, optionDefault(option.Value), source)
+	}
+	return writer.Flush()
+}
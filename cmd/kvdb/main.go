@@ -53,6 +53,16 @@ func main() {
 			),
 		),
 
+		DriversCmd,
+
+		Group("dsn", "KVDB DSN commands",
+			DSNExplainCmd,
+		),
+
+		Group("serve", "KVDB server commands",
+			ServeRESPCmd,
+		),
+
 		PersistentFlags(
 			func(flags *pflag.FlagSet) {
 				flags.String("dsn", "", "URL to connect to the KV store. Supported schemes: 'badger3', 'badger', 'bigkv', 'tikv', 'netkv'. See https://github.com/streamingfast/kvdb for more details. (ex: 'badger3:///tmp/substreams-sink-kv-db')")
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	. "github.com/streamingfast/cli"
+	"github.com/streamingfast/kvdb/store/resp"
+	"go.uber.org/zap"
+)
+
+var ServeRESPCmd = Command(serveRESPRunE,
+	"resp",
+	"Serve the store of the --dsn flag over a subset of the Redis protocol",
+	Description(`
+		Supported commands are GET, SET, DEL, EXISTS, MGET, SCAN (MATCH limited to prefix
+		patterns like 'blk:*') and DBSIZE, so that redis-cli and Redis client libraries can
+		be used against the store. There is no authentication, keep the listen address
+		private.
+	`),
+	NoArgs(),
+	Flags(func(flags *pflag.FlagSet) {
+		flags.String("listen-addr", "127.0.0.1:6379", "Listening address of the Redis protocol server")
+	}),
+)
+
+func serveRESPRunE(cmd *cobra.Command, args []string) error {
+	kvdb, err := getKV()
+	if err != nil {
+		return err
+	}
+	defer kvdb.Close()
+
+	listenAddr := viper.GetString("serve-resp-listen-addr")
+	server, err := resp.Launch(listenAddr, kvdb)
+	if err != nil {
+		return err
+	}
+
+	zlog.Info("serving store over the redis protocol", zap.Stringer("addr", server.Addr()))
+	fmt.Printf("Listening %s\n", server.Addr())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	<-signals
+
+	return server.Close()
+}
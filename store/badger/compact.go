@@ -0,0 +1,33 @@
+package badger
+
+import (
+	"context"
+	"errors"
+	"runtime"
+
+	"github.com/dgraph-io/badger/v2"
+)
+
+// compactDiscardRatio is the share of stale data a value log file must hold to be
+// rewritten by `Compact`.
+const compactDiscardRatio = 0.5
+
+// Compact implements `store.Compactor`, the LSM tree is flattened to a single level
+// then the value log files holding enough stale data are rewritten.
+func (s *Store) Compact(ctx context.Context) error {
+	if err := s.db.Flatten(runtime.NumCPU()); err != nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		err := s.db.RunValueLogGC(compactDiscardRatio)
+		if errors.Is(err, badger.ErrNoRewrite) || errors.Is(err, badger.ErrGCInMemoryMode) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,76 @@
+package badger
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/dgraph-io/badger/v2/y"
+)
+
+// Count implements `store.Counter`, keys are counted without reading their value.
+func (s *Store) Count(ctx context.Context, start, exclusiveEnd []byte) (count uint64, err error) {
+	err = s.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(keyOnlyIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(start); it.Valid() && bytes.Compare(it.Item().Key(), exclusiveEnd) == -1; it.Next() {
+			count++
+		}
+		return ctx.Err()
+	})
+
+	return
+}
+
+// CountPrefix implements `store.Counter`, keys are counted without reading their value.
+func (s *Store) CountPrefix(ctx context.Context, prefix []byte) (count uint64, err error) {
+	err = s.db.View(func(txn *badger.Txn) error {
+		options := keyOnlyIteratorOptions
+		options.Prefix = prefix
+
+		it := txn.NewIterator(options)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			count++
+		}
+		return ctx.Err()
+	})
+
+	return
+}
+
+// ApproximateSize implements `store.Counter`, the estimate is the size of the LSM tables
+// overlapping the range plus the value log share of those tables. Writes still in memory
+// are not accounted for.
+func (s *Store) ApproximateSize(ctx context.Context, start, exclusiveEnd []byte) (uint64, error) {
+	var inRange, total uint64
+	for _, table := range s.db.Tables(false) {
+		total += table.EstimatedSz
+		if tableOverlaps(y.ParseKey(table.Left), y.ParseKey(table.Right), start, exclusiveEnd) {
+			inRange += table.EstimatedSz
+		}
+	}
+
+	if total == 0 {
+		return 0, nil
+	}
+
+	_, vlog := s.db.Size()
+	return inRange + uint64(float64(vlog)*float64(inRange)/float64(total)), nil
+}
+
+var keyOnlyIteratorOptions = func() badger.IteratorOptions {
+	options := badger.DefaultIteratorOptions
+	options.PrefetchValues = false
+	return options
+}()
+
+func tableOverlaps(left, right, start, exclusiveEnd []byte) bool {
+	if bytes.Compare(right, start) < 0 {
+		return false
+	}
+
+	return len(exclusiveEnd) == 0 || bytes.Compare(left, exclusiveEnd) < 0
+}
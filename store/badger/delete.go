@@ -0,0 +1,51 @@
+package badger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/streamingfast/kvdb/store"
+)
+
+// DeleteRange implements `store.RangeDeleter`, Badger cannot drop an arbitrary range so keys
+// are read without their value and deleted in batches.
+func (s *Store) DeleteRange(ctx context.Context, start, exclusiveEnd []byte) error {
+	return s.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(keyOnlyIteratorOptions)
+		defer it.Close()
+
+		var keys [][]byte
+		for it.Seek(start); it.Valid() && bytes.Compare(it.Item().Key(), exclusiveEnd) == -1; it.Next() {
+			keys = append(keys, it.Item().KeyCopy(nil))
+			if len(keys) >= store.DeleteRangeMaxBatchSize {
+				if err := s.BatchDelete(ctx, keys); err != nil {
+					return fmt.Errorf("unable to delete batch: %w", err)
+				}
+				keys = nil
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err := s.BatchDelete(ctx, keys); err != nil {
+				return fmt.Errorf("unable to delete batch: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// DeletePrefix implements `store.RangeDeleter` with Badger `DropPrefix`, which blocks writes
+// while it runs, so it's best suited for large prefixes.
+func (s *Store) DeletePrefix(ctx context.Context, prefix []byte) error {
+	if len(prefix) == 0 {
+		return s.db.DropAll()
+	}
+
+	return s.db.DropPrefix(prefix)
+}
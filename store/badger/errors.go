@@ -0,0 +1,13 @@
+package badger
+
+import (
+	"errors"
+
+	"github.com/dgraph-io/badger/v2"
+)
+
+// IsRetryableError implements `store.RetryableErrorClassifier`, transaction
+// conflicts are retryable.
+func (s *Store) IsRetryableError(err error) bool {
+	return errors.Is(err, badger.ErrConflict)
+}
@@ -0,0 +1,26 @@
+package badger
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/dgraph-io/badger/v2/y"
+	"github.com/streamingfast/kvdb/store"
+)
+
+// SplitRange implements `store.Splitter`, splits are picked among the last key of the LSM
+// tables, writes still in memory are not accounted for.
+func (s *Store) SplitRange(ctx context.Context, start, exclusiveEnd []byte, approxChunks int) ([][]byte, error) {
+	// Table keys carry the version which must be removed to compare them with user keys,
+	// the order of versioned keys is not the order of user keys so we sort again
+	tableKeys := s.db.KeySplits(nil)
+	candidates := make([][]byte, len(tableKeys))
+	for i, key := range tableKeys {
+		candidates[i] = y.ParseKey([]byte(key))
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return bytes.Compare(candidates[i], candidates[j]) < 0 })
+
+	return store.BalancedSplits(candidates, start, exclusiveEnd, approxChunks), nil
+}
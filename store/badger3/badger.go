@@ -12,13 +12,18 @@ import (
 	"github.com/dgraph-io/badger/v3/options"
 	"github.com/streamingfast/kvdb/store"
 	"github.com/streamingfast/logging"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
 
+// defaultBatchSizeThreshold bounds the memory held by pending puts, Badger write batches
+// split themselves in transactions of acceptable size on flush.
+const defaultBatchSizeThreshold = 16 * 1024 * 1024
+
 type Store struct {
 	dsn        string
 	db         *badger.DB
-	writeBatch *badger.WriteBatch
+	writes     *store.WriteBuffer
 	compressor store.Compressor
 }
 
@@ -28,12 +33,22 @@ func (s *Store) String() string {
 
 func init() {
 	store.Register(&store.Registration{
-		Name:        "badger3",
-		Title:       "Badger V3",
-		FactoryFunc: NewStore,
+		Name:         "badger3",
+		Title:        "Badger V3",
+		FactoryFunc:  NewStore,
+		Capabilities: capabilities,
+		Options: append(store.OptionSchemas{
+			{Name: "compression", Type: store.OptionTypeString, Description: "Compression of the database tables, Snappy when unset", Values: store.CompressionValues},
+		}, store.WriteBufferOptionSchemas(store.WriteBufferOptions{SizeThreshold: defaultBatchSizeThreshold})...),
 	})
 }
 
+var capabilities = store.DriverCapabilities{EmptyValues: true}
+
+func (s *Store) Capabilities() store.DriverCapabilities {
+	return capabilities
+}
+
 func NewStore(dsnString string) (store.KVStore, error) {
 	dsn, err := newDSN(dsnString)
 	if err != nil {
@@ -70,62 +85,63 @@ func NewStore(dsnString string) (store.KVStore, error) {
 		return nil, err
 	}
 
+	writeOptions, err := store.NewWriteBufferOptions(dsn.params, store.WriteBufferOptions{SizeThreshold: defaultBatchSizeThreshold})
+	if err != nil {
+		return nil, fmt.Errorf("badger new: dsn: %w", err)
+	}
+
 	s := &Store{
 		dsn:        dsnString,
 		db:         db,
 		compressor: compressor,
 	}
+	s.writes = store.NewWriteBuffer(writeOptions, s.batchPut)
 	return s, nil
 }
 
 func (s *Store) Close() error {
-	return s.db.Close()
+	return multierr.Append(s.writes.Close(), s.db.Close())
 }
 
 func (s *Store) Put(ctx context.Context, key, value []byte) (err error) {
-	zlogger := logging.Logger(ctx, zlog)
-
 	if tracer.Enabled() {
-		zlogger.Debug("putting key in store", zap.Stringer("key", store.Key(key)))
+		logging.Logger(ctx, zlog).Debug("putting key in store", zap.Stringer("key", store.Key(key)))
 	}
 
-	if s.writeBatch == nil {
-		s.writeBatch = s.db.NewWriteBatch()
-	}
+	return s.writes.Put(ctx, key, value)
+}
 
-	value = s.compressor.Compress(value)
+func (s *Store) FlushPuts(ctx context.Context) error {
+	return s.writes.Flush(ctx)
+}
 
-	err = s.writeBatch.SetEntry(badger.NewEntry(key, value))
-	if err == badger.ErrTxnTooBig {
-		zlogger.Debug("txn too big pre-emptively pushing")
-		if err := s.writeBatch.Flush(); err != nil {
-			return err
+func (s *Store) batchPut(ctx context.Context, kvs []store.KV) error {
+	zlogger := logging.Logger(ctx, zlog)
+
+	writeBatch := s.db.NewWriteBatch()
+	for _, kv := range kvs {
+		entry := badger.NewEntry(kv.Key, s.compressor.Compress(kv.Value))
+
+		err := writeBatch.SetEntry(entry)
+		if err == badger.ErrTxnTooBig {
+			zlogger.Debug("txn too big pre-emptively pushing")
+			if err := writeBatch.Flush(); err != nil {
+				return err
+			}
+
+			writeBatch = s.db.NewWriteBatch()
+			err = writeBatch.SetEntry(entry)
+			if err != nil {
+				return fmt.Errorf("set entry (after flush): %w", err)
+			}
 		}
 
-		s.writeBatch = s.db.NewWriteBatch()
-		err := s.writeBatch.SetEntry(badger.NewEntry(key, value))
 		if err != nil {
-			return fmt.Errorf("set entry (after flush): %w", err)
+			return fmt.Errorf("set entry: %w", err)
 		}
 	}
 
-	if err != nil {
-		return fmt.Errorf("set entry: %w", err)
-	}
-
-	return nil
-}
-
-func (s *Store) FlushPuts(ctx context.Context) error {
-	if s.writeBatch == nil {
-		return nil
-	}
-	err := s.writeBatch.Flush()
-	if err != nil {
-		return err
-	}
-	s.writeBatch = s.db.NewWriteBatch()
-	return nil
+	return writeBatch.Flush()
 }
 
 func wrapNotFoundError(err error) error {
@@ -136,6 +152,10 @@ func wrapNotFoundError(err error) error {
 }
 
 func (s *Store) Get(ctx context.Context, key []byte) (value []byte, err error) {
+	if value, found := s.writes.Get(key); found {
+		return value, nil
+	}
+
 	err = s.db.View(func(txn *badger.Txn) error {
 		item, err := txn.Get(key)
 		if err != nil {
@@ -159,10 +179,62 @@ func (s *Store) Get(ctx context.Context, key []byte) (value []byte, err error) {
 	return
 }
 
+// Exists checks the key without reading its value, which Badger fetches only on demand.
+func (s *Store) Exists(ctx context.Context, key []byte) (exists bool, err error) {
+	if _, found := s.writes.Get(key); found {
+		return true, nil
+	}
+
+	err = s.db.View(func(txn *badger.Txn) error {
+		exists, err = keyExists(txn, key)
+		return err
+	})
+	return
+}
+
+func (s *Store) BatchExists(ctx context.Context, keys [][]byte) (exists []bool, err error) {
+	exists = make([]bool, len(keys))
+	err = s.db.View(func(txn *badger.Txn) error {
+		for i, key := range keys {
+			if _, found := s.writes.Get(key); found {
+				exists[i] = true
+				continue
+			}
+
+			if exists[i], err = keyExists(txn, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func keyExists(txn *badger.Txn, key []byte) (bool, error) {
+	_, err := txn.Get(key)
+	if err == badger.ErrKeyNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *Store) Delete(ctx context.Context, key []byte) (err error) {
+	s.writes.Discard(key)
+
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(key)
+	})
+}
+
 func (s *Store) BatchDelete(ctx context.Context, keys [][]byte) (err error) {
 	zlogger := logging.Logger(ctx, zlog)
 	zlogger.Debug("batch deletion", zap.Int("key_count", len(keys)))
 
+	s.writes.Discard(keys...)
+
 	deletionBatch := s.db.NewWriteBatch()
 	for _, key := range keys {
 		err = deletionBatch.Delete(key)
@@ -189,6 +261,10 @@ func (s *Store) BatchDelete(ctx context.Context, keys [][]byte) (err error) {
 }
 
 func (s *Store) BatchGet(ctx context.Context, keys [][]byte) *store.Iterator {
+	return s.writes.OverlayBatchGet(ctx, keys, s.batchGet)
+}
+
+func (s *Store) batchGet(ctx context.Context, keys [][]byte) *store.Iterator {
 	kr := store.NewIterator(ctx)
 
 	go func() {
@@ -229,19 +305,23 @@ func (s *Store) BatchGet(ctx context.Context, keys [][]byte) *store.Iterator {
 
 func (s *Store) Scan(ctx context.Context, start, exclusiveEnd []byte, limit int, options ...store.ReadOption) *store.Iterator {
 	zlogger := logging.Logger(ctx, zlog)
-	sit := store.NewIterator(ctx)
+	sit := store.NewScanIterator(ctx, start, exclusiveEnd, limit, options)
 	zlogger.Debug("scanning", zap.Stringer("start", store.Key(start)), zap.Stringer("exclusive_end", store.Key(exclusiveEnd)), zap.Stringer("limit", store.Limit(limit)))
 	go func() {
-		err := s.db.View(func(txn *badger.Txn) error {
-			badgerOptions := badgerIteratorOptions(store.Limit(limit), options)
+		filter, stripValues, err := readFilter(options)
+		if err != nil {
+			sit.PushError(err)
+			return
+		}
+
+		err = s.db.View(func(txn *badger.Txn) error {
+			badgerOptions := badgerIteratorOptions(store.Limit(limit), options, filter)
 			bit := txn.NewIterator(badgerOptions)
 			defer bit.Close()
 
 			var err error
 			count := uint64(0)
 			for bit.Seek(start); bit.Valid() && bytes.Compare(bit.Item().Key(), exclusiveEnd) == -1; bit.Next() {
-				count++
-
 				// We require value only when `PrefetchValues` is true, otherwise, we are performing a key-only iteration and as such,
 				// we should not fetch nor decompress actual value
 				var value []byte
@@ -257,7 +337,17 @@ func (s *Store) Scan(ctx context.Context, start, exclusiveEnd []byte, limit int,
 					}
 				}
 
-				if !sit.PushItem(store.KV{Key: bit.Item().KeyCopy(nil), Value: value}) {
+				key := bit.Item().KeyCopy(nil)
+				if !filter.Match(key, value) {
+					continue
+				}
+
+				if stripValues {
+					value = nil
+				}
+
+				count++
+				if !sit.PushItem(store.KV{Key: key, Value: value}) {
 					break
 				}
 
@@ -275,16 +365,22 @@ func (s *Store) Scan(ctx context.Context, start, exclusiveEnd []byte, limit int,
 		sit.PushFinished()
 	}()
 
-	return sit
+	return s.writes.OverlayScan(sit, start, exclusiveEnd, limit, options)
 }
 
 func (s *Store) Prefix(ctx context.Context, prefix []byte, limit int, options ...store.ReadOption) *store.Iterator {
 	zlogger := logging.Logger(ctx, zlog)
-	kr := store.NewIterator(ctx)
+	kr := store.NewPrefixIterator(ctx, prefix, limit, options)
 	zlogger.Debug("prefix scanning", zap.Stringer("prefix", store.Key(prefix)), zap.Stringer("limit", store.Limit(limit)))
 	go func() {
-		err := s.db.View(func(txn *badger.Txn) error {
-			badgerOptions := badgerIteratorOptions(store.Limit(limit), options)
+		filter, stripValues, err := readFilter(options)
+		if err != nil {
+			kr.PushError(err)
+			return
+		}
+
+		err = s.db.View(func(txn *badger.Txn) error {
+			badgerOptions := badgerIteratorOptions(store.Limit(limit), options, filter)
 			badgerOptions.Prefix = prefix
 
 			it := txn.NewIterator(badgerOptions)
@@ -293,8 +389,6 @@ func (s *Store) Prefix(ctx context.Context, prefix []byte, limit int, options ..
 			var err error
 			count := uint64(0)
 			for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-				count++
-
 				// We require value only when `PrefetchValues` is true, otherwise, we are performing a key-only iteration and as such,
 				// we should not fetch nor decompress actual value
 				var value []byte
@@ -310,7 +404,17 @@ func (s *Store) Prefix(ctx context.Context, prefix []byte, limit int, options ..
 					}
 				}
 
-				if !kr.PushItem(store.KV{Key: it.Item().KeyCopy(nil), Value: value}) {
+				key := it.Item().KeyCopy(nil)
+				if !filter.Match(key, value) {
+					continue
+				}
+
+				if stripValues {
+					value = nil
+				}
+
+				count++
+				if !kr.PushItem(store.KV{Key: key, Value: value}) {
 					break
 				}
 
@@ -328,17 +432,23 @@ func (s *Store) Prefix(ctx context.Context, prefix []byte, limit int, options ..
 		kr.PushFinished()
 	}()
 
-	return kr
+	return s.writes.OverlayPrefix(kr, prefix, limit, options)
 }
 
 func (s *Store) BatchPrefix(ctx context.Context, prefixes [][]byte, limit int, options ...store.ReadOption) *store.Iterator {
 	zlogger := logging.Logger(ctx, zlog)
-	kr := store.NewIterator(ctx)
+	kr := store.NewBatchPrefixIterator(ctx, prefixes, limit, options)
 	zlogger.Debug("batch prefix scanning", zap.Int("prefix_count", len(prefixes)), zap.Stringer("limit", store.Limit(limit)))
 
 	go func() {
-		err := s.db.View(func(txn *badger.Txn) error {
-			badgerOptions := badgerIteratorOptions(store.Limit(limit), options)
+		filter, stripValues, err := readFilter(options)
+		if err != nil {
+			kr.PushError(err)
+			return
+		}
+
+		err = s.db.View(func(txn *badger.Txn) error {
+			badgerOptions := badgerIteratorOptions(store.Limit(limit), options, filter)
 			it := txn.NewIterator(badgerOptions)
 			defer it.Close()
 
@@ -347,8 +457,6 @@ func (s *Store) BatchPrefix(ctx context.Context, prefixes [][]byte, limit int, o
 		terminateLoop:
 			for _, prefix := range prefixes {
 				for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-					count++
-
 					// We require value only when `PrefetchValues` is true, otherwise, we are performing a key-only iteration and as such,
 					// we should not fetch nor decompress actual value
 					var value []byte
@@ -364,7 +472,17 @@ func (s *Store) BatchPrefix(ctx context.Context, prefixes [][]byte, limit int, o
 						}
 					}
 
-					if !kr.PushItem(store.KV{Key: it.Item().KeyCopy(nil), Value: value}) {
+					key := it.Item().KeyCopy(nil)
+					if !filter.Match(key, value) {
+						continue
+					}
+
+					if stripValues {
+						value = nil
+					}
+
+					count++
+					if !kr.PushItem(store.KV{Key: key, Value: value}) {
 						break terminateLoop
 					}
 
@@ -385,10 +503,21 @@ func (s *Store) BatchPrefix(ctx context.Context, prefixes [][]byte, limit int, o
 		kr.PushFinished()
 	}()
 
-	return kr
+	return s.writes.OverlayBatchPrefix(kr, prefixes, limit, options)
 }
 
-func badgerIteratorOptions(limit store.Limit, options []store.ReadOption) badger.IteratorOptions {
+// readFilter returns the filter of the read options, `stripValues` tells if values, read
+// only to apply the filter of a key-only read, must be removed from the items.
+func readFilter(options []store.ReadOption) (filter *store.ReadFilter, stripValues bool, err error) {
+	readOptions := store.NewReadOptions(options...)
+	if filter, err = store.NewReadFilter(readOptions); err != nil {
+		return nil, false, err
+	}
+
+	return filter, readOptions != nil && readOptions.KeyOnly && filter.NeedsValue(), nil
+}
+
+func badgerIteratorOptions(limit store.Limit, options []store.ReadOption, filter *store.ReadFilter) badger.IteratorOptions {
 	if limit.Unbounded() && len(options) == 0 {
 		return badger.DefaultIteratorOptions
 	}
@@ -399,9 +528,13 @@ func badgerIteratorOptions(limit store.Limit, options []store.ReadOption) badger
 	}
 
 	opts := badger.DefaultIteratorOptions
-	if readOptions.KeyOnly {
+	if readOptions.PrefetchSize > 0 {
+		opts.PrefetchSize = readOptions.PrefetchSize
+	}
+
+	if readOptions.KeyOnly && !filter.NeedsValue() {
 		opts.PrefetchValues = false
-	} else if limit.Bounded() && int(limit) < opts.PrefetchSize {
+	} else if filter == nil && limit.Bounded() && int(limit) < opts.PrefetchSize {
 		opts.PrefetchSize = int(limit)
 	}
 
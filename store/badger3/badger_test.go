@@ -21,6 +21,10 @@ func TestAll(t *testing.T) {
 	storetest.TestAll(t, "Badger", NewTestBadgerFactory(t, "badger-test.db"))
 }
 
+func TestAll_ReadYourWrites(t *testing.T) {
+	storetest.TestAll(t, "Badger", NewTestBadgerFactory(t, "badger-test.db?read_your_writes=true"))
+}
+
 func NewTestBadgerFactory(t *testing.T, testDBFilename string) storetest.DriverFactory {
 	return func(opts ...store.Option) (store.KVStore, *storetest.DriverCapabilities, storetest.DriverCleanupFunc) {
 		dir, err := ioutil.TempDir("", "kvdb-badger")
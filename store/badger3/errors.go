@@ -0,0 +1,13 @@
+package badger3
+
+import (
+	"errors"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+// IsRetryableError implements `store.RetryableErrorClassifier`, transaction
+// conflicts are retryable.
+func (s *Store) IsRetryableError(err error) bool {
+	return errors.Is(err, badger.ErrConflict)
+}
@@ -3,3 +3,7 @@ package badger3
 func (s *Store) EnableEmpty() {
 	zlog.Info("discarding possible empty value on store implementation, not required for this store")
 }
+
+func (s *Store) SetFlushErrorHandler(onError func(err error)) {
+	s.writes.SetErrorHandler(onError)
+}
@@ -0,0 +1,27 @@
+package badger3
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/dgraph-io/badger/v3/y"
+	"github.com/streamingfast/kvdb/store"
+)
+
+// SplitRange implements `store.Splitter`, splits are picked among the last key of the LSM
+// tables, writes still in memory are not accounted for.
+func (s *Store) SplitRange(ctx context.Context, start, exclusiveEnd []byte, approxChunks int) ([][]byte, error) {
+	// Badger v3 dropped `DB.KeySplits`, so we use the tables directly. Table keys carry the
+	// version which must be removed to compare them with user keys, the order of versioned
+	// keys is not the order of user keys so we sort again
+	tables := s.db.Tables()
+	candidates := make([][]byte, len(tables))
+	for i, table := range tables {
+		candidates[i] = y.ParseKey(table.Right)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return bytes.Compare(candidates[i], candidates[j]) < 0 })
+
+	return store.BalancedSplits(candidates, start, exclusiveEnd, approxChunks), nil
+}
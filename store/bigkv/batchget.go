@@ -0,0 +1,140 @@
+package bigkv
+
+import (
+	"context"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/streamingfast/kvdb/store"
+	"github.com/streamingfast/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	// batchGetRowsPerRead is the maximum number of rows read by a single `ReadRows` call of `BatchGet`
+	batchGetRowsPerRead = 500
+	// batchGetConcurrentReads is the number of `ReadRows` calls of a `BatchGet` running concurrently
+	batchGetConcurrentReads = 4
+)
+
+// BatchGet reads the keys in chunks of `batchGetRowsPerRead` rows, running a few `ReadRows`
+// calls concurrently. Bigtable returns the rows in key order, items are emitted in the order
+// of `keys` and the read ends with `store.ErrNotFound` at the first missing key.
+func (s *Store) BatchGet(ctx context.Context, keys [][]byte) *store.Iterator {
+	if tracer.Enabled() {
+		logging.Logger(ctx, zlog).Debug("batch get", zap.Int("key_count", len(keys)))
+	}
+
+	kr := store.NewIterator(ctx)
+	go func() {
+		if err := s.batchGet(ctx, keys, kr.PushItem); err != nil {
+			kr.PushError(err)
+			return
+		}
+
+		kr.PushFinished()
+	}()
+
+	return kr
+}
+
+// batchGetRead is the outcome of the `ReadRows` call of a chunk of keys, values are keyed by row key.
+type batchGetRead struct {
+	values map[string][]byte
+	err    error
+}
+
+func (s *Store) batchGet(ctx context.Context, keys [][]byte, onKV func(kv store.KV) bool) error {
+	// Stops the reads still running when the consumer stops early or on error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chunk := func(i int) [][]byte {
+		end := (i + 1) * batchGetRowsPerRead
+		if end > len(keys) {
+			end = len(keys)
+		}
+		return keys[i*batchGetRowsPerRead : end]
+	}
+	chunks := (len(keys) + batchGetRowsPerRead - 1) / batchGetRowsPerRead
+
+	slots := make(chan struct{}, batchGetConcurrentReads)
+	pending := make(chan chan batchGetRead, batchGetConcurrentReads)
+
+	go func() {
+		defer close(pending)
+
+		for i := 0; i < chunks; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case slots <- struct{}{}:
+			}
+
+			read := make(chan batchGetRead, 1)
+			go func(keys [][]byte) {
+				values, err := s.readValues(ctx, keys)
+				read <- batchGetRead{values: values, err: err}
+			}(chunk(i))
+
+			pending <- read
+		}
+	}()
+
+	i := 0
+	for read := range pending {
+		result := <-read
+		if result.err != nil {
+			return result.err
+		}
+
+		for _, key := range chunk(i) {
+			value, found := s.writes.Get(key)
+			if !found {
+				if value, found = result.values[string(s.withPrefix(key))]; !found {
+					return store.ErrNotFound
+				}
+			}
+
+			if !onKV(store.KV{Key: key, Value: value}) {
+				return nil
+			}
+		}
+
+		<-slots
+		i++
+	}
+
+	return ctx.Err()
+}
+
+// readValues reads the values of the keys with a single `ReadRows` call.
+func (s *Store) readValues(ctx context.Context, keys [][]byte) (map[string][]byte, error) {
+	rowKeys := make(bigtable.RowList, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		rowKey := string(s.withPrefix(key))
+		if !seen[rowKey] {
+			seen[rowKey] = true
+			rowKeys = append(rowKeys, rowKey)
+		}
+	}
+
+	out := make(map[string][]byte, len(rowKeys))
+
+	var decodeErr error
+	err := s.table.ReadRows(ctx, rowKeys, func(row bigtable.Row) bool {
+		value, err := s.rowValue(row)
+		if err != nil {
+			decodeErr = err
+			return false
+		}
+
+		out[row.Key()] = value
+		return true
+	}, s.bigtableReadOptions(store.Unlimited, nil)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return out, decodeErr
+}
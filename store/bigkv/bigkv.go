@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/bigtable"
@@ -15,6 +17,7 @@ import (
 	"github.com/streamingfast/logging"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -24,31 +27,91 @@ type Store struct {
 	client *bigtable.Client
 	table  *bigtable.Table
 
-	keyPrefix []byte
-	tableName string
+	project    string
+	instance   string
+	connection connectionOptions
+	keyPrefix  []byte
+	tableName  string
+
+	// dataConn is the connection used for the low-level calls not exposed by client, dialed on first use
+	dataConnLock sync.Mutex
+	dataConn     *grpc.ClientConn
+
+	adminLock sync.Mutex
+	admin     *bigtable.AdminClient
 
 	columnName string
+	// valueFilter keeps the cells holding the value of a key, other cells of the row being
+	// columns written with `PutColumns`
+	valueFilter bigtable.Filter
 
-	maxBytesBeforeFlush   uint64
-	maxRowsBeforeFlush    uint64
-	maxSecondsBeforeFlush uint64
+	compressor store.Compressor
+	compressed bool
+	chunkSize  int
 
-	batchPut *store.BatchOp
+	writes *store.WriteBuffer
 }
 
 func (s *Store) String() string {
 	return fmt.Sprintf("bigtable kv store with dsn: %q", s.dsn)
 }
 
+// registration is kept to resolve the legacy option names of DSNs given to `NewStore`
+// directly, without going through `store.New`.
+var registration *store.Registration
+
 func init() {
-	store.Register(&store.Registration{
-		Name:        "bigkv",
-		FactoryFunc: NewStore,
-	})
+	writeOptions := store.WriteBufferOptionSchemas(store.WriteBufferOptions{SizeThreshold: defaultBatchSizeThreshold, TimeThreshold: defaultBatchTimeThreshold})
+	writeOptions.Find(store.BatchSizeThresholdOption).Aliases = []string{"maxBytesBeforeFlush"}
+	writeOptions.Find(store.BatchOpsThresholdOption).Aliases = []string{"maxRowsBeforeFlush"}
+
+	registration = &store.Registration{
+		Name:         "bigkv",
+		Aliases:      []string{"bigtable"},
+		Title:        "Bigtable",
+		FactoryFunc:  NewStore,
+		Capabilities: capabilities,
+		Options: append(store.OptionSchemas{
+			{Name: "key_prefix", Type: store.OptionTypeHex, Description: "Prefix added to all keys, hex encoded", Aliases: []string{"keyPrefix"}},
+			{Name: "column_name", Type: store.OptionTypeString, Default: defaultColumnName, Description: "Column family holding the values", Aliases: []string{"colName"}},
+			{Name: "gc_policy", Type: store.OptionTypeString, Default: "max_versions=1", Description: "GC policy of the column_name family applied with create_table, `never`, `max_versions=<count>` or `max_age=<duration>` rules joined with `|`"},
+			{Name: "column_families", Type: store.OptionTypeString, Description: "Extra column families created with the table, comma separated `family[:gc_policy]` entries (`never`, `max_versions=<count>` or `max_age=<duration>` rules, joined with `|`)"},
+			{Name: "create_table", Type: store.OptionTypeBool, Default: "false", Description: "Create the table and its column families if they do not exist", Aliases: []string{"createTable"}},
+			{Name: "compression", Type: store.OptionTypeString, Description: "Compression of values above the size threshold, none when unset", Values: store.CompressionValues},
+			{Name: "compression_size_threshold", Type: store.OptionTypeInt, Default: strconv.Itoa(defaultCompressionSizeThreshold), Description: "Size in bytes above which values are compressed"},
+			{Name: "chunk_size", Type: store.OptionTypeInt, Default: strconv.Itoa(defaultChunkSize), Description: "Size in bytes above which stored values are split in cells of at most this size, 0 disables chunking"},
+			{Name: "maxSecondsBeforeFlush", Type: store.OptionTypeInt, Description: "Deprecated, use batch_time_threshold"},
+		}, append(connectionOptionSchemas, writeOptions...)...),
+	}
+	store.Register(registration)
 }
 
-// NewStore supports bigkt://project.instance/tableName?createTable=true
+var capabilities = store.DriverCapabilities{EmptyValues: true}
+
+func (s *Store) Capabilities() store.DriverCapabilities {
+	return capabilities
+}
+
+const (
+	// valueColumn is the column qualifier of the cell holding the value of a key
+	valueColumn = "v"
+
+	// maxApplyBulkBytes is the size above which puts are split in many Bigtable requests
+	maxApplyBulkBytes = 64 * 1024 * 1024
+
+	defaultColumnName         = "kv"
+	defaultBatchSizeThreshold = 70000000
+	defaultBatchTimeThreshold = 10 * time.Second
+)
+
+// NewStore supports bigkv://project.instance/tableName?create_table=true, see the registered
+// options for the others. The legacy camelCase option names are still accepted.
 func NewStore(dsnString string) (store.KVStore, error) {
+	dsnString, _, err := registration.ResolveDSN(dsnString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dsn: %w", err)
+	}
+
 	dsn, err := url.Parse(dsnString)
 	if err != nil {
 		return nil, err
@@ -64,87 +127,107 @@ func NewStore(dsnString string) (store.KVStore, error) {
 	project := projInstance[0]
 	instance := projInstance[1]
 
-	optionalTestEnv(project, instance)
-
-	client, err := bigtable.NewClient(ctx, project, instance)
+	dsnQuery := store.DSNQuery(dsn.Query())
+	connection, err := newConnectionOptions(dsnQuery)
 	if err != nil {
 		return nil, err
 	}
 
-	// FIXME: Sadly, Bigkv and TiKV uses different parameters name for configuring the batch manager.
-	//        I tend to see name here better than the ones in TiKV even though I think that starting with
-	//        `batch-` would be preferable. We also have a naming convention issue, we use `camelCase` here
-	//        but `snake-case` in TiKV. We should decide on our standard convention for parameter naming.
-	maxBytesBeforeFlush := uint64(70000000)
-	if qMaxBytes := dsn.Query().Get("maxBytesBeforeFlush"); qMaxBytes != "" {
-		ms, err := strconv.ParseUint(qMaxBytes, 10, 32)
-		if err != nil {
-			return nil, fmt.Errorf("dsn: invalid parameter for maxBytesBeforeFlush, %s", err)
-		}
-		maxBytesBeforeFlush = ms
+	if connection.emulatorHost == "" {
+		optionalTestEnv(project, instance)
 	}
 
-	maxSecondsBeforeFlush := uint64(10)
-	if qMaxSeconds := dsn.Query().Get("maxSecondsBeforeFlush"); qMaxSeconds != "" {
-		ms, err := strconv.ParseUint(qMaxSeconds, 10, 32)
-		if err != nil {
-			return nil, fmt.Errorf("dsn: invalid parameter for maxSecondsBeforeFlush, %s", err)
-		}
-		maxSecondsBeforeFlush = ms
+	client, err := connection.newClient(ctx, project, instance)
+	if err != nil {
+		return nil, err
 	}
 
-	maxRowsBeforeFlush := uint64(0)
-	if qMaxRows := dsn.Query().Get("maxRowsBeforeFlush"); qMaxRows != "" {
-		mb, err := strconv.ParseUint(qMaxRows, 10, 32)
-		if err != nil {
-			return nil, fmt.Errorf("dsn: invalid parameter for maxRowsBeforeFlush, %s", err)
-		}
-		maxRowsBeforeFlush = mb
+	writeDefaults, err := legacyWriteBufferDefaults(dsn.Query(), store.WriteBufferOptions{
+		SizeThreshold: defaultBatchSizeThreshold,
+		TimeThreshold: defaultBatchTimeThreshold,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	writeOptions, err := store.NewWriteBufferOptions(dsn.Query(), writeDefaults)
+	if err != nil {
+		return nil, fmt.Errorf("dsn: %w", err)
 	}
 
 	s := &Store{
-		dsn:                   dsnString,
-		client:                client,
-		batchPut:              store.NewBatchOp(int(maxBytesBeforeFlush), int(maxRowsBeforeFlush), time.Duration(maxSecondsBeforeFlush)*time.Second),
-		maxBytesBeforeFlush:   maxBytesBeforeFlush,
-		maxRowsBeforeFlush:    maxRowsBeforeFlush,
-		maxSecondsBeforeFlush: maxSecondsBeforeFlush,
+		dsn:        dsnString,
+		client:     client,
+		project:    project,
+		instance:   instance,
+		connection: connection,
 	}
+	s.writes = store.NewWriteBuffer(writeOptions, s.batchPut)
 
-	if keyPrefix := dsn.Query().Get("keyPrefix"); keyPrefix != "" {
+	if keyPrefix, _ := dsnQuery.StringOption("key_prefix", ""); keyPrefix != "" {
 		keyPrefixBytes, err := hex.DecodeString(keyPrefix)
 		if err != nil {
-			return nil, fmt.Errorf("decoding keyPrefix as hex: %w", err)
+			return nil, fmt.Errorf("decoding key_prefix as hex: %w", err)
 		}
 		s.keyPrefix = keyPrefixBytes
 	}
 
-	s.columnName = "kv"
-	if colName := dsn.Query().Get("colName"); colName != "" {
-		s.columnName = colName
+	s.columnName, _ = dsnQuery.StringOption("column_name", defaultColumnName)
+	s.valueFilter = bigtable.ChainFilters(bigtable.FamilyFilter(regexp.QuoteMeta(s.columnName)), bigtable.ColumnFilter(valueColumnsPattern))
+
+	compression, _ := dsnQuery.StringOption("compression", "")
+	compressionThreshold, rawValue, err := dsnQuery.IntOption("compression_size_threshold", defaultCompressionSizeThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("compression size threshold option %q is not a valid number: %w", rawValue, err)
 	}
 
-	createTable := dsn.Query().Get("createTable") == "true"
+	if s.compressor, err = store.NewCompressor(compression, compressionThreshold); err != nil {
+		return nil, fmt.Errorf("new compressor: %w", err)
+	}
+	_, uncompressed := s.compressor.(*store.NoOpCompressor)
+	s.compressed = !uncompressed
+
+	if s.chunkSize, rawValue, err = dsnQuery.IntOption("chunk_size", defaultChunkSize); err != nil {
+		return nil, fmt.Errorf("chunk size option %q is not a valid number: %w", rawValue, err)
+	}
+
+	rawFamilies, _ := dsnQuery.StringOption("column_families", "")
+	families, err := parseColumnFamilies(rawFamilies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid column_families option: %w", err)
+	}
+
+	if hasColumnFamily(families, s.columnName) {
+		return nil, fmt.Errorf("invalid column_families option: %q is the column_name family, its policy is set with gc_policy", s.columnName)
+	}
+
+	rawGCPolicy, _ := dsnQuery.StringOption("gc_policy", "")
+	valueFamily := columnFamily{name: s.columnName, gcPolicy: defaultGCPolicy}
+	if rawGCPolicy != "" {
+		if valueFamily.gcPolicy, err = parseGCPolicy(rawGCPolicy); err != nil {
+			return nil, fmt.Errorf("invalid gc_policy option: %w", err)
+		}
+	}
+	families = append([]columnFamily{valueFamily}, families...)
+
+	createTable, rawValue, err := dsnQuery.BoolOption("create_table", false)
+	if err != nil {
+		return nil, fmt.Errorf("create table option %q is not a valid boolean: %w", rawValue, err)
+	}
 
 	tableName := strings.Trim(dsn.Path, "/")
+	s.tableName = tableName
 	s.table = client.Open(tableName)
 
 	if createTable {
-		adminClient, err := bigtable.NewAdminClient(ctx, project, instance)
+		adminClient, err := connection.newAdminClient(ctx, project, instance)
 		if err != nil {
 			return nil, fmt.Errorf("failed setting up admin client: %w", err)
 		}
+		defer adminClient.Close()
 
-		if err := adminClient.CreateTable(ctx, tableName); err != nil && !isAlreadyExistsError(err) {
-			return nil, fmt.Errorf("failed creating table %q: %w", tableName, err)
-		}
-
-		if err := adminClient.CreateColumnFamily(ctx, tableName, s.columnName); err != nil && !isAlreadyExistsError(err) {
-			return nil, fmt.Errorf("failed creating %q family for table %q: %w", s.columnName, tableName, err)
-		}
-
-		if err := adminClient.SetGCPolicy(ctx, tableName, s.columnName, bigtable.MaxVersionsPolicy(1)); err != nil {
-			return nil, fmt.Errorf("failed applying gc policy to %q family for table %q: %w", s.columnName, tableName, err)
+		if err := ensureTable(ctx, adminClient, tableName, families); err != nil {
+			return nil, err
 		}
 	}
 
@@ -160,54 +243,97 @@ func isAlreadyExistsError(err error) bool {
 	return st.Code() == codes.AlreadyExists
 }
 
-func (s *Store) Close() error {
-	return s.client.Close()
+// legacyWriteBufferDefaults reads the deprecated `maxSecondsBeforeFlush` parameter, overridden
+// by `batch_time_threshold`. The deprecated `maxBytesBeforeFlush` and `maxRowsBeforeFlush` are
+// declared as aliases of `batch_size_threshold` and `batch_ops_threshold` instead.
+func legacyWriteBufferDefaults(query url.Values, defaults store.WriteBufferOptions) (store.WriteBufferOptions, error) {
+	rawValue := query.Get("maxSecondsBeforeFlush")
+	if rawValue == "" {
+		return defaults, nil
+	}
+
+	seconds, err := strconv.ParseUint(rawValue, 10, 32)
+	if err != nil {
+		return defaults, fmt.Errorf("dsn: invalid parameter for maxSecondsBeforeFlush, %s", err)
+	}
+
+	zlog.Warn("dsn parameter maxSecondsBeforeFlush is deprecated, use batch_time_threshold instead")
+	defaults.TimeThreshold = time.Duration(seconds) * time.Second
+	return defaults, nil
 }
 
-func (s *Store) Put(ctx context.Context, key, value []byte) (err error) {
-	formattedKey := s.withPrefix(key)
-	if s.batchPut.WouldFlushNext(formattedKey, value) {
-		err := s.FlushPuts(ctx)
-		if err != nil {
-			return err
-		}
+func (s *Store) Close() error {
+	s.dataConnLock.Lock()
+	defer s.dataConnLock.Unlock()
+	s.adminLock.Lock()
+	defer s.adminLock.Unlock()
+
+	err := s.writes.Close()
+	if s.dataConn != nil {
+		err = multierr.Append(err, s.dataConn.Close())
+	}
+	if s.admin != nil {
+		err = multierr.Append(err, s.admin.Close())
 	}
 
-	s.batchPut.Op(formattedKey, value)
-	return nil
+	return multierr.Append(err, s.client.Close())
+}
+
+func (s *Store) Put(ctx context.Context, key, value []byte) (err error) {
+	return s.writes.Put(ctx, key, value)
 }
 
 func (s *Store) FlushPuts(ctx context.Context) error {
 	if tracer.Enabled() {
-		logging.Logger(ctx, zlog).Debug("flushing puts")
+		logging.Logger(ctx, zlog).Debug("flushing puts", zap.Object("write_buffer", s.writes))
 	}
 
-	kvs := s.batchPut.GetBatch()
-	if len(kvs) == 0 {
-		return nil
+	return s.writes.Flush(ctx)
+}
+
+func (s *Store) batchPut(ctx context.Context, kvs []store.KV) error {
+	var keys []string
+	var mutations []*bigtable.Mutation
+	pendingSize := 0
+	for _, kv := range kvs {
+		mut, size := s.valueMutation(kv.Value)
+
+		// Large values are written in their own request, keeping requests under Bigtable limit
+		if len(keys) > 0 && pendingSize+size > maxApplyBulkBytes {
+			if err := s.applyBulk(ctx, keys, mutations); err != nil {
+				return err
+			}
+			keys, mutations, pendingSize = nil, nil, 0
+		}
+
+		keys = append(keys, string(s.withPrefix(kv.Key)))
+		mutations = append(mutations, mut)
+		pendingSize += len(kv.Key) + size
 	}
 
-	keys := make([]string, len(kvs))
-	values := make([]*bigtable.Mutation, len(kvs))
-	for idx, kv := range kvs {
-		keys[idx] = string(kv.Key)
-		mut := bigtable.NewMutation()
-		mut.Set(s.columnName, "v", bigtable.Now(), kv.Value)
-		values[idx] = mut
+	if len(keys) == 0 {
+		return nil
 	}
-	errs, err := s.table.ApplyBulk(ctx, keys, values)
+	return s.applyBulk(ctx, keys, mutations)
+}
+
+func (s *Store) applyBulk(ctx context.Context, keys []string, mutations []*bigtable.Mutation) error {
+	errs, err := s.table.ApplyBulk(ctx, keys, mutations)
 	if err != nil {
 		return err
 	}
 	if len(errs) != 0 {
 		return fmt.Errorf("apply bulk error: %w", multierr.Combine(errs...))
 	}
-	s.batchPut.Reset()
 	return nil
 }
 
 func (s *Store) Get(ctx context.Context, key []byte) (value []byte, err error) {
-	btOptions := bigtableReadOptions(store.Limit(store.Unlimited), nil)
+	if value, found := s.writes.Get(key); found {
+		return value, nil
+	}
+
+	btOptions := s.bigtableReadOptions(store.Unlimited, nil)
 	row, err := s.table.ReadRow(ctx, string(s.withPrefix(key)), btOptions...)
 	if err != nil {
 		return nil, err
@@ -216,35 +342,65 @@ func (s *Store) Get(ctx context.Context, key []byte) (value []byte, err error) {
 		return nil, store.ErrNotFound
 	}
 
-	return row[s.columnName][0].Value, nil
+	return s.rowValue(row)
 }
 
-func (s *Store) BatchGet(ctx context.Context, keys [][]byte) *store.Iterator {
-	if tracer.Enabled() {
-		logging.Logger(ctx, zlog).Debug("batch get", zap.Int("key_count", len(keys)))
+// Exists reads the row keeping a single cell stripped of its value, so the value is never transferred.
+func (s *Store) Exists(ctx context.Context, key []byte) (bool, error) {
+	if _, found := s.writes.Get(key); found {
+		return true, nil
 	}
 
-	btKeys := make([]string, len(keys))
+	row, err := s.table.ReadRow(ctx, string(s.withPrefix(key)), bigtable.RowFilter(s.valuePresenceFilter()))
+	if err != nil {
+		return false, err
+	}
+
+	return len(row) > 0, nil
+}
+
+func (s *Store) BatchExists(ctx context.Context, keys [][]byte) ([]bool, error) {
+	out := make([]bool, len(keys))
+	if len(keys) == 0 {
+		return out, nil
+	}
+
+	indexes := make(map[string][]int, len(keys))
+	rowKeys := make(bigtable.RowList, 0, len(keys))
 	for i, key := range keys {
-		btKeys[i] = string(key)
+		rowKey := string(s.withPrefix(key))
+		if _, found := indexes[rowKey]; !found {
+			rowKeys = append(rowKeys, rowKey)
+		}
+		indexes[rowKey] = append(indexes[rowKey], i)
 	}
 
-	btOptions := bigtableReadOptions(store.Limit(store.Unlimited), nil)
-	kr := store.NewIterator(ctx)
-	go func() {
-		err := s.table.ReadRows(ctx, bigtable.RowList(btKeys), func(row bigtable.Row) bool {
-			return kr.PushItem(store.KV{Key: s.withoutPrefix([]byte(row.Key())), Value: row[s.columnName][0].Value})
-		}, btOptions...)
+	err := s.table.ReadRows(ctx, rowKeys, func(row bigtable.Row) bool {
+		for _, i := range indexes[row.Key()] {
+			out[i] = true
+		}
+		return true
+	}, bigtable.RowFilter(s.valuePresenceFilter()))
+	if err != nil {
+		return nil, err
+	}
 
-		if err != nil {
-			kr.PushError(err)
-			return
+	for i, key := range keys {
+		if _, found := s.writes.Get(key); found {
+			out[i] = true
 		}
+	}
 
-		kr.PushFinished()
-	}()
+	return out, nil
+}
 
-	return kr
+func (s *Store) Delete(ctx context.Context, key []byte) error {
+	s.writes.Discard(key)
+
+	mut := bigtable.NewMutation()
+	mut.DeleteRow()
+
+	return s.table.Apply(ctx, string(s.withPrefix(key)), mut)
 }
 
 func (s *Store) BatchDelete(ctx context.Context, deletionKeys [][]byte) (err error) {
@@ -256,35 +412,30 @@ func (s *Store) BatchDelete(ctx context.Context, deletionKeys [][]byte) (err err
 		return nil
 	}
 
-	batch := store.NewBatchOp(int(s.maxBytesBeforeFlush), int(s.maxRowsBeforeFlush), time.Duration(s.maxSecondsBeforeFlush)*time.Second)
-	keys := make([]string, len(deletionKeys))
-	values := make([]*bigtable.Mutation, len(deletionKeys))
-	for idx, deletionKey := range deletionKeys {
+	s.writes.Discard(deletionKeys...)
+
+	writeOptions := s.writes.Options()
+	batch := store.NewBatchOp(writeOptions.SizeThreshold, writeOptions.OpsThreshold, writeOptions.TimeThreshold)
+	var keys []string
+	var mutations []*bigtable.Mutation
+	for _, deletionKey := range deletionKeys {
 		if batch.ShouldFlush() {
-			errs, err := s.table.ApplyBulk(ctx, keys, values)
-			if err != nil {
+			if err := s.applyBulk(ctx, keys, mutations); err != nil {
 				return err
 			}
-			if len(errs) != 0 {
-				return fmt.Errorf("apply bulk error: %w", multierr.Combine(errs...))
-			}
-			keys = make([]string, len(deletionKeys))
-			values = make([]*bigtable.Mutation, len(deletionKeys))
+			batch.Reset()
+			keys, mutations = nil, nil
 		}
+
 		batch.Op(deletionKey, []byte{0x00})
-		keys[idx] = string(deletionKey)
+		keys = append(keys, string(s.withPrefix(deletionKey)))
 		mut := bigtable.NewMutation()
 		mut.DeleteRow()
-		values[idx] = mut
+		mutations = append(mutations, mut)
 	}
-	if len(batch.GetBatch()) > 0 {
-		errs, err := s.table.ApplyBulk(ctx, keys, values)
-		if err != nil {
-			return err
-		}
-		if len(errs) != 0 {
-			return fmt.Errorf("apply bulk error: %w", multierr.Combine(errs...))
-		}
+
+	if len(keys) > 0 {
+		return s.applyBulk(ctx, keys, mutations)
 	}
 	return nil
 }
@@ -297,20 +448,19 @@ func (s *Store) Scan(ctx context.Context, start, exclusiveEnd []byte, limit int,
 		logging.Logger(ctx, zlog).Debug("scanning", zap.Stringer("start", store.Key(startKey)), zap.Stringer("exclusive_end", store.Key(endKey)), zap.Stringer("limit", store.Limit(limit)))
 	}
 
-	sit := store.NewIterator(ctx)
+	sit := store.NewScanIterator(ctx, start, exclusiveEnd, limit, options)
 	if len(endKey) == 0 {
 		// Act like the other backends
 		sit.PushFinished()
 		return sit
 	}
 
-	btOptions := bigtableReadOptions(store.Limit(limit), options)
-	rowRange := bigtable.NewRange(string(startKey), string(endKey))
+	ranges := []rowRange{{start: string(startKey), end: string(endKey)}}
 
 	go func() {
-		err := s.table.ReadRows(ctx, rowRange, func(row bigtable.Row) bool {
-			return sit.PushItem(store.KV{s.withoutPrefix([]byte(row.Key())), row[s.columnName][0].Value})
-		}, btOptions...)
+		err := s.readRows(ctx, ranges, store.Limit(limit), options, func(kv store.KV) bool {
+			return sit.PushItem(kv)
+		})
 
 		if err != nil {
 			sit.PushError(err)
@@ -319,7 +469,7 @@ func (s *Store) Scan(ctx context.Context, start, exclusiveEnd []byte, limit int,
 		sit.PushFinished()
 	}()
 
-	return sit
+	return s.writes.OverlayScan(sit, start, exclusiveEnd, limit, options)
 }
 
 func (s *Store) Prefix(ctx context.Context, prefix []byte, limit int, options ...store.ReadOption) *store.Iterator {
@@ -327,14 +477,13 @@ func (s *Store) Prefix(ctx context.Context, prefix []byte, limit int, options ..
 		logging.Logger(ctx, zlog).Debug("prefix scanning", zap.Stringer("prefix", store.Key(prefix)), zap.Stringer("limit", store.Limit(limit)))
 	}
 
-	sit := store.NewIterator(ctx)
-	btOptions := bigtableReadOptions(store.Limit(limit), options)
-	prefix = s.withPrefix(prefix)
+	sit := store.NewPrefixIterator(ctx, prefix, limit, options)
+	ranges := []rowRange{prefixRowRange(s.withPrefix(prefix))}
 
 	go func() {
-		err := s.table.ReadRows(ctx, bigtable.PrefixRange(string(prefix)), func(row bigtable.Row) bool {
-			return sit.PushItem(store.KV{s.withoutPrefix([]byte(row.Key())), row[s.columnName][0].Value})
-		}, btOptions...)
+		err := s.readRows(ctx, ranges, store.Limit(limit), options, func(kv store.KV) bool {
+			return sit.PushItem(kv)
+		})
 
 		if err != nil {
 			sit.PushError(err)
@@ -344,7 +493,7 @@ func (s *Store) Prefix(ctx context.Context, prefix []byte, limit int, options ..
 		sit.PushFinished() // there was an error there!
 	}()
 
-	return sit
+	return s.writes.OverlayPrefix(sit, prefix, limit, options)
 }
 
 func (s *Store) BatchPrefix(ctx context.Context, prefixes [][]byte, limit int, options ...store.ReadOption) *store.Iterator {
@@ -352,17 +501,16 @@ func (s *Store) BatchPrefix(ctx context.Context, prefixes [][]byte, limit int, o
 		logging.Logger(ctx, zlog).Debug("batch prefix scanning", zap.Int("prefix_count", len(prefixes)), zap.Stringer("limit", store.Limit(limit)))
 	}
 
-	sit := store.NewIterator(ctx)
-	btOptions := bigtableReadOptions(store.Limit(limit), options)
-	rowRanges := make([]bigtable.RowRange, len(prefixes))
+	sit := store.NewBatchPrefixIterator(ctx, prefixes, limit, options)
+	ranges := make([]rowRange, len(prefixes))
 	for i, prefix := range prefixes {
-		rowRanges[i] = bigtable.PrefixRange(string(s.withPrefix(prefix)))
+		ranges[i] = prefixRowRange(s.withPrefix(prefix))
 	}
 
 	go func() {
-		err := s.table.ReadRows(ctx, bigtable.RowRangeList(rowRanges), func(row bigtable.Row) bool {
-			return sit.PushItem(store.KV{Key: s.withoutPrefix([]byte(row.Key())), Value: row[s.columnName][0].Value})
-		}, btOptions...)
+		err := s.readRows(ctx, ranges, store.Limit(limit), options, func(kv store.KV) bool {
+			return sit.PushItem(kv)
+		})
 
 		if err != nil {
 			sit.PushError(err)
@@ -372,7 +520,7 @@ func (s *Store) BatchPrefix(ctx context.Context, prefixes [][]byte, limit int, o
 		sit.PushFinished() // there was an error there!
 	}()
 
-	return sit
+	return s.writes.OverlayBatchPrefix(sit, prefixes, limit, options)
 }
 
 func (s *Store) withPrefix(key []byte) []byte {
@@ -395,31 +543,68 @@ func (s *Store) withoutPrefix(key []byte) []byte {
 var keyOnlyFilter = bigtable.StripValueFilter()
 var latestCellFilter = bigtable.LatestNFilter(1)
 
-func bigtableReadOptions(limit store.Limit, options []store.ReadOption) []bigtable.ReadOption {
-	readOptions := store.ReadOptions{}
-	for _, opt := range options {
-		opt.Apply(&readOptions)
+// presenceFilter keeps a single cell stripped of its value, enough to know a row exists
+var presenceFilter = bigtable.ChainFilters(bigtable.CellsPerRowLimitFilter(1), keyOnlyFilter)
+
+// valuePresenceFilter is the `presenceFilter` of rows holding a value, ignoring the rows
+// holding only columns written with `PutColumns`.
+func (s *Store) valuePresenceFilter() bigtable.Filter {
+	return bigtable.ChainFilters(s.valueFilter, presenceFilter)
+}
+
+func (s *Store) bigtableReadOptions(limit store.Limit, filters *readFilters) []bigtable.ReadOption {
+	if filters == nil {
+		filters = &readFilters{}
 	}
 
-	// We assume here that at most, we will get 2 filters, also, we assume that if key only is specified,
-	// it should go first, theory here is that stripping value first (if required) puts less performance hit
-	// on BigTable for subsequent filters, so order matters.
-	var filters = make([]bigtable.Filter, 0, 2)
-	if readOptions.KeyOnly {
-		filters = append(filters, keyOnlyFilter)
+	var btFilters = make([]bigtable.Filter, 0, 5)
+	btFilters = append(btFilters, s.valueFilter)
+	if filters.keyRegexPattern != "" {
+		btFilters = append(btFilters, bigtable.RowKeyFilter(filters.keyRegexPattern))
 	}
 
-	filters = append(filters, latestCellFilter)
+	if len(filters.valuePrefix) > 0 || filters.needsValue() {
+		// Value filters must see the latest cell only and its value must still be present, so order matters.
+		btFilters = append(btFilters, latestCellFilter)
+		if len(filters.valuePrefix) > 0 {
+			valueRange := prefixRowRange(filters.valuePrefix)
+			var end []byte
+			if valueRange.end != "" {
+				end = []byte(valueRange.end)
+			}
+
+			// Only the first chunk of a value is matched, the row keeping all its value cells
+			btFilters = append(btFilters, bigtable.ConditionFilter(
+				bigtable.ChainFilters(bigtable.ColumnFilter(valueColumn), bigtable.ValueRangeFilter(filters.valuePrefix, end)),
+				bigtable.PassAllFilter(),
+				nil,
+			))
+		}
+
+		if filters.keyOnly && !filters.needsValue() {
+			btFilters = append(btFilters, keyOnlyFilter)
+		}
+	} else {
+		// We assume that if key only is specified, it should go first, theory here is that stripping value first
+		// (if required) puts less performance hit on BigTable for subsequent filters, so order matters.
+		if filters.keyOnly {
+			btFilters = append(btFilters, keyOnlyFilter)
+		}
+
+		btFilters = append(btFilters, latestCellFilter)
+	}
 
 	var filterOption bigtable.ReadOption
-	if len(filters) == 1 {
-		filterOption = bigtable.RowFilter(filters[0])
+	if len(btFilters) == 1 {
+		filterOption = bigtable.RowFilter(btFilters[0])
 	} else {
-		filterOption = bigtable.RowFilter(bigtable.ChainFilters(filters...))
+		filterOption = bigtable.RowFilter(bigtable.ChainFilters(btFilters...))
 	}
 
 	opts := []bigtable.ReadOption{filterOption}
-	if store.Limit(limit).Bounded() {
+
+	// Rows filtered out on our side would count in Bigtable limit, the limit is enforced when receiving rows in this case
+	if store.Limit(limit).Bounded() && filters.client == nil {
 		opts = append(opts, bigtable.LimitRows(int64(limit)))
 	}
 
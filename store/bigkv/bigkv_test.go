@@ -1,6 +1,7 @@
 package bigkv
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"math/rand"
@@ -9,9 +10,11 @@ import (
 	"testing"
 	"time"
 
+	"cloud.google.com/go/bigtable/bttest"
 	"github.com/streamingfast/logging"
 	"github.com/streamingfast/kvdb/store"
 	"github.com/streamingfast/kvdb/store/storetest"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -28,6 +31,58 @@ func TestAll(t *testing.T) {
 	storetest.TestAll(t, "bigkv", newTestFactory(t))
 }
 
+// TestAllEmulated runs the tests against an in-memory Bigtable server, with options changing
+// how rows are stored.
+func TestAllEmulated(t *testing.T) {
+	server, err := bttest.NewServer("localhost:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	tableCount := 0
+	for _, dsnQuery := range []string{
+		"key_prefix=6b76",
+		"chunk_size=7",
+		"compression=zstd&compression_size_threshold=16&chunk_size=7",
+		"read_your_writes=true",
+	} {
+		storetest.TestAll(t, "bigkv?"+dsnQuery, func(opts ...store.Option) (store.KVStore, *storetest.DriverCapabilities, storetest.DriverCleanupFunc) {
+			tableCount++
+			dsn := fmt.Sprintf("bigkv://project.instance/table-%d?create_table=true&emulator_host=%s&%s", tableCount, server.Addr, dsnQuery)
+
+			kvStore, err := store.New(dsn, opts...)
+			require.NoError(t, err)
+			return kvStore, storetest.NewDriverCapabilities(), func() {
+				kvStore.Close()
+			}
+		})
+	}
+}
+
+func TestNewStore_LegacyOptionNames(t *testing.T) {
+	server, err := bttest.NewServer("localhost:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	kvStore, err := NewStore("bigkv://project.instance/legacy?createTable=true&keyPrefix=6b76&colName=data&maxRowsBeforeFlush=1&emulator_host=" + server.Addr)
+	require.NoError(t, err)
+	defer kvStore.Close()
+
+	s := kvStore.(*Store)
+	assert.Equal(t, []byte("kv"), s.keyPrefix)
+	assert.Equal(t, "data", s.columnName)
+
+	ctx := context.Background()
+	require.NoError(t, kvStore.Put(ctx, []byte("a"), []byte("1")))
+	require.NoError(t, kvStore.FlushPuts(ctx))
+
+	value, err := kvStore.Get(ctx, []byte("a"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+
+	_, err = NewStore("bigkv://project.instance/legacy?unknownOption=1&emulator_host=" + server.Addr)
+	assert.Error(t, err)
+}
+
 func newTestFactory(t *testing.T) storetest.DriverFactory {
 	return func(opts ...store.Option) (store.KVStore, *storetest.DriverCapabilities, storetest.DriverCleanupFunc) {
 		dsn := "bigkv://dev.dev/dev-{prefix}?createTable=true"
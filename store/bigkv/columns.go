@@ -0,0 +1,213 @@
+package bigkv
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/streamingfast/kvdb/store"
+	"github.com/streamingfast/logging"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// ColumnRow is a row made of named columns, each column is a `family:qualifier` pair (the
+// notation of `base/bigt` `BaseTable.SetKey`) mapped to the value of its latest cell.
+type ColumnRow struct {
+	Key     []byte
+	Columns map[string][]byte
+}
+
+// PutColumns writes the columns of the rows, alongside the values written with `Put` which
+// are stored in the `column_name` family. The column families must exist, see the
+// `column_families` dsn query parameter creating them with the table. The `column_name`
+// family is rejected, its cells hold values encoded by the store (compressed or chunked)
+// and writing them would corrupt the value of the key.
+//
+// Columns are not buffered, they are written when the call returns. Deleting a key (`Delete`,
+// `BatchDelete`, `DeleteRange` or `DeletePrefix`) deletes the whole row, its columns included.
+func (s *Store) PutColumns(ctx context.Context, rows ...ColumnRow) error {
+	if tracer.Enabled() {
+		logging.Logger(ctx, zlog).Debug("put columns", zap.Int("row_count", len(rows)))
+	}
+
+	sizeThreshold := s.writes.Options().SizeThreshold
+
+	var keys []string
+	var mutations []*bigtable.Mutation
+	pendingSize := 0
+	applyBatch := func() error {
+		errs, err := s.table.ApplyBulk(ctx, keys, mutations)
+		if err != nil {
+			return err
+		}
+		if len(errs) != 0 {
+			return fmt.Errorf("apply bulk error: %w", multierr.Combine(errs...))
+		}
+
+		keys, mutations, pendingSize = nil, nil, 0
+		return nil
+	}
+
+	for _, row := range rows {
+		if len(row.Columns) == 0 {
+			continue
+		}
+
+		mut := bigtable.NewMutation()
+		for column, value := range row.Columns {
+			family, qualifier, err := s.splitColumn(column)
+			if err != nil {
+				return err
+			}
+
+			mut.Set(family, qualifier, bigtable.Now(), value)
+			pendingSize += len(column) + len(value)
+		}
+
+		keys = append(keys, string(s.withPrefix(row.Key)))
+		mutations = append(mutations, mut)
+		pendingSize += len(row.Key)
+
+		if sizeThreshold > 0 && pendingSize >= sizeThreshold {
+			if err := applyBatch(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(keys) > 0 {
+		return applyBatch()
+	}
+	return nil
+}
+
+// GetColumns reads the columns of the row at `key`, an entry of `columns` is either a
+// `family:qualifier` column or a `family` selecting all its columns, all columns of the row
+// are read when none is given. It returns `store.ErrNotFound` when the row has none of them.
+//
+// The `column_name` family is never read, its value is the one `Get` returns, selecting it
+// is an error.
+func (s *Store) GetColumns(ctx context.Context, key []byte, columns ...string) (map[string][]byte, error) {
+	filter, err := s.columnsFilter(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := s.table.ReadRow(ctx, string(s.withPrefix(key)), bigtable.RowFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	out := s.rowColumns(row)
+	if len(out) == 0 {
+		return nil, store.ErrNotFound
+	}
+
+	return out, nil
+}
+
+// ScanColumns reads the selected columns (see `GetColumns`) of the rows from `start` to
+// `exclusiveEnd`, calling `onRow` in key order until it returns false. Rows having none
+// of the columns are skipped and not counted in the limit.
+func (s *Store) ScanColumns(ctx context.Context, start, exclusiveEnd []byte, limit int, columns []string, onRow func(row ColumnRow) bool) error {
+	if len(exclusiveEnd) == 0 {
+		// Act like `Scan`
+		return nil
+	}
+
+	rowRange := bigtable.NewRange(string(s.withPrefix(start)), string(s.withPrefix(exclusiveEnd)))
+	return s.readColumns(ctx, rowRange, store.Limit(limit), columns, onRow)
+}
+
+// PrefixColumns reads the selected columns (see `GetColumns`) of the rows whose key starts
+// with `prefix`, calling `onRow` in key order until it returns false. Rows having none of the
+// columns are skipped and not counted in the limit.
+func (s *Store) PrefixColumns(ctx context.Context, prefix []byte, limit int, columns []string, onRow func(row ColumnRow) bool) error {
+	return s.readColumns(ctx, prefixRowRange(s.withPrefix(prefix)).bigtableRange(), store.Limit(limit), columns, onRow)
+}
+
+func (s *Store) readColumns(ctx context.Context, rowSet bigtable.RowSet, limit store.Limit, columns []string, onRow func(row ColumnRow) bool) error {
+	filter, err := s.columnsFilter(columns)
+	if err != nil {
+		return err
+	}
+
+	// Without selected columns, rows holding only a value are filtered out client side and
+	// must not count in the limit.
+	var opts []bigtable.ReadOption
+	if limit.Bounded() && len(columns) > 0 {
+		opts = append(opts, bigtable.LimitRows(int64(limit)))
+	}
+
+	count := uint64(0)
+	return s.table.ReadRows(ctx, rowSet, func(row bigtable.Row) bool {
+		columns := s.rowColumns(row)
+		if len(columns) == 0 {
+			return true
+		}
+
+		count++
+		return onRow(ColumnRow{Key: s.withoutPrefix([]byte(row.Key())), Columns: columns}) && !limit.Reached(count)
+	}, append(opts, bigtable.RowFilter(filter))...)
+}
+
+// columnsFilter returns the filter keeping the latest cell of the selected columns, the
+// `column_name` family cannot be selected.
+func (s *Store) columnsFilter(columns []string) (bigtable.Filter, error) {
+	if len(columns) == 0 {
+		return latestCellFilter, nil
+	}
+
+	selections := make([]bigtable.Filter, len(columns))
+	for i, column := range columns {
+		family, qualifier, hasQualifier := strings.Cut(column, ":")
+		if family == "" {
+			return nil, fmt.Errorf("invalid column %q, expecting family or family:qualifier", column)
+		}
+		if family == s.columnName {
+			return nil, fmt.Errorf("invalid column %q, the %q family holds the values of keys, use Get to read them", column, family)
+		}
+
+		selections[i] = bigtable.FamilyFilter(regexp.QuoteMeta(family))
+		if hasQualifier {
+			selections[i] = bigtable.ChainFilters(selections[i], bigtable.ColumnFilter(regexp.QuoteMeta(qualifier)))
+		}
+	}
+
+	selection := selections[0]
+	if len(selections) > 1 {
+		selection = bigtable.InterleaveFilters(selections...)
+	}
+
+	return bigtable.ChainFilters(selection, latestCellFilter), nil
+}
+
+// rowColumns maps the cells of a row read with `columnsFilter` by column, Bigtable already
+// names them `family:qualifier`. The cells of the `column_name` family are left out.
+func (s *Store) rowColumns(row bigtable.Row) map[string][]byte {
+	out := map[string][]byte{}
+	for family, items := range row {
+		if family == s.columnName {
+			continue
+		}
+
+		for _, item := range items {
+			out[item.Column] = item.Value
+		}
+	}
+	return out
+}
+
+func (s *Store) splitColumn(column string) (family, qualifier string, err error) {
+	family, qualifier, found := strings.Cut(column, ":")
+	if !found || family == "" {
+		return "", "", fmt.Errorf("invalid column %q, expecting family:qualifier", column)
+	}
+	if family == s.columnName {
+		return "", "", fmt.Errorf("invalid column %q, the %q family holds the values of keys, use Put to write them", column, family)
+	}
+	return family, qualifier, nil
+}
@@ -0,0 +1,141 @@
+package bigkv
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/bigtable/bttest"
+	"github.com/streamingfast/kvdb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEmulatedStore returns a store backed by an in-memory Bigtable server.
+func newEmulatedStore(t *testing.T, dsnQuery string) *Store {
+	t.Helper()
+
+	server, err := bttest.NewServer("localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	kvStore, err := store.New("bigkv://project.instance/table?create_table=true&emulator_host=" + server.Addr + "&" + dsnQuery)
+	require.NoError(t, err)
+	t.Cleanup(func() { kvStore.(*Store).Close() })
+
+	return kvStore.(*Store)
+}
+
+func TestColumns(t *testing.T) {
+	ctx := context.Background()
+	s := newEmulatedStore(t, "key_prefix=aa&column_families=meta,blocks:max_age=72h")
+
+	require.NoError(t, s.Put(ctx, []byte("k1"), []byte("value1")))
+	require.NoError(t, s.FlushPuts(ctx))
+
+	require.NoError(t, s.PutColumns(ctx,
+		ColumnRow{Key: []byte("k1"), Columns: map[string][]byte{"meta:author": []byte("alice"), "blocks:header": []byte("h1")}},
+		ColumnRow{Key: []byte("k2"), Columns: map[string][]byte{"meta:author": []byte("bob"), "meta:size": []byte("2")}},
+		ColumnRow{Key: []byte("k3"), Columns: map[string][]byte{"blocks:header": []byte("h3")}},
+	))
+
+	columns, err := s.GetColumns(ctx, []byte("k1"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"meta:author": []byte("alice"), "blocks:header": []byte("h1")}, columns, "values are not columns")
+
+	columns, err = s.GetColumns(ctx, []byte("k2"), "meta")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"meta:author": []byte("bob"), "meta:size": []byte("2")}, columns)
+
+	columns, err = s.GetColumns(ctx, []byte("k1"), "meta:author", "blocks:header")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"meta:author": []byte("alice"), "blocks:header": []byte("h1")}, columns)
+
+	_, err = s.GetColumns(ctx, []byte("k3"), "meta")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	// Overwriting a column keeps only its latest value
+	require.NoError(t, s.PutColumns(ctx, ColumnRow{Key: []byte("k2"), Columns: map[string][]byte{"meta:size": []byte("3")}}))
+	columns, err = s.GetColumns(ctx, []byte("k2"), "meta:size")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"meta:size": []byte("3")}, columns)
+
+	var keys []string
+	var headers []string
+	require.NoError(t, s.PrefixColumns(ctx, []byte("k"), 0, []string{"blocks:header"}, func(row ColumnRow) bool {
+		keys = append(keys, string(row.Key))
+		headers = append(headers, string(row.Columns["blocks:header"]))
+		return true
+	}))
+	assert.Equal(t, []string{"k1", "k3"}, keys)
+	assert.Equal(t, []string{"h1", "h3"}, headers)
+
+	keys = nil
+	require.NoError(t, s.ScanColumns(ctx, []byte("k1"), []byte("k3"), 1, []string{"meta"}, func(row ColumnRow) bool {
+		keys = append(keys, string(row.Key))
+		return true
+	}))
+	assert.Equal(t, []string{"k1"}, keys)
+
+	// Rows holding only columns are not key/value entries
+	_, err = s.Get(ctx, []byte("k2"))
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	exists, err := s.BatchExists(ctx, [][]byte{[]byte("k1"), []byte("k2"), []byte("k3")})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, false}, exists)
+
+	kvs, err := store.Collect(s.Prefix(ctx, []byte("k"), store.Unlimited))
+	require.NoError(t, err)
+	assert.Equal(t, []store.KV{{Key: []byte("k1"), Value: []byte("value1")}}, kvs)
+
+	count, err := s.CountPrefix(ctx, []byte("k"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+
+	// Deleting a key deletes its columns
+	require.NoError(t, s.Delete(ctx, []byte("k1")))
+	_, err = s.GetColumns(ctx, []byte("k1"))
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	assert.Error(t, s.PutColumns(ctx, ColumnRow{Key: []byte("k4"), Columns: map[string][]byte{"meta": []byte("x")}}))
+	_, err = s.GetColumns(ctx, []byte("k2"), ":author")
+	assert.Error(t, err)
+}
+
+func TestColumns_ValueFamily(t *testing.T) {
+	ctx := context.Background()
+	s := newEmulatedStore(t, "column_families=meta&compression=zstd&compression_size_threshold=4&chunk_size=4")
+
+	require.NoError(t, s.Put(ctx, []byte("k1"), []byte("a value split in chunks")))
+	require.NoError(t, s.Put(ctx, []byte("k2"), []byte("value only")))
+	require.NoError(t, s.FlushPuts(ctx))
+	require.NoError(t, s.PutColumns(ctx, ColumnRow{Key: []byte("k1"), Columns: map[string][]byte{"meta:author": []byte("alice")}}))
+
+	for _, columns := range []map[string][]byte{{"kv:v": []byte("x")}, {"kv:vn": []byte("1")}, {"kv:v1": []byte("x")}} {
+		assert.Error(t, s.PutColumns(ctx, ColumnRow{Key: []byte("k1"), Columns: columns}), "writing the value family")
+	}
+
+	value, err := s.Get(ctx, []byte("k1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a value split in chunks"), value)
+
+	for _, column := range []string{"kv", "kv:v", "kv:vn"} {
+		_, err = s.GetColumns(ctx, []byte("k1"), column)
+		assert.Error(t, err, "reading the value family")
+		assert.Error(t, s.ScanColumns(ctx, []byte("k"), []byte("l"), 0, []string{column}, func(row ColumnRow) bool { return true }))
+	}
+
+	columns, err := s.GetColumns(ctx, []byte("k1"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"meta:author": []byte("alice")}, columns)
+
+	_, err = s.GetColumns(ctx, []byte("k2"))
+	assert.ErrorIs(t, err, store.ErrNotFound, "rows holding only a value have no columns")
+
+	var keys []string
+	require.NoError(t, s.PrefixColumns(ctx, []byte("k"), 1, nil, func(row ColumnRow) bool {
+		keys = append(keys, string(row.Key))
+		return true
+	}))
+	assert.Equal(t, []string{"k1"}, keys)
+}
@@ -0,0 +1,122 @@
+package bigkv
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/streamingfast/kvdb/store"
+	"google.golang.org/api/option"
+	gtransport "google.golang.org/api/transport/grpc"
+	"google.golang.org/grpc"
+)
+
+const (
+	bigtableEndpoint = "bigtable.googleapis.com:443"
+
+	defaultConnectionPoolSize = 4
+)
+
+var connectionOptionSchemas = store.OptionSchemas{
+	{Name: "app_profile", Type: store.OptionTypeString, Description: "App profile of the data operations, the instance default one when empty"},
+	{Name: "credentials_file", Type: store.OptionTypeString, Description: "Service account JSON key file, application default credentials are used when empty"},
+	{Name: "endpoint", Type: store.OptionTypeString, Description: "Bigtable data API endpoint (host:port) overriding the default one"},
+	{Name: "admin_endpoint", Type: store.OptionTypeString, Description: "Bigtable admin API endpoint (host:port) overriding the default one"},
+	{Name: "emulator_host", Type: store.OptionTypeString, Description: "Bigtable emulator address (host:port), dialed without credentials, overrides BIGTABLE_EMULATOR_HOST"},
+	{Name: "pool_size", Type: store.OptionTypeInt, Default: "4", Description: "Number of gRPC connections of the data client"},
+}
+
+// connectionOptions are the DSN options dialing Bigtable, shared by the data and admin clients.
+type connectionOptions struct {
+	appProfile      string
+	credentialsFile string
+	endpoint        string
+	adminEndpoint   string
+	emulatorHost    string
+	poolSize        int
+}
+
+func newConnectionOptions(dsnQuery store.DSNQuery) (out connectionOptions, err error) {
+	out.appProfile, _ = dsnQuery.StringOption("app_profile", "")
+	out.credentialsFile, _ = dsnQuery.StringOption("credentials_file", "")
+	out.endpoint, _ = dsnQuery.StringOption("endpoint", "")
+	out.adminEndpoint, _ = dsnQuery.StringOption("admin_endpoint", "")
+	out.emulatorHost, _ = dsnQuery.StringOption("emulator_host", "")
+
+	var rawValue string
+	if out.poolSize, rawValue, err = dsnQuery.IntOption("pool_size", defaultConnectionPoolSize); err != nil {
+		return out, fmt.Errorf("pool size option %q is not a valid integer: %w", rawValue, err)
+	}
+	if out.poolSize <= 0 {
+		return out, fmt.Errorf("pool size option must be positive, got %d", out.poolSize)
+	}
+
+	return out, nil
+}
+
+// clientOptions returns the options of a data client, or of an admin client when `admin`
+// is set, they are appended to the ones of the Bigtable client so they take precedence.
+func (o connectionOptions) clientOptions(ctx context.Context, admin bool) ([]option.ClientOption, error) {
+	if o.emulatorHost != "" {
+		// Same as Bigtable client with BIGTABLE_EMULATOR_HOST, the emulator is dialed directly
+		// without any credentials, the client owns the connection and closes it.
+		conn, err := grpc.DialContext(ctx, o.emulatorHost, grpc.WithInsecure())
+		if err != nil {
+			return nil, fmt.Errorf("dialing emulator: %w", err)
+		}
+		return []option.ClientOption{option.WithGRPCConn(conn)}, nil
+	}
+
+	var opts []option.ClientOption
+	if o.credentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(o.credentialsFile))
+	}
+
+	if admin {
+		if o.adminEndpoint != "" {
+			opts = append(opts, option.WithEndpoint(o.adminEndpoint))
+		}
+		return opts, nil
+	}
+
+	if o.endpoint != "" {
+		opts = append(opts, option.WithEndpoint(o.endpoint))
+	}
+	return append(opts, option.WithGRPCConnectionPool(o.poolSize)), nil
+}
+
+func (o connectionOptions) newClient(ctx context.Context, project, instance string) (*bigtable.Client, error) {
+	opts, err := o.clientOptions(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return bigtable.NewClientWithConfig(ctx, project, instance, bigtable.ClientConfig{AppProfile: o.appProfile}, opts...)
+}
+
+func (o connectionOptions) newAdminClient(ctx context.Context, project, instance string) (*bigtable.AdminClient, error) {
+	opts, err := o.clientOptions(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return bigtable.NewAdminClient(ctx, project, instance, opts...)
+}
+
+// dial returns a connection to the Bigtable data API for the low-level calls, the emulator
+// being dialed directly like the Bigtable client does.
+func (o connectionOptions) dial(ctx context.Context) (*grpc.ClientConn, error) {
+	if o.emulatorHost == "" {
+		if addr := os.Getenv(emulatorHostDefault); addr != "" {
+			return grpc.DialContext(ctx, addr, grpc.WithInsecure())
+		}
+	}
+
+	opts, err := o.clientOptions(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return gtransport.Dial(ctx, append([]option.ClientOption{option.WithEndpoint(bigtableEndpoint), option.WithScopes(bigtable.Scope)}, opts...)...)
+}
@@ -0,0 +1,66 @@
+package bigkv
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/streamingfast/kvdb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConnectionOptions(t *testing.T) {
+	query, err := url.ParseQuery("app_profile=batch&credentials_file=/etc/key.json&endpoint=bt:443&admin_endpoint=bta:443&pool_size=8")
+	require.NoError(t, err)
+
+	options, err := newConnectionOptions(store.DSNQuery(query))
+	require.NoError(t, err)
+	assert.Equal(t, connectionOptions{
+		appProfile:      "batch",
+		credentialsFile: "/etc/key.json",
+		endpoint:        "bt:443",
+		adminEndpoint:   "bta:443",
+		poolSize:        8,
+	}, options)
+
+	options, err = newConnectionOptions(store.DSNQuery(url.Values{}))
+	require.NoError(t, err)
+	assert.Equal(t, connectionOptions{poolSize: defaultConnectionPoolSize}, options)
+
+	_, err = newConnectionOptions(store.DSNQuery(url.Values{"pool_size": {"0"}}))
+	assert.Error(t, err)
+}
+
+func TestEmulatorHostAndGCPolicy(t *testing.T) {
+	ctx := context.Background()
+	s := newEmulatedStore(t, "gc_policy=max_age=24h|max_versions=2&column_families=meta:never&app_profile=default")
+
+	require.NoError(t, s.Put(ctx, []byte("k1"), []byte("v1")))
+	require.NoError(t, s.FlushPuts(ctx))
+
+	value, err := s.Get(ctx, []byte("k1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v1"), value)
+
+	admin, err := s.adminClient(ctx)
+	require.NoError(t, err)
+
+	info, err := admin.TableInfo(ctx, "table")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []bigtable.FamilyInfo{
+		{Name: "kv", GCPolicy: bigtable.UnionPolicy(bigtable.MaxAgePolicy(24*time.Hour), bigtable.MaxVersionsPolicy(2)).String()},
+		{Name: "meta", GCPolicy: bigtable.NoGcPolicy().String()},
+	}, info.FamilyInfos)
+
+	for _, invalid := range []string{
+		"bigkv://project.instance/table?emulator_host=localhost:1&gc_policy=max_versions=0",
+		"bigkv://project.instance/table?emulator_host=localhost:1&column_families=kv",
+		"bigkv://project.instance/table?emulator_host=localhost:1&pool_size=-1",
+	} {
+		_, err := store.New(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,141 @@
+package bigkv
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"cloud.google.com/go/bigtable"
+	btpb "google.golang.org/genproto/googleapis/bigtable/v2"
+)
+
+// Count implements `store.Counter`, rows are counted by Bigtable without sending back their value.
+func (s *Store) Count(ctx context.Context, start, exclusiveEnd []byte) (uint64, error) {
+	if len(exclusiveEnd) == 0 {
+		// Act like the other backends, consistent with `Scan`
+		return 0, nil
+	}
+
+	return s.count(ctx, bigtable.NewRange(string(s.withPrefix(start)), string(s.withPrefix(exclusiveEnd))))
+}
+
+// CountPrefix implements `store.Counter`, rows are counted by Bigtable without sending back their value.
+func (s *Store) CountPrefix(ctx context.Context, prefix []byte) (uint64, error) {
+	return s.count(ctx, prefixRowRange(s.withPrefix(prefix)).bigtableRange())
+}
+
+func (s *Store) count(ctx context.Context, rowSet bigtable.RowSet) (count uint64, err error) {
+	err = s.table.ReadRows(ctx, rowSet, func(_ bigtable.Row) bool {
+		count++
+		return true
+	}, bigtable.RowFilter(s.valuePresenceFilter()))
+
+	return
+}
+
+// ApproximateSize implements `store.Counter`, the estimate is derived from the offsets returned
+// by Bigtable `SampleRowKeys`, so it's as coarse as the samples are (in the order of hundreds of MiB).
+func (s *Store) ApproximateSize(ctx context.Context, start, exclusiveEnd []byte) (uint64, error) {
+	client, err := s.dataClient(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	samples, err := sampleRowKeys(ctx, client, &btpb.SampleRowKeysRequest{
+		TableName:    fmt.Sprintf("projects/%s/instances/%s/tables/%s", s.project, s.instance, s.tableName),
+		AppProfileId: s.connection.appProfile,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("sample row keys: %w", err)
+	}
+
+	endKey := prefixRowRange(s.keyPrefix).end
+	if len(exclusiveEnd) != 0 {
+		endKey = string(s.withPrefix(exclusiveEnd))
+	}
+
+	return sampledSize(samples, string(s.withPrefix(start)), endKey), nil
+}
+
+// dataClient returns the low-level Bigtable data client, the high-level one does not expose
+// the offsets returned by `SampleRowKeys`. The connection is dialed on first use.
+func (s *Store) dataClient(ctx context.Context) (btpb.BigtableClient, error) {
+	s.dataConnLock.Lock()
+	defer s.dataConnLock.Unlock()
+
+	if s.dataConn == nil {
+		conn, err := s.connection.dial(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("dialing bigtable: %w", err)
+		}
+		s.dataConn = conn
+	}
+
+	return btpb.NewBigtableClient(s.dataConn), nil
+}
+
+// rowKeySample is a sampled row key along the approximate amount of bytes stored in the rows preceding it.
+type rowKeySample struct {
+	key    string
+	offset uint64
+}
+
+func sampleRowKeys(ctx context.Context, client btpb.BigtableClient, request *btpb.SampleRowKeysRequest) (out []rowKeySample, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.SampleRowKeys(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return out, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, rowKeySample{key: string(resp.RowKey), offset: uint64(resp.OffsetBytes)})
+	}
+}
+
+// sampledSize estimates the size of the [start, end) range out of sorted samples, an empty end
+// meaning unbounded. The estimate errs on the large side, going from the last sample at or
+// before `start` to the first sample at or after `end`.
+func sampledSize(samples []rowKeySample, start, end string) uint64 {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	// An empty sampled key means the end of the table in Bigtable responses
+	at := func(key string) int {
+		return sort.Search(len(samples), func(i int) bool {
+			return samples[i].key == "" || samples[i].key >= key
+		})
+	}
+
+	startOffset := uint64(0)
+	if i := at(start); i < len(samples) && samples[i].key == start {
+		startOffset = samples[i].offset
+	} else if i > 0 {
+		startOffset = samples[i-1].offset
+	}
+
+	endOffset := samples[len(samples)-1].offset
+	if end != "" {
+		if i := at(end); i < len(samples) {
+			endOffset = samples[i].offset
+		}
+	}
+
+	if endOffset < startOffset {
+		return 0
+	}
+
+	return endOffset - startOffset
+}
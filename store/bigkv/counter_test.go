@@ -0,0 +1,38 @@
+package bigkv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampledSize(t *testing.T) {
+	samples := []rowKeySample{
+		{key: "b", offset: 100},
+		{key: "d", offset: 200},
+		{key: "f", offset: 300},
+		{key: "", offset: 350},
+	}
+
+	tests := []struct {
+		name     string
+		start    string
+		end      string
+		expected uint64
+	}{
+		{"whole table", "", "", 350},
+		{"on sample boundaries", "b", "d", 100},
+		{"between samples", "c", "e", 200},
+		{"unbounded end", "d", "", 150},
+		{"after last sample", "g", "", 50},
+		{"before first sample", "", "a", 100},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, sampledSize(samples, test.start, test.end))
+		})
+	}
+
+	assert.Equal(t, uint64(0), sampledSize(nil, "a", "b"))
+}
@@ -0,0 +1,111 @@
+package bigkv
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/streamingfast/kvdb/store"
+	"github.com/streamingfast/logging"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeleteRange implements `store.RangeDeleter`, Bigtable can only drop prefixes so rows are
+// read without their value and deleted with batched mutations.
+func (s *Store) DeleteRange(ctx context.Context, start, exclusiveEnd []byte) error {
+	if len(exclusiveEnd) == 0 {
+		// Act like the other backends, consistent with `Scan`
+		return nil
+	}
+
+	return s.deleteRows(ctx, bigtable.NewRange(string(s.withPrefix(start)), string(s.withPrefix(exclusiveEnd))))
+}
+
+// DeletePrefix implements `store.RangeDeleter` with Bigtable admin `DropRowRange`, falling
+// back to batched mutations when the credentials are not allowed to administer the table.
+func (s *Store) DeletePrefix(ctx context.Context, prefix []byte) error {
+	rowPrefix := s.withPrefix(prefix)
+
+	admin, err := s.adminClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(rowPrefix) == 0 {
+		err = admin.DropAllRows(ctx, s.tableName)
+	} else {
+		err = admin.DropRowRange(ctx, s.tableName, string(rowPrefix))
+	}
+
+	if status.Code(err) == codes.PermissionDenied {
+		logging.Logger(ctx, zlog).Debug("dropping row range not permitted, deleting rows instead", zap.Stringer("prefix", store.Key(prefix)))
+		return s.deleteRows(ctx, prefixRowRange(rowPrefix).bigtableRange())
+	}
+
+	if err != nil {
+		return fmt.Errorf("drop row range: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) deleteRows(ctx context.Context, rowSet bigtable.RowSet) error {
+	var keys []string
+	deleteBatch := func() error {
+		mutations := make([]*bigtable.Mutation, len(keys))
+		for i := range keys {
+			mutations[i] = bigtable.NewMutation()
+			mutations[i].DeleteRow()
+		}
+
+		errs, err := s.table.ApplyBulk(ctx, keys, mutations)
+		if err != nil {
+			return err
+		}
+		if len(errs) != 0 {
+			return fmt.Errorf("apply bulk error: %w", multierr.Combine(errs...))
+		}
+
+		keys = nil
+		return nil
+	}
+
+	var batchErr error
+	err := s.table.ReadRows(ctx, rowSet, func(row bigtable.Row) bool {
+		keys = append(keys, row.Key())
+		if len(keys) >= store.DeleteRangeMaxBatchSize {
+			batchErr = deleteBatch()
+		}
+		return batchErr == nil
+	}, bigtable.RowFilter(presenceFilter))
+
+	if err != nil {
+		return err
+	}
+	if batchErr != nil {
+		return batchErr
+	}
+
+	if len(keys) > 0 {
+		return deleteBatch()
+	}
+	return nil
+}
+
+// adminClient returns the Bigtable admin client, created on first use.
+func (s *Store) adminClient(ctx context.Context) (*bigtable.AdminClient, error) {
+	s.adminLock.Lock()
+	defer s.adminLock.Unlock()
+
+	if s.admin == nil {
+		admin, err := s.connection.newAdminClient(ctx, s.project, s.instance)
+		if err != nil {
+			return nil, fmt.Errorf("failed setting up admin client: %w", err)
+		}
+		s.admin = admin
+	}
+
+	return s.admin, nil
+}
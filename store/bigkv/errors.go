@@ -0,0 +1,25 @@
+package bigkv
+
+import (
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IsRetryableError implements `store.RetryableErrorClassifier`, transient gRPC
+// errors returned by Bigtable are retryable.
+func (s *Store) IsRetryableError(err error) bool {
+	// `status.FromError` does not unwrap errors, the status is looked up in the chain
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &grpcErr) {
+		return false
+	}
+
+	switch grpcErr.GRPCStatus().Code() {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+
+	return false
+}
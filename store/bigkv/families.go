@@ -0,0 +1,124 @@
+package bigkv
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// columnFamily is a column family created along the table, with the policy garbage collecting its cells.
+type columnFamily struct {
+	name     string
+	gcPolicy bigtable.GCPolicy
+}
+
+var defaultGCPolicy = bigtable.MaxVersionsPolicy(1)
+
+// parseColumnFamilies parses the `column_families` option, a comma separated list of `family`
+// or `family:policy` entries, families without a policy keep a single version of their cells.
+func parseColumnFamilies(raw string) (out []columnFamily, err error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	seen := map[string]bool{}
+	for _, entry := range strings.Split(raw, ",") {
+		name, rawPolicy, hasPolicy := strings.Cut(entry, ":")
+		if name == "" {
+			return nil, fmt.Errorf("column family %q has no name", entry)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("column family %q is listed more than once", name)
+		}
+		seen[name] = true
+
+		family := columnFamily{name: name, gcPolicy: defaultGCPolicy}
+		if hasPolicy {
+			if family.gcPolicy, err = parseGCPolicy(rawPolicy); err != nil {
+				return nil, fmt.Errorf("column family %q: %w", name, err)
+			}
+		}
+
+		out = append(out, family)
+	}
+
+	return out, nil
+}
+
+// parseGCPolicy parses a Bigtable GC policy made of `never`, `max_versions=<count>` or
+// `max_age=<duration>` rules, rules joined with `|` form a union collecting the cells
+// matching any of them.
+func parseGCPolicy(raw string) (bigtable.GCPolicy, error) {
+	var rules []bigtable.GCPolicy
+	for _, rawRule := range strings.Split(raw, "|") {
+		rule, err := parseGCRule(rawRule)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if len(rules) == 1 {
+		return rules[0], nil
+	}
+	return bigtable.UnionPolicy(rules...), nil
+}
+
+func parseGCRule(raw string) (bigtable.GCPolicy, error) {
+	if raw == "never" {
+		return bigtable.NoGcPolicy(), nil
+	}
+
+	name, rawValue, _ := strings.Cut(raw, "=")
+	switch name {
+	case "max_versions":
+		versions, err := strconv.ParseUint(rawValue, 10, 31)
+		if err != nil || versions == 0 {
+			return nil, fmt.Errorf("invalid gc rule %q, max versions must be a positive integer", raw)
+		}
+		return bigtable.MaxVersionsPolicy(int(versions)), nil
+
+	case "max_age":
+		age, err := time.ParseDuration(rawValue)
+		if err != nil || age <= 0 {
+			return nil, fmt.Errorf("invalid gc rule %q, max age must be a positive duration", raw)
+		}
+		return bigtable.MaxAgePolicy(age), nil
+	}
+
+	return nil, fmt.Errorf("invalid gc rule %q, expecting never, max_versions=<count> or max_age=<duration>", raw)
+}
+
+func hasColumnFamily(families []columnFamily, name string) bool {
+	for _, family := range families {
+		if family.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureTable creates the table and its column families when they do not exist, and applies
+// the GC policy of each family.
+func ensureTable(ctx context.Context, adminClient *bigtable.AdminClient, tableName string, families []columnFamily) error {
+	if err := adminClient.CreateTable(ctx, tableName); err != nil && !isAlreadyExistsError(err) {
+		return fmt.Errorf("failed creating table %q: %w", tableName, err)
+	}
+
+	for _, family := range families {
+		if err := adminClient.CreateColumnFamily(ctx, tableName, family.name); err != nil && !isAlreadyExistsError(err) {
+			return fmt.Errorf("failed creating %q family for table %q: %w", family.name, tableName, err)
+		}
+
+		if err := adminClient.SetGCPolicy(ctx, tableName, family.name, family.gcPolicy); err != nil {
+			return fmt.Errorf("failed applying gc policy to %q family for table %q: %w", family.name, tableName, err)
+		}
+	}
+
+	return nil
+}
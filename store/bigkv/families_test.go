@@ -0,0 +1,39 @@
+package bigkv
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseColumnFamilies(t *testing.T) {
+	families, err := parseColumnFamilies("meta,blocks:max_age=72h|max_versions=2,history:never")
+	require.NoError(t, err)
+	assert.Equal(t, []columnFamily{
+		{name: "meta", gcPolicy: bigtable.MaxVersionsPolicy(1)},
+		{name: "blocks", gcPolicy: bigtable.UnionPolicy(bigtable.MaxAgePolicy(72*time.Hour), bigtable.MaxVersionsPolicy(2))},
+		{name: "history", gcPolicy: bigtable.NoGcPolicy()},
+	}, families)
+
+	families, err = parseColumnFamilies("")
+	require.NoError(t, err)
+	assert.Nil(t, families)
+
+	for _, invalid := range []string{
+		"meta,meta",
+		":max_versions=1",
+		"meta:",
+		"meta:max_versions=0",
+		"meta:max_versions=abc",
+		"meta:max_age=-1h",
+		"meta:max_age=1",
+		"meta:max_size=10",
+		"meta:max_versions=1|",
+	} {
+		_, err := parseColumnFamilies(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
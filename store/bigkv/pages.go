@@ -0,0 +1,222 @@
+package bigkv
+
+import (
+	"context"
+	"regexp"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/streamingfast/kvdb/store"
+)
+
+// rowRange is a [start, end) range of row keys, an empty end meaning unbounded.
+type rowRange struct {
+	start string
+	end   string
+}
+
+func prefixRowRange(prefix []byte) rowRange {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xFF {
+			end := make([]byte, i+1)
+			copy(end, prefix)
+			end[i]++
+			return rowRange{start: string(prefix), end: string(end)}
+		}
+	}
+
+	// Empty prefix or prefix made only of 0xFF bytes, there is no upper bound
+	return rowRange{start: string(prefix)}
+}
+
+func (r rowRange) bigtableRange() bigtable.RowRange {
+	if r.end == "" {
+		return bigtable.InfiniteRange(r.start)
+	}
+
+	return bigtable.NewRange(r.start, r.end)
+}
+
+func bigtableRowSet(ranges []rowRange) bigtable.RowSet {
+	if len(ranges) == 1 {
+		return ranges[0].bigtableRange()
+	}
+
+	out := make(bigtable.RowRangeList, len(ranges))
+	for i, r := range ranges {
+		out[i] = r.bigtableRange()
+	}
+	return out
+}
+
+// rowRangesAfter returns the parts of the ranges containing only keys strictly
+// greater than `lastKey`.
+func rowRangesAfter(ranges []rowRange, lastKey string) (out []rowRange) {
+	next := lastKey + "\x00"
+	for _, r := range ranges {
+		if r.end != "" && r.end <= next {
+			continue
+		}
+
+		if r.start < next {
+			r.start = next
+		}
+		out = append(out, r)
+	}
+	return
+}
+
+// readRows reads the rows of the ranges, in pages of `ReadOptions.PrefetchSize` rows
+// when set so that a single Bigtable read never holds more than a page in flight.
+func (s *Store) readRows(ctx context.Context, ranges []rowRange, limit store.Limit, options []store.ReadOption, onRow func(kv store.KV) bool) error {
+	readOptions := store.NewReadOptions(options...)
+	filters, err := s.newReadFilters(readOptions)
+	if err != nil {
+		return err
+	}
+
+	pageSize := 0
+	if readOptions != nil {
+		pageSize = readOptions.PrefetchSize
+	}
+
+	var client *store.ReadFilter
+	serverLimit := limit
+	if filters != nil && filters.client != nil {
+		client = filters.client
+		serverLimit = store.Unlimited
+	}
+
+	count := uint64(0)
+	stopped := false
+	var decodeErr error
+	emit := func(row bigtable.Row) bool {
+		kv, err := s.rowKV(row, filters.stripsValue())
+		if err != nil {
+			decodeErr = err
+			return false
+		}
+
+		if client != nil {
+			if !client.Match(kv.Key, kv.Value) {
+				return true
+			}
+
+			if filters.keyOnly {
+				kv.Value = nil
+			}
+		}
+
+		count++
+		if !onRow(kv) || limit.Reached(count) {
+			stopped = true
+			return false
+		}
+		return true
+	}
+
+	if pageSize <= 0 {
+		if err := s.table.ReadRows(ctx, bigtableRowSet(ranges), emit, s.bigtableReadOptions(serverLimit, filters)...); err != nil {
+			return err
+		}
+		return decodeErr
+	}
+
+	for len(ranges) > 0 {
+		pageLimit := store.Limit(pageSize)
+		if serverLimit.Bounded() && uint64(serverLimit)-count < uint64(pageSize) {
+			pageLimit = store.Limit(uint64(serverLimit) - count)
+		}
+
+		var lastKey string
+		pageCount := 0
+		err := s.table.ReadRows(ctx, bigtableRowSet(ranges), func(row bigtable.Row) bool {
+			lastKey = row.Key()
+			pageCount++
+
+			return emit(row)
+		}, s.bigtableReadOptions(pageLimit, filters)...)
+		if err != nil {
+			return err
+		}
+		if decodeErr != nil {
+			return decodeErr
+		}
+
+		if stopped || pageCount < int(pageLimit) {
+			return nil
+		}
+
+		ranges = rowRangesAfter(ranges, lastKey)
+	}
+
+	return nil
+}
+
+// readFilters holds the filtering read options of a read, split between the ones pushed
+// down to Bigtable and the ones applied to the rows received.
+type readFilters struct {
+	keyOnly         bool
+	keyRegexPattern string
+	valuePrefix     []byte
+
+	client *store.ReadFilter
+}
+
+func (s *Store) newReadFilters(readOptions *store.ReadOptions) (*readFilters, error) {
+	if readOptions == nil {
+		return nil, nil
+	}
+
+	// Validates the options, also catching invalid regular expressions before sending them
+	if _, err := store.NewReadFilter(readOptions); err != nil {
+		return nil, err
+	}
+
+	filters := &readFilters{keyOnly: readOptions.KeyOnly}
+	clientOptions := &store.ReadOptions{MinValueSize: readOptions.MinValueSize, MaxValueSize: readOptions.MaxValueSize}
+
+	// Bigtable sees the first chunk of the stored values, it can match a prefix only when
+	// values are not compressed and the prefix fits in a chunk
+	if s.compressed || (s.chunkSize > 0 && len(readOptions.ValuePrefix) > s.chunkSize) {
+		clientOptions.ValuePrefix = readOptions.ValuePrefix
+	} else {
+		filters.valuePrefix = readOptions.ValuePrefix
+	}
+
+	if readOptions.KeyRegex != "" {
+		if pattern, ok := s.keyRegexPattern(readOptions.KeyRegex); ok {
+			filters.keyRegexPattern = pattern
+		} else {
+			clientOptions.KeyRegex = readOptions.KeyRegex
+		}
+	}
+
+	var err error
+	if filters.client, err = store.NewReadFilter(clientOptions); err != nil {
+		return nil, err
+	}
+
+	return filters, nil
+}
+
+// keyRegexPattern returns the Bigtable row key regex matching keys whose un-prefixed part
+// matches `expr`, it's not possible when the key prefix is not made of printable ASCII.
+func (s *Store) keyRegexPattern(expr string) (string, bool) {
+	for _, b := range s.keyPrefix {
+		if b < 0x20 || b > 0x7E {
+			return "", false
+		}
+	}
+
+	// Bigtable regular expressions must match the entire row key
+	return regexp.QuoteMeta(string(s.keyPrefix)) + "(?:" + expr + ")", true
+}
+
+func (f *readFilters) needsValue() bool {
+	return f.client.NeedsValue()
+}
+
+// stripsValue tells if rows are read with their values stripped by Bigtable.
+func (f *readFilters) stripsValue() bool {
+	return f != nil && f.keyOnly && !f.needsValue()
+}
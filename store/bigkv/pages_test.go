@@ -0,0 +1,60 @@
+package bigkv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixRowRange(t *testing.T) {
+	assert.Equal(t, rowRange{start: "ab", end: "ac"}, prefixRowRange([]byte("ab")))
+	assert.Equal(t, rowRange{start: "a\xff", end: "b"}, prefixRowRange([]byte("a\xff")))
+	assert.Equal(t, rowRange{start: "\xff\xff"}, prefixRowRange([]byte("\xff\xff")))
+	assert.Equal(t, rowRange{start: ""}, prefixRowRange(nil))
+}
+
+func TestRowRangesAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		ranges   []rowRange
+		lastKey  string
+		expected []rowRange
+	}{
+		{
+			name:     "within single range",
+			ranges:   []rowRange{{start: "a", end: "c"}},
+			lastKey:  "b",
+			expected: []rowRange{{start: "b\x00", end: "c"}},
+		},
+		{
+			name:     "unbounded range",
+			ranges:   []rowRange{{start: "a"}},
+			lastKey:  "b",
+			expected: []rowRange{{start: "b\x00"}},
+		},
+		{
+			name:     "drops consumed ranges",
+			ranges:   []rowRange{{start: "a", end: "b"}, {start: "ba", end: "bb"}, {start: "c", end: "d"}},
+			lastKey:  "ba1",
+			expected: []rowRange{{start: "ba1\x00", end: "bb"}, {start: "c", end: "d"}},
+		},
+		{
+			name:     "last key of range end",
+			ranges:   []rowRange{{start: "a", end: "a\x00"}, {start: "c", end: "d"}},
+			lastKey:  "a",
+			expected: []rowRange{{start: "c", end: "d"}},
+		},
+		{
+			name:     "last key in later range",
+			ranges:   []rowRange{{start: "a", end: "b"}, {start: "c", end: "d"}},
+			lastKey:  "c",
+			expected: []rowRange{{start: "c\x00", end: "d"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, rowRangesAfter(test.ranges, test.lastKey))
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package bigkv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/streamingfast/kvdb/store"
+)
+
+// SplitRange implements `store.Splitter`, splits are picked among the row keys sampled by
+// Bigtable `SampleRowKeys`, which delimit sections of the table of roughly the same size.
+func (s *Store) SplitRange(ctx context.Context, start, exclusiveEnd []byte, approxChunks int) ([][]byte, error) {
+	sampledKeys, err := s.table.SampleRowKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sample row keys: %w", err)
+	}
+
+	var candidates [][]byte
+	for _, key := range sampledKeys {
+		if bytes.HasPrefix([]byte(key), s.keyPrefix) {
+			candidates = append(candidates, s.withoutPrefix([]byte(key)))
+		}
+	}
+
+	return store.BalancedSplits(candidates, start, exclusiveEnd, approxChunks), nil
+}
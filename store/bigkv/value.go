@@ -0,0 +1,123 @@
+package bigkv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/streamingfast/kvdb/store"
+)
+
+const (
+	// chunkCountColumn is the column qualifier holding the number of chunks of a value split
+	// in many cells, the first chunk is in `valueColumn` and the next ones in `valueColumn`
+	// suffixed by their index (`v1`, `v2`, ...). Chunks left by an older value of the key
+	// are ignored, writing a value not split in chunks deletes the chunk count.
+	chunkCountColumn = valueColumn + "n"
+
+	// valueColumnsPattern matches the qualifiers of the cells holding a value
+	valueColumnsPattern = valueColumn + "(n|[0-9]+)?"
+
+	defaultChunkSize                = 8 * 1024 * 1024
+	defaultCompressionSizeThreshold = 512 * 1024
+)
+
+// valueMutation returns the mutation writing `value`, compressed and split in chunks of at
+// most `chunkSize` bytes, along the amount of bytes written.
+func (s *Store) valueMutation(value []byte) (*bigtable.Mutation, int) {
+	encoded := s.compressor.Compress(value)
+
+	mut := bigtable.NewMutation()
+	timestamp := bigtable.Now()
+	if s.chunkSize <= 0 || len(encoded) <= s.chunkSize {
+		mut.Set(s.columnName, valueColumn, timestamp, encoded)
+		mut.DeleteCellsInColumn(s.columnName, chunkCountColumn)
+		return mut, len(encoded)
+	}
+
+	chunkCount := 0
+	for offset := 0; offset < len(encoded); offset += s.chunkSize {
+		end := offset + s.chunkSize
+		if end > len(encoded) {
+			end = len(encoded)
+		}
+
+		mut.Set(s.columnName, chunkColumn(chunkCount), timestamp, encoded[offset:end])
+		chunkCount++
+	}
+	mut.Set(s.columnName, chunkCountColumn, timestamp, []byte(strconv.Itoa(chunkCount)))
+
+	return mut, len(encoded)
+}
+
+func chunkColumn(index int) string {
+	if index == 0 {
+		return valueColumn
+	}
+	return valueColumn + strconv.Itoa(index)
+}
+
+// rowValue returns the value of a row read with `valueFilter`, its chunks reassembled
+// and decompressed.
+func (s *Store) rowValue(row bigtable.Row) ([]byte, error) {
+	var first, chunkCount *bigtable.ReadItem
+	chunks := map[string]*bigtable.ReadItem{}
+	for i, item := range row[s.columnName] {
+		switch qualifier := strings.TrimPrefix(item.Column, s.columnName+":"); qualifier {
+		case valueColumn:
+			first = &row[s.columnName][i]
+		case chunkCountColumn:
+			chunkCount = &row[s.columnName][i]
+		default:
+			chunks[qualifier] = &row[s.columnName][i]
+		}
+	}
+
+	if first == nil {
+		return nil, fmt.Errorf("row %s has no value cell", store.Key(s.withoutPrefix([]byte(row.Key()))))
+	}
+
+	value := first.Value
+	if chunkCount != nil {
+		count, err := strconv.Atoi(string(chunkCount.Value))
+		if err != nil {
+			return nil, fmt.Errorf("row %s has an invalid chunk count %q", store.Key(s.withoutPrefix([]byte(row.Key()))), chunkCount.Value)
+		}
+
+		size := len(first.Value)
+		for i := 1; i < count; i++ {
+			chunk := chunks[chunkColumn(i)]
+			if chunk == nil {
+				return nil, fmt.Errorf("row %s is missing chunk %d of %d", store.Key(s.withoutPrefix([]byte(row.Key()))), i, count)
+			}
+			size += len(chunk.Value)
+		}
+
+		value = make([]byte, 0, size)
+		value = append(value, first.Value...)
+		for i := 1; i < count; i++ {
+			value = append(value, chunks[chunkColumn(i)].Value...)
+		}
+	}
+
+	value, err := s.compressor.Decompress(value)
+	if err != nil {
+		return nil, fmt.Errorf("decompress value: %w", err)
+	}
+
+	return value, nil
+}
+
+// rowKV returns the key and value of a row read with `valueFilter`, `keyOnly` rows having
+// their values stripped are not decoded.
+func (s *Store) rowKV(row bigtable.Row, keyOnly bool) (store.KV, error) {
+	kv := store.KV{Key: s.withoutPrefix([]byte(row.Key()))}
+	if keyOnly {
+		return kv, nil
+	}
+
+	var err error
+	kv.Value, err = s.rowValue(row)
+	return kv, err
+}
@@ -0,0 +1,82 @@
+package bigkv
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/streamingfast/kvdb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readCells returns the latest cells of the row at `key` by column, as stored in Bigtable.
+func readCells(t *testing.T, s *Store, key string) map[string][]byte {
+	t.Helper()
+
+	row, err := s.table.ReadRow(context.Background(), string(s.withPrefix([]byte(key))), bigtable.RowFilter(latestCellFilter))
+	require.NoError(t, err)
+
+	out := map[string][]byte{}
+	for _, items := range row {
+		for _, item := range items {
+			out[item.Column] = item.Value
+		}
+	}
+	return out
+}
+
+func TestValueChunks(t *testing.T) {
+	ctx := context.Background()
+	s := newEmulatedStore(t, "chunk_size=4")
+
+	require.NoError(t, s.Put(ctx, []byte("k1"), []byte("0123456789")))
+	require.NoError(t, s.Put(ctx, []byte("k2"), []byte("0123")))
+	require.NoError(t, s.FlushPuts(ctx))
+
+	assert.Equal(t, map[string][]byte{
+		"kv:v":  []byte("0123"),
+		"kv:v1": []byte("4567"),
+		"kv:v2": []byte("89"),
+		"kv:vn": []byte("3"),
+	}, readCells(t, s, "k1"))
+
+	value, err := s.Get(ctx, []byte("k1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("0123456789"), value)
+
+	// Chunks of the previous value are ignored once it's overwritten
+	require.NoError(t, s.Put(ctx, []byte("k1"), []byte("abcdef")))
+	require.NoError(t, s.FlushPuts(ctx))
+
+	value, err = s.Get(ctx, []byte("k1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("abcdef"), value)
+
+	require.NoError(t, s.Put(ctx, []byte("k1"), []byte("xy")))
+	require.NoError(t, s.FlushPuts(ctx))
+
+	kvs, err := store.Collect(s.Prefix(ctx, []byte("k"), store.Unlimited))
+	require.NoError(t, err)
+	assert.Equal(t, []store.KV{{Key: []byte("k1"), Value: []byte("xy")}, {Key: []byte("k2"), Value: []byte("0123")}}, kvs)
+
+	kvs, err = store.Collect(s.Prefix(ctx, []byte("k"), store.Unlimited, store.WithValuePrefix([]byte("01"))))
+	require.NoError(t, err)
+	assert.Equal(t, []store.KV{{Key: []byte("k2"), Value: []byte("0123")}}, kvs)
+}
+
+func TestLargeValues(t *testing.T) {
+	ctx := context.Background()
+	s := newEmulatedStore(t, "compression=zstd&chunk_size=65536")
+
+	large := bytes.Repeat([]byte("block payload "), 100000)
+	require.NoError(t, s.Put(ctx, []byte("large"), large))
+	require.NoError(t, s.FlushPuts(ctx))
+
+	value, err := s.Get(ctx, []byte("large"))
+	require.NoError(t, err)
+	assert.Equal(t, large, value)
+
+	assert.Less(t, len(readCells(t, s, "large")["kv:v"]), len(large), "value should be stored compressed")
+}
@@ -0,0 +1,74 @@
+package store
+
+// DriverCapabilities describes the features a driver exposes through the kvdb API, so
+// that applications can choose a strategy at runtime, for example writing keys twice to
+// read them in both directions when reverse scans are not supported. A driver only
+// reports a capability once callers can reach it, a backend supporting a feature that
+// the driver does not expose does not report it.
+type DriverCapabilities struct {
+	// ReverseScan is set when the backend can scan keys in descending order.
+	ReverseScan bool
+	// TTL is set when the backend can expire keys by itself.
+	TTL bool
+	// EmptyValues is set when 0-length values can be stored as-is, without `WithEmptyValue`.
+	EmptyValues bool
+	// Transactions is set when the backend can apply multiple operations atomically.
+	Transactions bool
+	// AtomicOps is set when the backend supports single key read-modify-write operations,
+	// like compare-and-swap.
+	AtomicOps bool
+}
+
+func (c *DriverCapabilities) named() []struct {
+	name string
+	set  *bool
+} {
+	return []struct {
+		name string
+		set  *bool
+	}{
+		{"reverse_scan", &c.ReverseScan},
+		{"ttl", &c.TTL},
+		{"empty_values", &c.EmptyValues},
+		{"transactions", &c.Transactions},
+		{"atomic_ops", &c.AtomicOps},
+	}
+}
+
+// Names returns the names of the capabilities that are set.
+func (c DriverCapabilities) Names() (out []string) {
+	for _, capability := range c.named() {
+		if *capability.set {
+			out = append(out, capability.name)
+		}
+	}
+	return
+}
+
+// CapabilitiesFromNames is the reverse of `DriverCapabilities.Names`, unknown names are
+// ignored.
+func CapabilitiesFromNames(names []string) (out DriverCapabilities) {
+	for _, capability := range out.named() {
+		for _, name := range names {
+			if name == capability.name {
+				*capability.set = true
+			}
+		}
+	}
+	return
+}
+
+// CapabilitiesReporter is implemented by stores able to tell the capabilities of their
+// backend, wrappers of other stores report the ones of the store they wrap.
+type CapabilitiesReporter interface {
+	Capabilities() DriverCapabilities
+}
+
+// Capabilities returns the capabilities of the store's backend, none are reported for
+// stores not implementing `CapabilitiesReporter`.
+func Capabilities(s KVStore) DriverCapabilities {
+	if reporter, ok := s.(CapabilitiesReporter); ok {
+		return reporter.Capabilities()
+	}
+	return DriverCapabilities{}
+}
@@ -0,0 +1,22 @@
+package store
+
+import "context"
+
+// Compactor is implemented by store drivers able to reclaim, on demand, the storage used
+// by deleted, overwritten and expired keys. Backends doing it continuously by themselves
+// don't need to implement it.
+type Compactor interface {
+	// Compact reclaims the space it can before returning, it can take a long time on
+	// large stores.
+	Compact(ctx context.Context) error
+}
+
+// Compact compacts the store's storage, `ErrNotSupported` if the store does not
+// implement `Compactor`.
+func Compact(ctx context.Context, s KVStore) error {
+	if compactor, ok := s.(Compactor); ok {
+		return compactor.Compact(ctx)
+	}
+
+	return ErrNotSupported
+}
@@ -0,0 +1,12 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompact_NotSupported(t *testing.T) {
+	assert.ErrorIs(t, Compact(context.Background(), newTestCursorStore()), ErrNotSupported)
+}
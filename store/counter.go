@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotSupported is returned by operations the store driver cannot perform.
+var ErrNotSupported = errors.New("not supported")
+
+// Counter is implemented by store drivers able to count keys and to estimate the size
+// of a range of keys without shipping them to the caller. Use `Count`, `CountPrefix`
+// and `ApproximateSize` functions which degrade to standard reads for the other drivers.
+type Counter interface {
+	// Count returns the number of keys `Scan(ctx, start, exclusiveEnd, Unlimited)` would return.
+	Count(ctx context.Context, start, exclusiveEnd []byte) (uint64, error)
+	// CountPrefix returns the number of keys `Prefix(ctx, prefix, Unlimited)` would return.
+	CountPrefix(ctx context.Context, prefix []byte) (uint64, error)
+	// ApproximateSize returns an estimate, in bytes, of the storage used by the keys in
+	// [start, exclusiveEnd) based on backend statistics, an empty `exclusiveEnd` meaning
+	// no upper bound. The estimate can be coarse, it's meant for planning, not accounting.
+	ApproximateSize(ctx context.Context, start, exclusiveEnd []byte) (uint64, error)
+}
+
+// Count returns the number of keys in [start, exclusiveEnd), using the store's
+// `Counter` implementation when available, a key-only scan otherwise.
+func Count(ctx context.Context, s KVStore, start, exclusiveEnd []byte) (uint64, error) {
+	if counter, ok := s.(Counter); ok {
+		return counter.Count(ctx, start, exclusiveEnd)
+	}
+
+	return countKeys(s.Scan(ctx, start, exclusiveEnd, Unlimited, KeyOnly()))
+}
+
+// CountPrefix returns the number of keys starting with `prefix`, using the store's
+// `Counter` implementation when available, a key-only prefix read otherwise.
+func CountPrefix(ctx context.Context, s KVStore, prefix []byte) (uint64, error) {
+	if counter, ok := s.(Counter); ok {
+		return counter.CountPrefix(ctx, prefix)
+	}
+
+	return countKeys(s.Prefix(ctx, prefix, Unlimited, KeyOnly()))
+}
+
+// ApproximateSize returns the store's estimate of the storage used by the keys in
+// [start, exclusiveEnd), `ErrNotSupported` if the store does not implement `Counter`.
+func ApproximateSize(ctx context.Context, s KVStore, start, exclusiveEnd []byte) (uint64, error) {
+	if counter, ok := s.(Counter); ok {
+		return counter.ApproximateSize(ctx, start, exclusiveEnd)
+	}
+
+	return 0, ErrNotSupported
+}
+
+func countKeys(it *Iterator) (count uint64, err error) {
+	for it.Next() {
+		count++
+	}
+
+	return count, it.Err()
+}
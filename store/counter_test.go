@@ -0,0 +1,30 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCount_Fallback(t *testing.T) {
+	s := newTestCursorStore()
+
+	count, err := Count(context.Background(), s, []byte("b"), []byte("c"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), count)
+
+	count, err = CountPrefix(context.Background(), s, []byte("ba"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), count)
+
+	count, err = CountPrefix(context.Background(), s, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), count)
+}
+
+func TestApproximateSize_NotSupported(t *testing.T) {
+	_, err := ApproximateSize(context.Background(), newTestCursorStore(), nil, nil)
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
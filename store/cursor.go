@@ -0,0 +1,202 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Cursor is a continuation point of a Scan, Prefix or BatchPrefix read, it holds the
+// read parameters as well as the last key the read emitted. Use `Iterator.Cursor()`
+// to obtain one and `ReadFromCursor` to continue the read right after the last key
+// it emitted.
+//
+// The cursor can be serialized with `Bytes()` and parsed back with `ParseCursor`,
+// the serialized form must be treated as opaque.
+type Cursor struct {
+	request readSegment
+	lastKey []byte
+}
+
+// CursorReader is implemented by store drivers that can continue a read from a cursor
+// natively, `ReadFromCursor` degrades to standard reads for the other ones.
+type CursorReader interface {
+	ReadFromCursor(ctx context.Context, cursor *Cursor, limit int) *Iterator
+}
+
+// NewScanIterator is like NewIterator but the returned iterator is able to provide
+// a `Cursor` continuing the `Scan` call made with the same arguments.
+func NewScanIterator(ctx context.Context, start, exclusiveEnd []byte, limit int, options []ReadOption) *Iterator {
+	return newCursorIterator(ctx, readSegment{kind: scanSegment, start: start, end: exclusiveEnd, options: options}, nil, Limit(limit))
+}
+
+// NewPrefixIterator is like NewIterator but the returned iterator is able to provide
+// a `Cursor` continuing the `Prefix` call made with the same arguments.
+func NewPrefixIterator(ctx context.Context, prefix []byte, limit int, options []ReadOption) *Iterator {
+	return newCursorIterator(ctx, readSegment{kind: prefixSegment, prefixes: [][]byte{prefix}, options: options}, nil, Limit(limit))
+}
+
+// NewBatchPrefixIterator is like NewIterator but the returned iterator is able to provide
+// a `Cursor` continuing the `BatchPrefix` call made with the same arguments.
+func NewBatchPrefixIterator(ctx context.Context, prefixes [][]byte, limit int, options []ReadOption) *Iterator {
+	return newCursorIterator(ctx, readSegment{kind: batchPrefixSegment, prefixes: prefixes, options: options}, nil, Limit(limit))
+}
+
+// NewCursorIterator is like NewIterator but the returned iterator is able to provide
+// a `Cursor` continuing the `ReadFromCursor` call made with the same arguments. It's
+// meant for `CursorReader` implementations.
+func NewCursorIterator(ctx context.Context, cursor *Cursor, limit int) *Iterator {
+	return newCursorIterator(ctx, cursor.request, cursor.lastKey, Limit(limit))
+}
+
+func newCursorIterator(ctx context.Context, request readSegment, lastKey []byte, limit Limit) *Iterator {
+	it := NewIterator(ctx, request.options...)
+	it.cursor = &iteratorCursor{request: request, lastKey: lastKey, limit: limit}
+	return it
+}
+
+// ReadFromCursor continues the read the cursor was obtained from, returning at most
+// `limit` items all strictly after the cursor's last key. Read options of the original
+// read are used unless new ones are provided. The returned iterator can itself
+// provide a cursor, which is useful to paginate through a large read.
+func ReadFromCursor(ctx context.Context, s KVStore, cursor *Cursor, limit int, options ...ReadOption) *Iterator {
+	if len(options) > 0 {
+		cursor = cursor.withOptions(options)
+	}
+
+	if reader, ok := s.(CursorReader); ok {
+		return reader.ReadFromCursor(ctx, cursor, limit)
+	}
+
+	segments := []readSegment{cursor.request}
+	if cursor.lastKey != nil {
+		segments = cursor.request.resumeAfter(cursor.lastKey, 0)
+	}
+
+	it := newCursorIterator(ctx, cursor.request, cursor.lastKey, Limit(limit))
+	go readSegments(ctx, s, it, Limit(limit), segments, nil)
+
+	return it
+}
+
+// LastKey returns the last key emitted by the read, nil if it did not emit any key yet.
+func (c *Cursor) LastKey() []byte {
+	return c.lastKey
+}
+
+// KeyRange returns the range of keys the read continued by the cursor covers, a nil
+// `exclusiveEnd` meaning the range is not bounded. For prefix reads, the range spans
+// from the lowest prefix up to the end of the highest one.
+func (c *Cursor) KeyRange() (start, exclusiveEnd []byte) {
+	if c.request.kind == scanSegment {
+		return c.request.start, c.request.end
+	}
+
+	for i, prefix := range c.request.prefixes {
+		end := prefixExclusiveEnd(prefix)
+		if i == 0 {
+			start, exclusiveEnd = prefix, end
+			continue
+		}
+
+		if bytes.Compare(prefix, start) < 0 {
+			start = prefix
+		}
+		if exclusiveEnd != nil && (end == nil || bytes.Compare(end, exclusiveEnd) > 0) {
+			exclusiveEnd = end
+		}
+	}
+
+	return start, exclusiveEnd
+}
+
+// ReadOptions returns the read options of the read the cursor continues.
+func (c *Cursor) ReadOptions() []ReadOption {
+	return c.request.options
+}
+
+func (c *Cursor) withOptions(options []ReadOption) *Cursor {
+	request := c.request
+	request.options = options
+
+	return &Cursor{request: request, lastKey: c.lastKey}
+}
+
+// cursorState is the serialized form of a Cursor
+type cursorState struct {
+	Version  int             `json:"v"`
+	Kind     readSegmentKind `json:"k"`
+	Start    []byte          `json:"s,omitempty"`
+	End      []byte          `json:"e,omitempty"`
+	Prefixes [][]byte        `json:"p,omitempty"`
+	Options  *ReadOptions    `json:"o,omitempty"`
+	LastKey  []byte          `json:"l,omitempty"`
+}
+
+const cursorVersion = 1
+
+// Bytes returns the opaque serialized form of the cursor.
+func (c *Cursor) Bytes() []byte {
+	out, err := json.Marshal(&cursorState{
+		Version:  cursorVersion,
+		Kind:     c.request.kind,
+		Start:    c.request.start,
+		End:      c.request.end,
+		Prefixes: c.request.prefixes,
+		Options:  NewReadOptions(c.request.options...),
+		LastKey:  c.lastKey,
+	})
+	if err != nil {
+		panic(fmt.Errorf("cursor state should always be serializable: %w", err))
+	}
+
+	return out
+}
+
+// ParseCursor parses a cursor previously serialized with `Cursor.Bytes()`.
+func ParseCursor(in []byte) (*Cursor, error) {
+	state := &cursorState{}
+	if err := json.Unmarshal(in, state); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	if state.Version != cursorVersion {
+		return nil, fmt.Errorf("invalid cursor: unsupported version %d", state.Version)
+	}
+
+	request := readSegment{kind: state.Kind, start: state.Start, end: state.End, prefixes: state.Prefixes}
+	switch state.Kind {
+	case scanSegment:
+	case prefixSegment:
+		if len(state.Prefixes) != 1 {
+			return nil, fmt.Errorf("invalid cursor: prefix read expects exactly one prefix, got %d", len(state.Prefixes))
+		}
+	case batchPrefixSegment:
+	default:
+		return nil, fmt.Errorf("invalid cursor: unsupported read kind %d", state.Kind)
+	}
+
+	if state.Options != nil {
+		request.options = []ReadOption{readOptionsOption{*state.Options}}
+	}
+
+	return &Cursor{request: request, lastKey: state.LastKey}, nil
+}
+
+// iteratorCursor tracks, from the consumer side, the position of an iterator in the read
+// it was created for.
+type iteratorCursor struct {
+	request readSegment
+	lastKey []byte
+	limit   Limit
+	count   uint64
+}
+
+type readOptionsOption struct {
+	options ReadOptions
+}
+
+func (o readOptionsOption) Apply(opts *ReadOptions) {
+	*opts = o.options
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCursorStore() *memoryKVStore {
+	return newMemoryKVStore(
+		KV{Key: []byte("a"), Value: []byte("1")},
+		KV{Key: []byte("ba"), Value: []byte("2")},
+		KV{Key: []byte("ba1"), Value: []byte("3")},
+		KV{Key: []byte("ba2"), Value: []byte("4")},
+		KV{Key: []byte("bb"), Value: []byte("5")},
+		KV{Key: []byte("c"), Value: []byte("6")},
+	)
+}
+
+func TestCursor_Paginate(t *testing.T) {
+	tests := []struct {
+		name          string
+		read          func(s KVStore, limit int) *Iterator
+		expectedPages [][]string
+	}{
+		{
+			name: "scan",
+			read: func(s KVStore, limit int) *Iterator {
+				return s.Scan(context.Background(), []byte("a"), []byte("c"), limit)
+			},
+			expectedPages: [][]string{{"a", "ba"}, {"ba1", "ba2"}, {"bb"}},
+		},
+		{
+			name:          "prefix",
+			read:          func(s KVStore, limit int) *Iterator { return s.Prefix(context.Background(), []byte("b"), limit) },
+			expectedPages: [][]string{{"ba", "ba1"}, {"ba2", "bb"}, {}},
+		},
+		{
+			name:          "empty prefix",
+			read:          func(s KVStore, limit int) *Iterator { return s.Prefix(context.Background(), nil, limit) },
+			expectedPages: [][]string{{"a", "ba"}, {"ba1", "ba2"}, {"bb", "c"}, {}},
+		},
+		{
+			name: "batch prefix",
+			read: func(s KVStore, limit int) *Iterator {
+				return s.BatchPrefix(context.Background(), [][]byte{[]byte("a"), []byte("ba"), []byte("c")}, limit)
+			},
+			expectedPages: [][]string{{"a", "ba"}, {"ba1", "ba2"}, {"c"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestCursorStore()
+
+			var pages [][]string
+			it := test.read(s, 2)
+			for {
+				keys, err := testCollectKeys(t, it)
+				require.NoError(t, err)
+				pages = append(pages, append([]string{}, keys...))
+
+				cursor := it.Cursor()
+				if cursor == nil {
+					break
+				}
+
+				// Round trip through the serialized form like a paginated API would
+				cursor, err = ParseCursor(cursor.Bytes())
+				require.NoError(t, err)
+
+				it = ReadFromCursor(context.Background(), s, cursor, 2)
+			}
+
+			assert.Equal(t, test.expectedPages, pages)
+		})
+	}
+}
+
+func TestCursor_ReadOptions(t *testing.T) {
+	s := newTestCursorStore()
+
+	it := s.Scan(context.Background(), []byte("a"), []byte("c"), 1, KeyOnly())
+	_, err := testCollectKeys(t, it)
+	require.NoError(t, err)
+
+	cursor, err := ParseCursor(it.Cursor().Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a"), cursor.LastKey())
+
+	it = ReadFromCursor(context.Background(), s, cursor, 1)
+	require.True(t, it.Next())
+	assert.Equal(t, KV{Key: []byte("ba")}, it.Item())
+}
+
+func TestCursor_NotScoped(t *testing.T) {
+	it := newTestCursorStore().BatchGet(context.Background(), [][]byte{[]byte("a")})
+	_, err := testCollectKeys(t, it)
+	require.NoError(t, err)
+
+	assert.Nil(t, it.Cursor())
+}
+
+func TestParseCursor(t *testing.T) {
+	_, err := ParseCursor([]byte("not json"))
+	assert.Error(t, err)
+
+	_, err = ParseCursor([]byte(`{"v":2,"k":1}`))
+	assert.EqualError(t, err, "invalid cursor: unsupported version 2")
+
+	_, err = ParseCursor([]byte(`{"v":1,"k":2,"p":[]}`))
+	assert.EqualError(t, err, "invalid cursor: prefix read expects exactly one prefix, got 0")
+
+	_, err = ParseCursor([]byte(`{"v":1,"k":0}`))
+	assert.EqualError(t, err, "invalid cursor: unsupported read kind 0")
+}
+
+func TestCursor_KeyRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		cursor      *Cursor
+		expectStart []byte
+		expectEnd   []byte
+	}{
+		{"scan", &Cursor{request: readSegment{kind: scanSegment, start: []byte("a"), end: []byte("c")}}, []byte("a"), []byte("c")},
+		{"prefix", &Cursor{request: readSegment{kind: prefixSegment, prefixes: [][]byte{[]byte("ba")}}}, []byte("ba"), []byte("bb")},
+		{"batch prefix", &Cursor{request: readSegment{kind: batchPrefixSegment, prefixes: [][]byte{[]byte("c"), []byte("a1")}}}, []byte("a1"), []byte("d")},
+		{"unbounded prefix", &Cursor{request: readSegment{kind: batchPrefixSegment, prefixes: [][]byte{[]byte("a"), {0xFF}}}}, []byte("a"), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end := test.cursor.KeyRange()
+			assert.Equal(t, test.expectStart, start)
+			assert.Equal(t, test.expectEnd, end)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// KeyDeleter is implemented by store drivers able to delete a single key without building
+// a batch. Use the `Delete` function which degrades to a one key `BatchDelete` for the
+// other drivers.
+type KeyDeleter interface {
+	// Delete a given key, deleting a key that does not exist is not an error.
+	Delete(ctx context.Context, key []byte) error
+}
+
+// Delete deletes the key, using the store's `KeyDeleter` implementation when available,
+// a one key `BatchDelete` otherwise. Deleting a key that does not exist is not an error.
+func Delete(ctx context.Context, s KVStore, key []byte) error {
+	if deleter, ok := s.(KeyDeleter); ok {
+		return deleter.Delete(ctx, key)
+	}
+
+	return s.BatchDelete(ctx, [][]byte{key})
+}
+
+// DeleteRangeMaxBatchSize is the number of keys deleted per `BatchDelete` call by the
+// `DeleteRange` and `DeletePrefix` fallback of drivers not implementing `RangeDeleter`.
+var DeleteRangeMaxBatchSize = 500
+
+// RangeDeleter is implemented by store drivers able to delete a range of keys without
+// reading them first. Use `DeleteRange` and `DeletePrefix` functions which degrade to
+// reading and deleting keys in batches for the other drivers.
+//
+// Like `BatchDelete`, puts not yet flushed are not affected.
+type RangeDeleter interface {
+	// DeleteRange deletes the keys `Scan(ctx, start, exclusiveEnd, Unlimited)` would return,
+	// so an empty `exclusiveEnd` deletes nothing.
+	DeleteRange(ctx context.Context, start, exclusiveEnd []byte) error
+	// DeletePrefix deletes the keys `Prefix(ctx, prefix, Unlimited)` would return.
+	DeletePrefix(ctx context.Context, prefix []byte) error
+}
+
+// DeleteRange deletes the keys in [start, exclusiveEnd), using the store's `RangeDeleter`
+// implementation when available, a key-only scan deleting keys in batches otherwise.
+func DeleteRange(ctx context.Context, s KVStore, start, exclusiveEnd []byte) error {
+	if deleter, ok := s.(RangeDeleter); ok {
+		return deleter.DeleteRange(ctx, start, exclusiveEnd)
+	}
+
+	return deleteKeys(ctx, s, s.Scan(ctx, start, exclusiveEnd, Unlimited, KeyOnly()))
+}
+
+// DeletePrefix deletes the keys starting with `prefix`, using the store's `RangeDeleter`
+// implementation when available, a key-only prefix read deleting keys in batches otherwise.
+func DeletePrefix(ctx context.Context, s KVStore, prefix []byte) error {
+	if deleter, ok := s.(RangeDeleter); ok {
+		return deleter.DeletePrefix(ctx, prefix)
+	}
+
+	return deleteKeys(ctx, s, s.Prefix(ctx, prefix, Unlimited, KeyOnly()))
+}
+
+func deleteKeys(ctx context.Context, s KVStore, it *Iterator) error {
+	var keys [][]byte
+	for it.Next() {
+		keys = append(keys, it.Item().Key)
+		if len(keys) >= DeleteRangeMaxBatchSize {
+			if err := s.BatchDelete(ctx, keys); err != nil {
+				return fmt.Errorf("unable to delete batch: %w", err)
+			}
+			keys = nil
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if len(keys) > 0 {
+		if err := s.BatchDelete(ctx, keys); err != nil {
+			return fmt.Errorf("unable to delete batch: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type batchDeleteCountingStore struct {
+	*memoryKVStore
+	batchDeletes int
+}
+
+func (s *batchDeleteCountingStore) BatchDelete(ctx context.Context, keys [][]byte) error {
+	s.batchDeletes++
+	return s.memoryKVStore.BatchDelete(ctx, keys)
+}
+
+func TestDelete_Fallback(t *testing.T) {
+	s := &batchDeleteCountingStore{memoryKVStore: newTestCursorStore()}
+
+	require.NoError(t, Delete(context.Background(), s, []byte("a")))
+	require.NoError(t, Delete(context.Background(), s, []byte("not-found")), "deleting a missing key is not an error")
+	assert.Equal(t, 2, s.batchDeletes)
+
+	_, err := s.Get(context.Background(), []byte("a"))
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestDeleteRange_Fallback(t *testing.T) {
+	defer func(previous int) { DeleteRangeMaxBatchSize = previous }(DeleteRangeMaxBatchSize)
+	DeleteRangeMaxBatchSize = 2
+
+	s := &batchDeleteCountingStore{memoryKVStore: newTestCursorStore()}
+
+	require.NoError(t, DeleteRange(context.Background(), s, []byte("b"), []byte("c")))
+	assert.Equal(t, 2, s.batchDeletes)
+
+	keys, err := testCollectKeys(t, s.Prefix(context.Background(), nil, Unlimited))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, keys)
+
+	require.NoError(t, DeleteRange(context.Background(), s, []byte("a"), nil))
+	assert.Equal(t, 2, s.batchDeletes, "empty end deletes nothing")
+}
+
+func TestDeletePrefix_Fallback(t *testing.T) {
+	s := newTestCursorStore()
+
+	require.NoError(t, DeletePrefix(context.Background(), s, []byte("ba")))
+
+	keys, err := testCollectKeys(t, s.Prefix(context.Background(), nil, Unlimited))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "bb", "c"}, keys)
+}
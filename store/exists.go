@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"errors"
+)
+
+// ExistenceChecker is implemented by store drivers able to check the presence of keys
+// without transferring their value. Use `Exists` and `BatchExists` functions which degrade
+// to reading the values for the other drivers.
+type ExistenceChecker interface {
+	// Exists returns whether a given key exists.
+	Exists(ctx context.Context, key []byte) (exists bool, err error)
+	// BatchExists returns whether each key exists, in the same order as keys.
+	BatchExists(ctx context.Context, keys [][]byte) (exists []bool, err error)
+}
+
+// Exists returns whether the key exists, using the store's `ExistenceChecker`
+// implementation when available, a `Get` of the key otherwise.
+func Exists(ctx context.Context, s KVStore, key []byte) (bool, error) {
+	if checker, ok := s.(ExistenceChecker); ok {
+		return checker.Exists(ctx, key)
+	}
+
+	_, err := s.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// BatchExists returns whether each key exists, in the same order as keys, using the
+// store's `ExistenceChecker` implementation when available, a `Get` of each key otherwise.
+func BatchExists(ctx context.Context, s KVStore, keys [][]byte) ([]bool, error) {
+	if checker, ok := s.(ExistenceChecker); ok {
+		return checker.BatchExists(ctx, keys)
+	}
+
+	out := make([]bool, len(keys))
+	for i, key := range keys {
+		exists, err := Exists(ctx, s, key)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = exists
+	}
+
+	return out, nil
+}
@@ -0,0 +1,25 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExists_Fallback(t *testing.T) {
+	s := newTestCursorStore()
+
+	exists, err := Exists(context.Background(), s, []byte("ba"))
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = Exists(context.Background(), s, []byte("b"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	batchExists, err := BatchExists(context.Background(), s, [][]byte{[]byte("c"), []byte("z"), []byte("a")})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true}, batchExists)
+}
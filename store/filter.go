@@ -0,0 +1,76 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+// ReadFilter applies the filtering read options (`WithKeyRegex`, `WithValuePrefix` and
+// `WithValueSizeRange`) to items, it's used by drivers for the filters their backend
+// cannot apply. A nil ReadFilter matches all items.
+type ReadFilter struct {
+	keyRegex     *regexp.Regexp
+	valuePrefix  []byte
+	minValueSize int
+	maxValueSize int
+}
+
+// NewReadFilter returns the filter of the read options, nil if they have no filtering
+// option.
+func NewReadFilter(options *ReadOptions) (*ReadFilter, error) {
+	if !options.HasFilter() {
+		return nil, nil
+	}
+
+	filter := &ReadFilter{
+		valuePrefix:  options.ValuePrefix,
+		minValueSize: options.MinValueSize,
+		maxValueSize: options.MaxValueSize,
+	}
+
+	if options.KeyRegex != "" {
+		var err error
+		if filter.keyRegex, err = regexp.Compile("^(?:" + options.KeyRegex + ")$"); err != nil {
+			return nil, fmt.Errorf("invalid key regex %q: %w", options.KeyRegex, err)
+		}
+	}
+
+	return filter, nil
+}
+
+// HasFilter returns true if any filtering option is set.
+func (o *ReadOptions) HasFilter() bool {
+	return o != nil && (o.KeyRegex != "" || len(o.ValuePrefix) > 0 || o.MinValueSize > 0 || o.MaxValueSize > 0)
+}
+
+// NeedsValue returns true if the value of items is required to apply the filter,
+// drivers must then read values even for key-only reads.
+func (f *ReadFilter) NeedsValue() bool {
+	return f != nil && (len(f.valuePrefix) > 0 || f.minValueSize > 0 || f.maxValueSize > 0)
+}
+
+// Match returns true if the item passes the filter.
+func (f *ReadFilter) Match(key, value []byte) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.keyRegex != nil && !f.keyRegex.Match(key) {
+		return false
+	}
+
+	if len(f.valuePrefix) > 0 && !bytes.HasPrefix(value, f.valuePrefix) {
+		return false
+	}
+
+	return f.matchValueSize(value)
+}
+
+func (f *ReadFilter) matchValueSize(value []byte) bool {
+	if len(value) < f.minValueSize {
+		return false
+	}
+
+	return f.maxValueSize <= 0 || len(value) <= f.maxValueSize
+}
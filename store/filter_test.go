@@ -0,0 +1,56 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		options    []ReadOption
+		key, value string
+		expected   bool
+	}{
+		{"no filter", nil, "a", "1", true},
+		{"key regex matches", []ReadOption{WithKeyRegex("a[0-9]+")}, "a12", "", true},
+		{"key regex is anchored", []ReadOption{WithKeyRegex("a[0-9]+")}, "ba12", "", false},
+		{"key regex is anchored at end", []ReadOption{WithKeyRegex("a[0-9]")}, "a12", "", false},
+		{"key regex alternation is anchored", []ReadOption{WithKeyRegex("a|b")}, "ab", "", false},
+		{"value prefix matches", []ReadOption{WithValuePrefix([]byte("v1"))}, "a", "v12", true},
+		{"value prefix does not match", []ReadOption{WithValuePrefix([]byte("v1"))}, "a", "v2", false},
+		{"value size within", []ReadOption{WithValueSizeRange(2, 3)}, "a", "123", true},
+		{"value size too small", []ReadOption{WithValueSizeRange(2, 3)}, "a", "1", false},
+		{"value size too big", []ReadOption{WithValueSizeRange(2, 3)}, "a", "1234", false},
+		{"value size no max", []ReadOption{WithValueSizeRange(2, 0)}, "a", "12345", true},
+		{"all filters", []ReadOption{WithKeyRegex("a.*"), WithValuePrefix([]byte("v")), WithValueSizeRange(0, 2)}, "ab", "v1", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := NewReadFilter(NewReadOptions(test.options...))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, filter.Match([]byte(test.key), []byte(test.value)))
+		})
+	}
+}
+
+func TestNewReadFilter(t *testing.T) {
+	filter, err := NewReadFilter(NewReadOptions(KeyOnly()))
+	require.NoError(t, err)
+	assert.Nil(t, filter)
+	assert.False(t, filter.NeedsValue())
+
+	filter, err = NewReadFilter(NewReadOptions(WithKeyRegex("a")))
+	require.NoError(t, err)
+	assert.False(t, filter.NeedsValue())
+
+	filter, err = NewReadFilter(NewReadOptions(WithValueSizeRange(1, 0)))
+	require.NoError(t, err)
+	assert.True(t, filter.NeedsValue())
+
+	_, err = NewReadFilter(NewReadOptions(WithKeyRegex("a(")))
+	assert.Error(t, err)
+}
@@ -29,9 +29,15 @@ type Iterator struct {
 	lastItem   KV
 	err        error
 	nextCalled bool
+	done       bool
 	once       sync.Once
+
+	bytes  *byteBudget
+	cursor *iteratorCursor
 }
 
+const defaultIteratorBufferSize = 100
+
 // NewIterator provides a streaming resultset for key/value queries. The correct pattern
 // iteration pattern is:
 //
@@ -43,12 +49,33 @@ type Iterator struct {
 //	if err := it.Err(); err != nil {
 //	    // handle error
 //	}
-func NewIterator(ctx context.Context) *Iterator {
-	return &Iterator{
+//
+// See `Iterator.All`, `Collect` and `ForEach` for helpers implementing this pattern.
+//
+// The iterator buffers at most 100 items ahead of its consumer, use `WithBufferSize`
+// and `WithBufferBytes` read options to change it.
+func NewIterator(ctx context.Context, options ...ReadOption) *Iterator {
+	readOptions := ReadOptions{}
+	for _, opt := range options {
+		opt.Apply(&readOptions)
+	}
+
+	bufferSize := defaultIteratorBufferSize
+	if readOptions.BufferSize > 0 {
+		bufferSize = readOptions.BufferSize
+	}
+
+	it := &Iterator{
 		ctx:     ctx,
-		items:   make(chan KV, 100),
+		items:   make(chan KV, bufferSize),
 		errorCh: make(chan error, 1),
 	}
+
+	if readOptions.BufferBytes > 0 {
+		it.bytes = newByteBudget(uint64(readOptions.BufferBytes))
+	}
+
+	return it
 }
 
 //
@@ -69,15 +96,25 @@ func (it *Iterator) Next() bool {
 	select {
 	case val, ok := <-it.items:
 		if !ok {
+			it.done = true
 			return false
 		}
 		it.lastItem = val
+		if it.bytes != nil {
+			it.bytes.release(uint64(val.Size()))
+		}
 
 	case err := <-it.errorCh:
 		it.err = err
+		it.done = true
 		return false
 	}
 
+	if it.cursor != nil {
+		it.cursor.lastKey = it.lastItem.Key
+		it.cursor.count++
+	}
+
 	return true
 }
 
@@ -116,8 +153,31 @@ func (it *Iterator) Err() error {
 	return it.err
 }
 
+// Cursor returns a cursor that can be used with `ReadFromCursor` to continue the read
+// right after the last item returned by this iterator, see `Cursor` for details.
+//
+// It returns nil if the iterator was not created by a `Scan`, `Prefix` or `BatchPrefix`
+// call of a driver supporting cursors or if the iteration completed without error
+// before reaching its limit, in which case there is nothing left to read.
+func (it *Iterator) Cursor() *Cursor {
+	if it.cursor == nil {
+		return nil
+	}
+
+	if it.done && it.err == nil && !it.cursor.limit.Reached(it.cursor.count) {
+		return nil
+	}
+
+	return &Cursor{request: it.cursor.request, lastKey: it.cursor.lastKey}
+}
+
 // Results gathering primitives
 func (it *Iterator) PushItem(res KV) bool {
+	if it.bytes != nil && !it.bytes.acquire(it.ctx, uint64(res.Size())) {
+		it.PushError(it.ctx.Err())
+		return false
+	}
+
 	select {
 	case <-it.ctx.Done():
 		it.PushError(it.ctx.Err())
@@ -139,3 +199,45 @@ func (it *Iterator) PushError(err error) {
 		close(it.errorCh)
 	})
 }
+
+// byteBudget bounds the total size of the items buffered by an iterator, the single
+// producer waits in `acquire` until the consumer `release`s enough bytes.
+type byteBudget struct {
+	lock  sync.Mutex
+	max   uint64
+	used  uint64
+	freed chan struct{}
+}
+
+func newByteBudget(max uint64) *byteBudget {
+	return &byteBudget{max: max, freed: make(chan struct{}, 1)}
+}
+
+func (b *byteBudget) acquire(ctx context.Context, size uint64) bool {
+	for {
+		b.lock.Lock()
+		if b.used == 0 || b.used+size <= b.max {
+			b.used += size
+			b.lock.Unlock()
+			return true
+		}
+		b.lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-b.freed:
+		}
+	}
+}
+
+func (b *byteBudget) release(size uint64) {
+	b.lock.Lock()
+	b.used -= size
+	b.lock.Unlock()
+
+	select {
+	case b.freed <- struct{}{}:
+	default:
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -46,3 +47,52 @@ func TestErrorExit(t *testing.T) {
 	assert.False(t, it.Next())
 	assert.Equal(t, deadErr, it.Err())
 }
+
+func TestIteratorBufferSize(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	it := NewIterator(ctx, WithBufferSize(2))
+
+	require.True(t, it.PushItem(KV{}))
+	require.True(t, it.PushItem(KV{}))
+
+	cancel()
+	assert.False(t, it.PushItem(KV{}))
+}
+
+func TestIteratorBufferBytes(t *testing.T) {
+	it := NewIterator(context.Background(), WithBufferBytes(10))
+
+	// An item bigger than the limit is accepted when the buffer is empty
+	require.True(t, it.PushItem(KV{Key: []byte("k"), Value: make([]byte, 20)}))
+
+	pushed := make(chan bool)
+	go func() {
+		pushed <- it.PushItem(KV{Key: []byte("k"), Value: []byte("12345")})
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("push should wait until buffered bytes are consumed")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	require.True(t, it.Next())
+	assert.True(t, <-pushed)
+
+	require.True(t, it.PushItem(KV{Key: []byte("k"), Value: []byte("123")}))
+	it.PushFinished()
+
+	require.True(t, it.Next())
+	require.True(t, it.Next())
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+}
+
+func TestIteratorBufferBytes_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	it := NewIterator(ctx, WithBufferBytes(1))
+	require.True(t, it.PushItem(KV{Key: []byte("k")}))
+
+	cancel()
+	assert.False(t, it.PushItem(KV{Key: []byte("k")}))
+}
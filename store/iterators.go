@@ -0,0 +1,159 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"iter"
+)
+
+// All returns a Go iterator over the items of the iterator, usable with `range`:
+//
+//	for kv, err := range store.Prefix(ctx, prefix, limit).All() {
+//	    if err != nil {
+//	        // handle error
+//	    }
+//	    // do something with kv
+//	}
+//
+// The error of the iterator, if any, is yielded last with an empty KV. Breaking out of
+// the loop early does not stop the read, cancel the context given to the store for that.
+func (it *Iterator) All() iter.Seq2[KV, error] {
+	return func(yield func(KV, error) bool) {
+		for it.Next() {
+			if !yield(it.Item(), nil) {
+				return
+			}
+		}
+
+		if err := it.Err(); err != nil {
+			yield(KV{}, err)
+		}
+	}
+}
+
+// Collect reads all the items of the iterator, returning them along the error that
+// stopped it, if any.
+func Collect(it *Iterator) (out []KV, err error) {
+	for it.Next() {
+		out = append(out, it.Item())
+	}
+
+	return out, it.Err()
+}
+
+// ForEach calls `fn` for each item of the iterator, stopping at the first error
+// returned by `fn` or by the iterator. Stopping early does not stop the read, cancel
+// the context given to the store for that.
+func ForEach(it *Iterator, fn func(kv KV) error) error {
+	for it.Next() {
+		if err := fn(it.Item()); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// Map returns a Go iterator over the items of the iterator converted by `fn`, the first
+// error returned by `fn` or by the iterator is yielded last.
+func Map[T any](it *Iterator, fn func(kv KV) (T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		for kv, err := range it.All() {
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			value, err := fn(kv)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			if !yield(value, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Filter returns an iterator over the items of the iterator for which `keep` returns true.
+func Filter(it *Iterator, keep func(kv KV) bool) *Iterator {
+	out := NewIterator(it.ctx)
+
+	go func() {
+		for it.Next() {
+			if keep(it.Item()) && !out.PushItem(it.Item()) {
+				return
+			}
+		}
+
+		if err := it.Err(); err != nil {
+			out.PushError(err)
+			return
+		}
+		out.PushFinished()
+	}()
+
+	return out
+}
+
+// Merge returns an iterator over the items of all the iterators in key order, each
+// iterator must itself be sorted by key. When the same key is emitted by more than
+// one iterator, only the item of the first one, in argument order, is kept.
+//
+// The merge stops at the first error of any of the iterators.
+func Merge(ctx context.Context, its ...*Iterator) *Iterator {
+	out := NewIterator(ctx)
+
+	go func() {
+		heads := make([]*KV, len(its))
+		advance := func(i int) error {
+			heads[i] = nil
+			if its[i].Next() {
+				item := its[i].Item()
+				heads[i] = &item
+				return nil
+			}
+			return its[i].Err()
+		}
+
+		for i := range its {
+			if err := advance(i); err != nil {
+				out.PushError(err)
+				return
+			}
+		}
+
+		for {
+			lowest := -1
+			for i, head := range heads {
+				if head != nil && (lowest == -1 || bytes.Compare(head.Key, heads[lowest].Key) < 0) {
+					lowest = i
+				}
+			}
+
+			if lowest == -1 {
+				out.PushFinished()
+				return
+			}
+
+			if !out.PushItem(*heads[lowest]) {
+				return
+			}
+
+			key := heads[lowest].Key
+			for i, head := range heads {
+				if head != nil && (i == lowest || bytes.Equal(head.Key, key)) {
+					if err := advance(i); err != nil {
+						out.PushError(err)
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testIterator(kvs []KV, err error) *Iterator {
+	return (&memoryKVStore{}).iterator(NewIterator(context.Background()), kvs, err)
+}
+
+func testKVs(keys ...string) (out []KV) {
+	for _, key := range keys {
+		out = append(out, KV{Key: []byte(key), Value: []byte("v" + key)})
+	}
+	return
+}
+
+func TestIterator_All(t *testing.T) {
+	var keys []string
+	for kv, err := range testIterator(testKVs("a", "b"), nil).All() {
+		require.NoError(t, err)
+		keys = append(keys, string(kv.Key))
+	}
+	assert.Equal(t, []string{"a", "b"}, keys)
+
+	failure := errors.New("failure")
+	var errs []error
+	for _, err := range testIterator(testKVs("a"), failure).All() {
+		errs = append(errs, err)
+	}
+	assert.Equal(t, []error{nil, failure}, errs)
+}
+
+func TestCollect(t *testing.T) {
+	kvs, err := Collect(testIterator(testKVs("a", "b"), nil))
+	require.NoError(t, err)
+	assert.Equal(t, testKVs("a", "b"), kvs)
+
+	failure := errors.New("failure")
+	kvs, err = Collect(testIterator(testKVs("a"), failure))
+	assert.Equal(t, failure, err)
+	assert.Equal(t, testKVs("a"), kvs)
+}
+
+func TestForEach(t *testing.T) {
+	var keys []string
+	err := ForEach(testIterator(testKVs("a", "b", "c"), nil), func(kv KV) error {
+		keys = append(keys, string(kv.Key))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+
+	stop := errors.New("stop")
+	keys = nil
+	err = ForEach(testIterator(testKVs("a", "b", "c"), nil), func(kv KV) error {
+		keys = append(keys, string(kv.Key))
+		if string(kv.Key) == "b" {
+			return stop
+		}
+		return nil
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, []string{"a", "b"}, keys)
+}
+
+func TestMap(t *testing.T) {
+	kvs := []KV{{Key: []byte("a"), Value: []byte("1")}, {Key: []byte("b"), Value: []byte("2")}, {Key: []byte("c"), Value: []byte("x")}}
+
+	var values []int
+	var errs []error
+	for value, err := range Map(testIterator(kvs, nil), func(kv KV) (int, error) { return strconv.Atoi(string(kv.Value)) }) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		values = append(values, value)
+	}
+
+	assert.Equal(t, []int{1, 2}, values)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], strconv.ErrSyntax)
+}
+
+func TestFilter(t *testing.T) {
+	kvs, err := Collect(Filter(testIterator(testKVs("a", "bb", "c", "dd"), nil), func(kv KV) bool { return len(kv.Key) == 2 }))
+	require.NoError(t, err)
+	assert.Equal(t, testKVs("bb", "dd"), kvs)
+
+	failure := errors.New("failure")
+	_, err = Collect(Filter(testIterator(testKVs("a"), failure), func(kv KV) bool { return true }))
+	assert.Equal(t, failure, err)
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name        string
+		its         []*Iterator
+		expected    []KV
+		expectedErr error
+	}{
+		{
+			name:     "none",
+			expected: nil,
+		},
+		{
+			name:     "interleaved",
+			its:      []*Iterator{testIterator(testKVs("a", "c", "e"), nil), testIterator(testKVs("b", "d"), nil), testIterator(nil, nil)},
+			expected: testKVs("a", "b", "c", "d", "e"),
+		},
+		{
+			name: "first iterator wins on same key",
+			its: []*Iterator{
+				testIterator([]KV{{Key: []byte("b"), Value: []byte("first")}}, nil),
+				testIterator(testKVs("a", "b", "c"), nil),
+			},
+			expected: []KV{testKVs("a")[0], {Key: []byte("b"), Value: []byte("first")}, testKVs("c")[0]},
+		},
+		{
+			name:        "error",
+			its:         []*Iterator{testIterator(testKVs("a", "c"), nil), testIterator(testKVs("b"), errors.New("failure"))},
+			expectedErr: errors.New("failure"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kvs, err := Collect(Merge(context.Background(), test.its...))
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, kvs)
+		})
+	}
+}
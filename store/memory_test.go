@@ -0,0 +1,143 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"sort"
+)
+
+// memoryKVStore is a minimal, sorted in-memory KVStore used to test wrappers and helpers
+// of this package without depending on an actual driver.
+type memoryKVStore struct {
+	kvs []KV
+}
+
+func newMemoryKVStore(kvs ...KV) *memoryKVStore {
+	s := &memoryKVStore{}
+	for _, kv := range kvs {
+		s.set(kv.Key, kv.Value)
+	}
+	return s
+}
+
+func (s *memoryKVStore) set(key, value []byte) {
+	i := sort.Search(len(s.kvs), func(i int) bool { return bytes.Compare(s.kvs[i].Key, key) >= 0 })
+	if i < len(s.kvs) && bytes.Equal(s.kvs[i].Key, key) {
+		s.kvs[i].Value = value
+		return
+	}
+
+	s.kvs = append(s.kvs, KV{})
+	copy(s.kvs[i+1:], s.kvs[i:])
+	s.kvs[i] = KV{Key: key, Value: value}
+}
+
+func (s *memoryKVStore) Put(ctx context.Context, key, value []byte) error {
+	s.set(key, value)
+	return nil
+}
+
+func (s *memoryKVStore) FlushPuts(ctx context.Context) error {
+	return nil
+}
+
+func (s *memoryKVStore) Get(ctx context.Context, key []byte) ([]byte, error) {
+	for _, kv := range s.kvs {
+		if bytes.Equal(kv.Key, key) {
+			return kv.Value, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *memoryKVStore) BatchGet(ctx context.Context, keys [][]byte) *Iterator {
+	var out []KV
+	for _, key := range keys {
+		value, err := s.Get(ctx, key)
+		if err != nil {
+			return s.iterator(NewIterator(ctx), out, err)
+		}
+		out = append(out, KV{Key: key, Value: value})
+	}
+	return s.iterator(NewIterator(ctx), out, nil)
+}
+
+func (s *memoryKVStore) Scan(ctx context.Context, start, exclusiveEnd []byte, limit int, options ...ReadOption) *Iterator {
+	return s.collect(NewScanIterator(ctx, start, exclusiveEnd, limit, options), Limit(limit), options, func(key []byte) bool {
+		return bytes.Compare(key, start) >= 0 && bytes.Compare(key, exclusiveEnd) < 0
+	})
+}
+
+func (s *memoryKVStore) Prefix(ctx context.Context, prefix []byte, limit int, options ...ReadOption) *Iterator {
+	return s.collect(NewPrefixIterator(ctx, prefix, limit, options), Limit(limit), options, func(key []byte) bool {
+		return bytes.HasPrefix(key, prefix)
+	})
+}
+
+func (s *memoryKVStore) BatchPrefix(ctx context.Context, prefixes [][]byte, limit int, options ...ReadOption) *Iterator {
+	var out []KV
+	for _, prefix := range prefixes {
+		for _, kv := range s.kvs {
+			if Limit(limit).Reached(uint64(len(out))) {
+				break
+			}
+			if bytes.HasPrefix(kv.Key, prefix) {
+				out = append(out, kv)
+			}
+		}
+	}
+	return s.iterator(NewBatchPrefixIterator(ctx, prefixes, limit, options), out, nil)
+}
+
+func (s *memoryKVStore) BatchDelete(ctx context.Context, keys [][]byte) error {
+	for _, key := range keys {
+		for i, kv := range s.kvs {
+			if bytes.Equal(kv.Key, key) {
+				s.kvs = append(s.kvs[:i], s.kvs[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (s *memoryKVStore) Close() error {
+	return nil
+}
+
+func (s *memoryKVStore) collect(it *Iterator, limit Limit, options []ReadOption, match func(key []byte) bool) *Iterator {
+	readOptions := ReadOptions{}
+	for _, opt := range options {
+		opt.Apply(&readOptions)
+	}
+
+	var out []KV
+	for _, kv := range s.kvs {
+		if limit.Reached(uint64(len(out))) {
+			break
+		}
+		if match(kv.Key) {
+			if readOptions.KeyOnly {
+				kv.Value = nil
+			}
+			out = append(out, kv)
+		}
+	}
+	return s.iterator(it, out, nil)
+}
+
+func (s *memoryKVStore) iterator(it *Iterator, kvs []KV, err error) *Iterator {
+	go func() {
+		for _, kv := range kvs {
+			if !it.PushItem(kv) {
+				return
+			}
+		}
+		if err != nil {
+			it.PushError(err)
+			return
+		}
+		it.PushFinished()
+	}()
+	return it
+}
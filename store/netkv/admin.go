@@ -0,0 +1,73 @@
+package netkv
+
+import (
+	"context"
+	"io"
+
+	"github.com/streamingfast/kvdb/store"
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Stats returns the statistics of the server and of the stores the client can access,
+// counting their keys when `countKeys` is set.
+func (s *Store) Stats(ctx context.Context, countKeys bool) (*pbnetkv.StatsResponse, error) {
+	return pbnetkv.NewNetKVAdminClient(s.conn).Stats(ctx, &pbnetkv.StatsRequest{CountKeys: countKeys})
+}
+
+// Compact implements `store.Compactor`, returning `store.ErrNotSupported` when the
+// store served by the server cannot compact on demand.
+func (s *Store) Compact(ctx context.Context) error {
+	_, err := pbnetkv.NewNetKVAdminClient(s.conn).Compact(ctx, &pbnetkv.CompactRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		return store.ErrNotSupported
+	}
+
+	return err
+}
+
+// FlushServer flushes the puts buffered by the client, then asks the server to write
+// the puts its store buffers to the backend.
+func (s *Store) FlushServer(ctx context.Context) error {
+	if err := s.FlushPuts(ctx); err != nil {
+		return err
+	}
+
+	_, err := pbnetkv.NewNetKVAdminClient(s.conn).Flush(ctx, &pbnetkv.FlushRequest{})
+	return err
+}
+
+// Backup reads all the keys of the store, restoring them is a matter of putting them
+// in an empty store.
+func (s *Store) Backup(ctx context.Context) *store.Iterator {
+	it := store.NewIterator(ctx)
+
+	go func() {
+		resp, err := pbnetkv.NewNetKVAdminClient(s.conn).Backup(ctx, &pbnetkv.BackupRequest{})
+		if err != nil {
+			it.PushError(err)
+			return
+		}
+
+		for {
+			batch, err := resp.Recv()
+			if err == io.EOF {
+				it.PushFinished()
+				return
+			}
+			if err != nil {
+				it.PushError(err)
+				return
+			}
+
+			for _, kv := range batch.Kvs {
+				if !it.PushItem(store.KV{Key: kv.Key, Value: kv.Value}) {
+					return
+				}
+			}
+		}
+	}()
+
+	return it
+}
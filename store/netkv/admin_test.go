@@ -0,0 +1,94 @@
+package netkv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/streamingfast/kvdb/store"
+	netkvserver "github.com/streamingfast/kvdb/store/netkv/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdmin(t *testing.T) {
+	server := launchTestServer(t, netkvserver.WithTokenVerifier(netkvserver.StaticTokens{
+		"admin":  {Name: "admin", Permissions: netkvserver.PermissionRead | netkvserver.PermissionWrite | netkvserver.PermissionAdmin},
+		"reader": {Name: "reader", Permissions: netkvserver.PermissionRead},
+	}))
+
+	open := func(token string) *Store {
+		return openClient(t, server, "insecure=true&token="+token).(*Store)
+	}
+
+	ctx := context.Background()
+	admin, reader := open("admin"), open("reader")
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, admin.Put(ctx, []byte(key), []byte("value")))
+	}
+	require.NoError(t, admin.FlushServer(ctx))
+	require.NoError(t, store.Delete(ctx, admin, []byte("b")))
+
+	require.NoError(t, store.Compact(ctx, admin))
+
+	stats, err := admin.Stats(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, stats.Stores, 1)
+	assert.Equal(t, "default", stats.Stores[0].Name)
+	assert.True(t, stats.Stores[0].KeyCountKnown)
+	assert.Equal(t, uint64(2), stats.Stores[0].KeyCount)
+	assert.Equal(t, uint64(1), stats.ActiveCalls, "only the stats call itself")
+	assert.False(t, stats.Draining)
+
+	kvs, err := store.Collect(admin.Backup(ctx))
+	require.NoError(t, err)
+	assert.Equal(t, []store.KV{{Key: []byte("a"), Value: []byte("value")}, {Key: []byte("c"), Value: []byte("value")}}, kvs)
+
+	_, err = reader.Stats(ctx, false)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = store.Collect(reader.Backup(ctx))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	stores, err := reader.ListStores(ctx)
+	require.NoError(t, err)
+	assert.Len(t, stores, 1, "listing stores requires no permission")
+}
+
+func TestHealthAndDrain(t *testing.T) {
+	server := launchTestServer(t, netkvserver.WithTokenVerifier(netkvserver.StaticTokens{
+		"secret": {Name: "writer", Permissions: netkvserver.PermissionRead | netkvserver.PermissionWrite},
+	}))
+
+	conn, err := grpc.Dial(server.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := context.Background()
+	health := healthpb.NewHealthClient(conn)
+
+	resp, err := health.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err, "health checks require no token")
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	kvStore := openClient(t, server, "insecure=true&token=secret")
+
+	require.NoError(t, kvStore.Put(ctx, []byte("a"), []byte("value")))
+	require.NoError(t, kvStore.FlushPuts(ctx))
+
+	drainCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	require.NoError(t, server.Drain(drainCtx))
+
+	resp, err = health.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	_, err = kvStore.Get(ctx, []byte("a"))
+	assert.Equal(t, codes.Unavailable, status.Code(err), "new calls are rejected")
+}
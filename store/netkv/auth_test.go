@@ -0,0 +1,139 @@
+package netkv
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/streamingfast/kvdb/store"
+	netkvserver "github.com/streamingfast/kvdb/store/netkv/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type testCertificate struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+// newTestCertificate creates a certificate signed by `parent`, self-signed when nil,
+// and writes it along its key as PEM files in `dir`.
+func newTestCertificate(t *testing.T, dir, name string, parent *testCertificate) *testCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer := &testCertificate{certificate: template, key: key}
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+	} else {
+		signer = parent
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer.certificate, &key.PublicKey, signer.key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	return &testCertificate{certificate: certificate, key: key}
+}
+
+func TestTLSAndTokens(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCertificate(t, dir, "ca", nil)
+	newTestCertificate(t, dir, "server", ca)
+	newTestCertificate(t, dir, "client", ca)
+
+	file := func(name string) string { return filepath.Join(dir, name) }
+
+	tlsConfig, err := netkvserver.NewServerTLSConfig(file("server.crt"), file("server.key"), file("ca.crt"))
+	require.NoError(t, err)
+
+	server := launchTestServer(t,
+		netkvserver.WithTLS(tlsConfig),
+		netkvserver.WithTokenVerifier(netkvserver.StaticTokens{
+			"writer": {Name: "writer", Permissions: netkvserver.PermissionRead | netkvserver.PermissionWrite, Prefixes: [][]byte{[]byte("a")}},
+			"reader": {Name: "reader", Permissions: netkvserver.PermissionRead},
+		}),
+	)
+
+	require.NoError(t, os.WriteFile(file("reader.token"), []byte("reader\n"), 0600))
+
+	mTLS := fmt.Sprintf("tls_ca_file=%s&tls_cert_file=%s&tls_key_file=%s", file("ca.crt"), file("client.crt"), file("client.key"))
+	open := func(query string) store.KVStore { return openClient(t, server, query) }
+
+	ctx := context.Background()
+	writer := open(mTLS + "&token=writer")
+	assert.NotContains(t, fmt.Sprint(writer), "token=writer", "token is redacted")
+
+	require.NoError(t, writer.Put(ctx, []byte("a1"), []byte("1")))
+	require.NoError(t, writer.FlushPuts(ctx))
+
+	require.NoError(t, writer.Put(ctx, []byte("b1"), []byte("1")))
+	assert.Equal(t, codes.PermissionDenied, status.Code(writer.FlushPuts(ctx)), "key outside of token prefixes")
+
+	_, err = store.Collect(writer.Scan(ctx, []byte("a"), []byte("c"), store.Unlimited))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "range outside of token prefixes")
+
+	kvs, err := store.Collect(writer.Prefix(ctx, []byte("a"), store.Unlimited))
+	require.NoError(t, err)
+	assert.Len(t, kvs, 1)
+
+	reader := open(mTLS + "&token_file=" + file("reader.token"))
+	value, err := reader.Get(ctx, []byte("a1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+	assert.Equal(t, codes.PermissionDenied, status.Code(store.Delete(ctx, reader, []byte("a1"))), "read only token")
+
+	_, err = open(mTLS).Get(ctx, []byte("a1"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "missing token")
+
+	_, err = open(mTLS+"&token=unknown").Get(ctx, []byte("a1"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "unknown token")
+
+	_, err = open(fmt.Sprintf("tls_ca_file=%s&token=reader", file("ca.crt"))).Get(ctx, []byte("a1"))
+	assert.Equal(t, codes.Unavailable, status.Code(err), "client certificate required")
+}
+
+func TestCredentialsDialOptions_Invalid(t *testing.T) {
+	for _, dsn := range []string{
+		"netkv://localhost:1234?insecure=true&tls_ca_file=ca.crt",
+		"netkv://localhost:1234?tls_cert_file=client.crt",
+		"netkv://localhost:1234?token=a&token_file=b",
+		"netkv://localhost:1234?tls_ca_file=missing.crt",
+	} {
+		_, err := store.New(dsn)
+		assert.Error(t, err, dsn)
+	}
+}
@@ -0,0 +1,135 @@
+package netkv
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/streamingfast/kvdb/store"
+	"github.com/streamingfast/kvdb/store/netkv/encoding/zstd"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+const (
+	defaultMaxMsgBytes = 100 * 1024 * 1024
+
+	// minKeepaliveTime is the shortest keepalive interval netkv servers accept, clients
+	// pinging more often get disconnected
+	minKeepaliveTime = 10 * time.Second
+)
+
+var connectionOptionSchemas = store.OptionSchemas{
+	{Name: "load_balancing", Type: store.OptionTypeString, Default: "round_robin", Values: []string{"round_robin", "failover"}, Description: "How calls are spread across the comma separated endpoints of the DSN host, round_robin uses all of them, failover the first reachable one in order"},
+	{Name: "keepalive_time", Type: store.OptionTypeDuration, Default: "0s", Description: "Idle time after which the connection is pinged to keep it alive through load balancers, at least 10s, 0s disables pings"},
+	{Name: "keepalive_timeout", Type: store.OptionTypeDuration, Default: "20s", Description: "Time waited for a ping answer before the connection is closed"},
+	{Name: "call_timeout", Type: store.OptionTypeDuration, Default: "0s", Description: "Deadline of unary calls made without one, streaming reads are not bounded, 0s for none"},
+	{Name: "max_recv_msg_bytes", Type: store.OptionTypeInt, Default: fmt.Sprint(defaultMaxMsgBytes), Description: "Largest message accepted from the server"},
+	{Name: "max_send_msg_bytes", Type: store.OptionTypeInt, Default: fmt.Sprint(defaultMaxMsgBytes), Description: "Largest message sent to the server, servers reject messages above their -max-recv-msg-bytes (100MiB by default)"},
+	{Name: "compression", Type: store.OptionTypeString, Values: []string{"none", gzip.Name, zstd.Name}, Description: "Compression of the messages exchanged with the server, none when empty"},
+}
+
+// dialTarget returns the target to dial the comma separated endpoints of `hosts` with,
+// along the dial options resolving and balancing calls across them.
+func dialTarget(hosts string, query url.Values) (string, []grpc.DialOption, error) {
+	var addresses []resolver.Address
+	for _, endpoint := range strings.Split(hosts, ",") {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			// The server name is the authority of the endpoint, verified by TLS
+			addresses = append(addresses, resolver.Address{Addr: endpoint, ServerName: endpoint})
+		}
+	}
+	if len(addresses) == 0 {
+		return "", nil, fmt.Errorf("at least one endpoint required in dsn host")
+	}
+
+	policy := "round_robin"
+	switch query.Get("load_balancing") {
+	case "", "round_robin":
+	case "failover":
+		policy = "pick_first"
+	default:
+		return "", nil, fmt.Errorf("load_balancing option %q is invalid, accepted values are round_robin and failover", query.Get("load_balancing"))
+	}
+
+	endpoints := manual.NewBuilderWithScheme("netkv")
+	endpoints.InitialState(resolver.State{Addresses: addresses})
+
+	return "netkv:///" + hosts, []grpc.DialOption{
+		grpc.WithResolvers(endpoints),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig": [{%q: {}}]}`, policy)),
+	}, nil
+}
+
+// connectionDialOptions returns the keepalive, deadline, message size and compression
+// settings configured by the DSN query parameters.
+func connectionDialOptions(query url.Values) ([]grpc.DialOption, error) {
+	dsnQuery := store.DSNQuery(query)
+
+	keepaliveTime, rawValue, err := dsnQuery.DurationOption("keepalive_time", 0)
+	if err != nil {
+		return nil, fmt.Errorf("keepalive_time option %q is not a valid duration: %w", rawValue, err)
+	}
+	keepaliveTimeout, rawValue, err := dsnQuery.DurationOption("keepalive_timeout", 20*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("keepalive_timeout option %q is not a valid duration: %w", rawValue, err)
+	}
+	callTimeout, rawValue, err := dsnQuery.DurationOption("call_timeout", 0)
+	if err != nil {
+		return nil, fmt.Errorf("call_timeout option %q is not a valid duration: %w", rawValue, err)
+	}
+	maxRecvMsgBytes, rawValue, err := dsnQuery.IntOption("max_recv_msg_bytes", defaultMaxMsgBytes)
+	if err != nil {
+		return nil, fmt.Errorf("max_recv_msg_bytes option %q is not a valid number: %w", rawValue, err)
+	}
+	maxSendMsgBytes, rawValue, err := dsnQuery.IntOption("max_send_msg_bytes", defaultMaxMsgBytes)
+	if err != nil {
+		return nil, fmt.Errorf("max_send_msg_bytes option %q is not a valid number: %w", rawValue, err)
+	}
+
+	if keepaliveTime != 0 && keepaliveTime < minKeepaliveTime {
+		return nil, fmt.Errorf("keepalive_time option must be at least %s, netkv servers reject more frequent pings", minKeepaliveTime)
+	}
+	if maxRecvMsgBytes <= 0 || maxSendMsgBytes <= 0 {
+		return nil, fmt.Errorf("max_recv_msg_bytes and max_send_msg_bytes options must be positive")
+	}
+
+	callOptions := []grpc.CallOption{grpc.MaxCallRecvMsgSize(maxRecvMsgBytes), grpc.MaxCallSendMsgSize(maxSendMsgBytes)}
+	switch compression := query.Get("compression"); compression {
+	case "", "none":
+	case gzip.Name:
+		callOptions = append(callOptions, grpc.UseCompressor(compression))
+	case zstd.Name:
+		zstd.AllowDecompressedBytes(maxRecvMsgBytes)
+		callOptions = append(callOptions, grpc.UseCompressor(compression))
+	default:
+		return nil, fmt.Errorf("compression option %q is invalid, accepted values are none, gzip and zstd", compression)
+	}
+
+	opts := []grpc.DialOption{grpc.WithDefaultCallOptions(callOptions...)}
+	if keepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    keepaliveTime,
+			Timeout: keepaliveTimeout,
+			// Idle connections are the ones load balancers close
+			PermitWithoutStream: true,
+		}))
+	}
+	if callTimeout > 0 {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			if _, found := ctx.Deadline(); !found {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, callTimeout)
+				defer cancel()
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}))
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,131 @@
+package netkv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/streamingfast/kvdb/store"
+	netkvserver "github.com/streamingfast/kvdb/store/netkv/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func launchTestServers(t *testing.T, count int) (servers []*netkvserver.Server, hosts string) {
+	for i := 0; i < count; i++ {
+		server := launchTestServer(t)
+		if i > 0 {
+			hosts += ","
+		}
+		hosts += server.Addr().String()
+		servers = append(servers, server)
+	}
+
+	return servers, hosts
+}
+
+func TestMultipleEndpoints_RoundRobin(t *testing.T) {
+	servers, hosts := launchTestServers(t, 2)
+
+	kvStore, err := store.New(fmt.Sprintf("netkv://%s?insecure=true", hosts))
+	require.NoError(t, err)
+	defer kvStore.Close()
+
+	firstClient, secondClient := openClient(t, servers[0], "insecure=true"), openClient(t, servers[1], "insecure=true")
+	readCalls := func(client store.KVStore) uint64 {
+		stores, err := client.(*Store).ListStores(context.Background())
+		require.NoError(t, err)
+		return stores[0].ReadCalls
+	}
+
+	assert.Eventually(t, func() bool {
+		for i := 0; i < 10; i++ {
+			_, err := store.Exists(context.Background(), kvStore, []byte("a"))
+			require.NoError(t, err)
+		}
+		return readCalls(firstClient) > 0 && readCalls(secondClient) > 0
+	}, 5*time.Second, 10*time.Millisecond, "calls are spread across endpoints")
+}
+
+func TestMultipleEndpoints_Failover(t *testing.T) {
+	servers, hosts := launchTestServers(t, 2)
+
+	ctx := context.Background()
+	require.NoError(t, servers[1].Store(netkvserver.DefaultStoreName).Put(ctx, []byte("a"), []byte("second")))
+	require.NoError(t, servers[1].Store(netkvserver.DefaultStoreName).FlushPuts(ctx))
+
+	kvStore, err := store.New(fmt.Sprintf("netkv://%s?insecure=true&load_balancing=failover", hosts))
+	require.NoError(t, err)
+	defer kvStore.Close()
+
+	for i := 0; i < 5; i++ {
+		_, err = kvStore.Get(ctx, []byte("a"))
+		assert.Equal(t, store.ErrNotFound, err, "first endpoint is used while reachable")
+	}
+
+	require.NoError(t, servers[0].Close())
+
+	assert.Eventually(t, func() bool {
+		value, err := kvStore.Get(ctx, []byte("a"))
+		return err == nil && bytes.Equal(value, []byte("second"))
+	}, 5*time.Second, 10*time.Millisecond, "second endpoint is used once the first is down")
+}
+
+func TestCompression(t *testing.T) {
+	_, hosts := launchTestServers(t, 1)
+	value := bytes.Repeat([]byte("compressible"), 10000)
+
+	for _, compression := range []string{"gzip", "zstd"} {
+		t.Run(compression, func(t *testing.T) {
+			kvStore, err := store.New(fmt.Sprintf("netkv://%s?insecure=true&compression=%s&keepalive_time=10s", hosts, compression))
+			require.NoError(t, err)
+			defer kvStore.Close()
+
+			key := []byte(compression)
+			require.NoError(t, kvStore.Put(context.Background(), key, value))
+			require.NoError(t, kvStore.FlushPuts(context.Background()))
+
+			out, err := kvStore.Get(context.Background(), key)
+			require.NoError(t, err)
+			assert.Equal(t, value, out)
+		})
+	}
+}
+
+func TestMaxRecvMsgBytes(t *testing.T) {
+	kvStore := openClient(t, launchTestServer(t, netkvserver.WithMaxRecvMsgBytes(1024)), "insecure=true")
+
+	require.NoError(t, kvStore.Put(context.Background(), []byte("small"), make([]byte, 100)))
+	require.NoError(t, kvStore.FlushPuts(context.Background()))
+
+	require.NoError(t, kvStore.Put(context.Background(), []byte("large"), make([]byte, 2048)))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(kvStore.FlushPuts(context.Background())), "message above the server limit is rejected")
+}
+
+func TestCallTimeout(t *testing.T) {
+	_, hosts := launchTestServers(t, 1)
+
+	kvStore, err := store.New(fmt.Sprintf("netkv://%s?insecure=true&call_timeout=1ns", hosts))
+	require.NoError(t, err)
+	defer kvStore.Close()
+
+	_, err = store.Exists(context.Background(), kvStore, []byte("a"))
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestConnectionDialOptions_Invalid(t *testing.T) {
+	for _, dsn := range []string{
+		"netkv://?insecure=true",
+		"netkv://localhost:1234?insecure=true&keepalive_time=1s",
+		"netkv://localhost:1234?insecure=true&load_balancing=random",
+		"netkv://localhost:1234?insecure=true&compression=snappy",
+		"netkv://localhost:1234?insecure=true&max_recv_msg_bytes=0",
+	} {
+		_, err := store.New(dsn)
+		assert.Error(t, err, dsn)
+	}
+}
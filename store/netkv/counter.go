@@ -0,0 +1,45 @@
+package netkv
+
+import (
+	"context"
+
+	"github.com/streamingfast/kvdb/store"
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Count implements `store.Counter`, the keys are counted on the server.
+func (s *Store) Count(ctx context.Context, start, exclusiveEnd []byte) (uint64, error) {
+	return s.count(ctx, &pbnetkv.CountRequest{
+		Range: &pbnetkv.CountRequest_KeyRange{KeyRange: &pbnetkv.KeyRange{Start: start, ExclusiveEnd: exclusiveEnd}},
+	})
+}
+
+// CountPrefix implements `store.Counter`, the keys are counted on the server.
+func (s *Store) CountPrefix(ctx context.Context, prefix []byte) (uint64, error) {
+	return s.count(ctx, &pbnetkv.CountRequest{Range: &pbnetkv.CountRequest_Prefix{Prefix: prefix}})
+}
+
+func (s *Store) count(ctx context.Context, req *pbnetkv.CountRequest) (uint64, error) {
+	resp, err := s.client.Count(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Count, nil
+}
+
+// ApproximateSize implements `store.Counter`, returning `store.ErrNotSupported` when the
+// store served by the server cannot estimate sizes.
+func (s *Store) ApproximateSize(ctx context.Context, start, exclusiveEnd []byte) (uint64, error) {
+	resp, err := s.client.ApproximateSize(ctx, &pbnetkv.KeyRange{Start: start, ExclusiveEnd: exclusiveEnd})
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return 0, store.ErrNotSupported
+		}
+		return 0, err
+	}
+
+	return resp.SizeBytes, nil
+}
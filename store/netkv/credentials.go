@@ -0,0 +1,120 @@
+package netkv
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/streamingfast/kvdb/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var credentialsOptionSchemas = store.OptionSchemas{
+	{Name: "insecure", Type: store.OptionTypeBool, Default: "false", Description: "Connect to the server without TLS"},
+	{Name: "tls_ca_file", Type: store.OptionTypeString, Description: "PEM file of the certificate authorities verifying the server certificate, system ones when empty"},
+	{Name: "tls_cert_file", Type: store.OptionTypeString, Description: "PEM file of the client certificate presented to servers requiring mTLS"},
+	{Name: "tls_key_file", Type: store.OptionTypeString, Description: "PEM file of the client certificate private key"},
+	{Name: "tls_server_name", Type: store.OptionTypeString, Description: "Name verified against the server certificate, the DSN host when empty"},
+	{Name: "token", Type: store.OptionTypeString, Description: "Bearer token sent to servers requiring authentication"},
+	{Name: "token_file", Type: store.OptionTypeString, Description: "File holding the bearer token, read on each call so it can be rotated"},
+}
+
+// credentialsDialOptions returns the transport and per call credentials configured by
+// the DSN query parameters.
+func credentialsDialOptions(query url.Values) ([]grpc.DialOption, error) {
+	dsnQuery := store.DSNQuery(query)
+
+	plainText, rawValue, err := dsnQuery.BoolOption("insecure", false)
+	if err != nil {
+		return nil, fmt.Errorf("insecure option %q is not a valid boolean: %w", rawValue, err)
+	}
+
+	caFile, certFile, keyFile, serverName := query.Get("tls_ca_file"), query.Get("tls_cert_file"), query.Get("tls_key_file"), query.Get("tls_server_name")
+	if plainText && (caFile != "" || certFile != "" || keyFile != "" || serverName != "") {
+		return nil, fmt.Errorf("tls options cannot be used along insecure=true")
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("tls_cert_file and tls_key_file must be provided together")
+	}
+
+	var opts []grpc.DialOption
+	if plainText {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	} else {
+		config := &tls.Config{ServerName: serverName, MinVersion: tls.VersionTLS12}
+		if caFile != "" {
+			content, err := os.ReadFile(caFile)
+			if err != nil {
+				return nil, fmt.Errorf("read tls_ca_file: %w", err)
+			}
+
+			config.RootCAs = x509.NewCertPool()
+			if !config.RootCAs.AppendCertsFromPEM(content) {
+				return nil, fmt.Errorf("no PEM certificate found in tls_ca_file %q", caFile)
+			}
+		}
+
+		if certFile != "" {
+			certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err != nil {
+				return nil, fmt.Errorf("load client certificate: %w", err)
+			}
+			config.Certificates = []tls.Certificate{certificate}
+		}
+
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(config)))
+	}
+
+	token, tokenFile := query.Get("token"), query.Get("token_file")
+	if token != "" && tokenFile != "" {
+		return nil, fmt.Errorf("token and token_file options are mutually exclusive")
+	}
+	if token != "" || tokenFile != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(&tokenCredentials{token: token, tokenFile: tokenFile, secure: !plainText}))
+	}
+
+	return opts, nil
+}
+
+// tokenCredentials sends the bearer token in the `authorization` metadata of each call.
+type tokenCredentials struct {
+	token     string
+	tokenFile string
+	secure    bool
+}
+
+func (c *tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	token := c.token
+	if c.tokenFile != "" {
+		content, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("read token_file: %w", err)
+		}
+		token = strings.TrimSpace(string(content))
+	}
+
+	return map[string]string{"authorization": "Bearer " + token}, nil
+}
+
+func (c *tokenCredentials) RequireTransportSecurity() bool {
+	return c.secure
+}
+
+// redactDSN hides the token of the DSN, if any, so it can be logged.
+func redactDSN(dsn *url.URL) string {
+	query := dsn.Query()
+	if query.Get("token") == "" {
+		return dsn.String()
+	}
+
+	query.Set("token", "redacted")
+	redacted := *dsn
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
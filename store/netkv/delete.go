@@ -0,0 +1,23 @@
+package netkv
+
+import (
+	"context"
+
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+)
+
+// DeleteRange implements `store.RangeDeleter`, the keys are deleted by the server.
+func (s *Store) DeleteRange(ctx context.Context, start, exclusiveEnd []byte) error {
+	_, err := s.client.DeleteRange(ctx, &pbnetkv.DeleteRangeRequest{
+		Range: &pbnetkv.DeleteRangeRequest_KeyRange{KeyRange: &pbnetkv.KeyRange{Start: start, ExclusiveEnd: exclusiveEnd}},
+	})
+
+	return err
+}
+
+// DeletePrefix implements `store.RangeDeleter`, the keys are deleted by the server.
+func (s *Store) DeletePrefix(ctx context.Context, prefix []byte) error {
+	_, err := s.client.DeleteRange(ctx, &pbnetkv.DeleteRangeRequest{Range: &pbnetkv.DeleteRangeRequest_Prefix{Prefix: prefix}})
+
+	return err
+}
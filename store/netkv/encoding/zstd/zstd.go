@@ -0,0 +1,115 @@
+// Package zstd registers a zstd gRPC compressor, the NetKV client and server import it
+// so that calls made with `compression=zstd` can be decompressed on both ends.
+package zstd
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+)
+
+// Name is the name the compressor is registered under.
+const Name = "zstd"
+
+// defaultMaxDecompressedBytes is the largest decompressed message until a larger limit is
+// allowed. Frames of the encoders declare their 8MiB window, the decoder rejecting windows
+// larger than the limit, it is never below it.
+const defaultMaxDecompressedBytes = 8 * 1024 * 1024
+
+var registered = newCompressor(defaultMaxDecompressedBytes)
+
+func init() {
+	encoding.RegisterCompressor(registered)
+}
+
+// AllowDecompressedBytes raises the largest message decompressed to at least `bytes`.
+// Messages are decompressed before gRPC checks their size against the receive limit of the
+// call, NetKV clients and servers call it with their receive limit so that a small message
+// decompressing to much more than any of them is rejected early.
+func AllowDecompressedBytes(bytes int) {
+	registered.allow(bytes)
+}
+
+type compressor struct {
+	encoders sync.Pool
+
+	// decoderLock is held for reading while a message is decoded, the decoder being
+	// replaced when a larger limit is allowed
+	decoderLock sync.RWMutex
+	decoder     *zstd.Decoder
+	maxBytes    int
+}
+
+func newCompressor(maxBytes int) *compressor {
+	return &compressor{decoder: newDecoder(maxBytes), maxBytes: maxBytes}
+}
+
+func newDecoder(maxBytes int) *zstd.Decoder {
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(maxBytes)))
+	if err != nil {
+		panic(err)
+	}
+	return decoder
+}
+
+func (c *compressor) allow(maxBytes int) {
+	c.decoderLock.Lock()
+	defer c.decoderLock.Unlock()
+
+	if maxBytes <= c.maxBytes {
+		return
+	}
+
+	c.decoder.Close()
+	c.decoder, c.maxBytes = newDecoder(maxBytes), maxBytes
+}
+
+func (c *compressor) Name() string {
+	return Name
+}
+
+func (c *compressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	if encoder, ok := c.encoders.Get().(*zstd.Encoder); ok {
+		encoder.Reset(w)
+		return &writer{Encoder: encoder, pool: &c.encoders}, nil
+	}
+
+	encoder, err := zstd.NewWriter(w)
+	if err != nil {
+		return nil, err
+	}
+	return &writer{Encoder: encoder, pool: &c.encoders}, nil
+}
+
+// Decompress reads the whole message, gRPC messages being bounded in size, to decode it
+// with the shared decoder, failing once the output exceeds the allowed bytes.
+func (c *compressor) Decompress(r io.Reader) (io.Reader, error) {
+	in, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c.decoderLock.RLock()
+	defer c.decoderLock.RUnlock()
+
+	out, err := c.decoder.DecodeAll(in, nil)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(out), nil
+}
+
+// writer returns its encoder to the pool once the message is written
+type writer struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+func (w *writer) Close() error {
+	err := w.Encoder.Close()
+	w.pool.Put(w.Encoder)
+	return err
+}
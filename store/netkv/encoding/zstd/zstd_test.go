@@ -0,0 +1,54 @@
+package zstd
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/encoding"
+)
+
+func TestCompressor(t *testing.T) {
+	compressor := encoding.GetCompressor(Name)
+	require.NotNil(t, compressor, "registered on init")
+
+	in := bytes.Repeat([]byte("compressible"), 1000)
+	for i := 0; i < 2; i++ {
+		compressed := &bytes.Buffer{}
+		w, err := compressor.Compress(compressed)
+		require.NoError(t, err)
+		_, err = w.Write(in)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assert.Less(t, compressed.Len(), len(in))
+
+		r, err := compressor.Decompress(compressed)
+		require.NoError(t, err)
+		out, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, in, out)
+	}
+}
+
+func TestCompressor_MaxDecompressedBytes(t *testing.T) {
+	compressor := newCompressor(defaultMaxDecompressedBytes)
+
+	compressed := &bytes.Buffer{}
+	w, err := compressor.Compress(compressed)
+	require.NoError(t, err)
+	_, err = w.Write(make([]byte, 16*1024*1024))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	_, err = compressor.Decompress(bytes.NewReader(compressed.Bytes()))
+	assert.Error(t, err, "output larger than allowed is rejected")
+
+	compressor.allow(16 * 1024 * 1024)
+	r, err := compressor.Decompress(bytes.NewReader(compressed.Bytes()))
+	require.NoError(t, err)
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Len(t, out, 16*1024*1024)
+}
@@ -0,0 +1,20 @@
+package netkv
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	s := &Store{}
+
+	assert.True(t, s.IsRetryableError(status.Error(codes.Unavailable, "unavailable")))
+	assert.True(t, s.IsRetryableError(fmt.Errorf("wrapped: %w", status.Error(codes.Unavailable, "unavailable"))))
+	assert.False(t, s.IsRetryableError(fmt.Errorf("wrapped: %w", status.Error(codes.InvalidArgument, "invalid"))))
+	assert.False(t, s.IsRetryableError(errors.New("not a grpc error")))
+}
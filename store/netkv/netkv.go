@@ -6,20 +6,31 @@ import (
 	"io"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/streamingfast/logging"
 
 	"github.com/streamingfast/kvdb/store"
 	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
 
 type Store struct {
-	dsn      string
-	conn     *grpc.ClientConn
-	client   pbnetkv.NetKVClient
-	putBatch []*pbnetkv.KeyValue
+	dsn       string
+	storeName string
+	conn      *grpc.ClientConn
+	client    pbnetkv.NetKVClient
+	writes    *store.WriteBuffer
+
+	streamChunkBytes  int
+	streamWindowBytes int
+	streamUnsupported atomic.Bool
+
+	capabilitiesLock sync.Mutex
+	capabilities     *store.DriverCapabilities
 }
 
 func (s *Store) String() string {
@@ -29,8 +40,9 @@ func (s *Store) String() string {
 func init() {
 	store.Register(&store.Registration{
 		Name:        "netkv",
-		Title:       "netkv",
+		Title:       "NetKV",
 		FactoryFunc: NewStore,
+		Options:     append(append(append(append(store.OptionSchemas{}, credentialsOptionSchemas...), connectionOptionSchemas...), streamOptionSchemas...), store.WriteBufferOptionSchemas(store.WriteBufferOptions{})...),
 	})
 }
 
@@ -40,31 +52,66 @@ func NewStore(dsnString string) (store.KVStore, error) {
 		return nil, fmt.Errorf("badger new: dsn: %w", err)
 	}
 
-	var grpcOpts []grpc.DialOption
-	if dsn.Query().Get("insecure") == "true" {
-		grpcOpts = append(grpcOpts, grpc.WithInsecure())
-		grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(1024*1024*100)))
+	grpcOpts, err := credentialsDialOptions(dsn.Query())
+	if err != nil {
+		return nil, fmt.Errorf("netkv new: dsn: %w", err)
+	}
+	connectionOpts, err := connectionDialOptions(dsn.Query())
+	if err != nil {
+		return nil, fmt.Errorf("netkv new: dsn: %w", err)
+	}
+	grpcOpts = append(grpcOpts, connectionOpts...)
+	storeName := defaultStoreName
+	if name := strings.Trim(dsn.Path, "/"); name != "" {
+		storeName = name
+		grpcOpts = append(grpcOpts, storeSelectionDialOptions(storeName)...)
 	}
 
-	// TODO: init gRPC connection to the `dsn.Host`
-	conn, err := grpc.Dial(dsn.Host, grpcOpts...)
+	target, targetOpts, err := dialTarget(dsn.Host, dsn.Query())
+	if err != nil {
+		return nil, fmt.Errorf("netkv new: dsn: %w", err)
+	}
+
+	conn, err := grpc.Dial(target, append(grpcOpts, targetOpts...)...)
 	if err != nil {
 		return nil, err
 	}
 
+	writeOptions, err := store.NewWriteBufferOptions(dsn.Query(), store.WriteBufferOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("netkv new: dsn: %w", err)
+	}
+
+	query := store.DSNQuery(dsn.Query())
+	streamChunkBytes, rawValue, err := query.IntOption("stream_chunk_bytes", defaultStreamChunkBytes)
+	if err != nil {
+		return nil, fmt.Errorf("stream_chunk_bytes option %q is not a valid number: %w", rawValue, err)
+	}
+	streamWindowBytes, rawValue, err := query.IntOption("stream_window_bytes", defaultStreamWindowBytes)
+	if err != nil {
+		return nil, fmt.Errorf("stream_window_bytes option %q is not a valid number: %w", rawValue, err)
+	}
+	if streamChunkBytes <= 0 || streamWindowBytes <= 0 {
+		return nil, fmt.Errorf("stream_chunk_bytes and stream_window_bytes options must be positive")
+	}
+
 	client := pbnetkv.NewNetKVClient(conn)
 
 	s := &Store{
-		dsn:    dsnString,
-		conn:   conn,
-		client: client,
+		dsn:               redactDSN(dsn),
+		storeName:         storeName,
+		conn:              conn,
+		client:            client,
+		streamChunkBytes:  streamChunkBytes,
+		streamWindowBytes: streamWindowBytes,
 	}
+	s.writes = store.NewWriteBuffer(writeOptions, s.batchPut)
 
 	return s, nil
 }
 
 func (s *Store) Close() error {
-	return s.conn.Close()
+	return multierr.Append(s.writes.Close(), s.conn.Close())
 }
 
 func (s *Store) Put(ctx context.Context, key, value []byte) (err error) {
@@ -74,20 +121,11 @@ func (s *Store) Put(ctx context.Context, key, value []byte) (err error) {
 		zlogger.Debug("putting key in store", zap.Stringer("key", store.Key(key)))
 	}
 
-	s.putBatch = append(s.putBatch, &pbnetkv.KeyValue{Key: key, Value: value})
-	return nil
+	return s.writes.Put(ctx, key, value)
 }
 
 func (s *Store) FlushPuts(ctx context.Context) error {
-	if s.putBatch == nil {
-		return nil
-	}
-	_, err := s.client.BatchPut(ctx, &pbnetkv.KeyValues{Kvs: s.putBatch})
-	if err != nil {
-		return err
-	}
-	s.putBatch = nil
-	return nil
+	return s.writes.Flush(ctx)
 }
 
 func wrapNotFoundError(err error) error {
@@ -99,6 +137,10 @@ func wrapNotFoundError(err error) error {
 }
 
 func (s *Store) Get(ctx context.Context, key []byte) (value []byte, err error) {
+	if value, found := s.writes.Get(key); found {
+		return value, nil
+	}
+
 	resp, err := s.client.BatchGet(ctx, &pbnetkv.Keys{Keys: [][]byte{key}})
 	if err != nil {
 		return nil, err
@@ -123,6 +165,10 @@ func (s *Store) Get(ctx context.Context, key []byte) (value []byte, err error) {
 }
 
 func (s *Store) BatchGet(ctx context.Context, keys [][]byte) *store.Iterator {
+	return s.writes.OverlayBatchGet(ctx, keys, s.batchGet)
+}
+
+func (s *Store) batchGet(ctx context.Context, keys [][]byte) *store.Iterator {
 	it := store.NewIterator(ctx)
 
 	go func() {
@@ -141,7 +187,41 @@ func (s *Store) BatchGet(ctx context.Context, keys [][]byte) *store.Iterator {
 	return it
 }
 
+func (s *Store) Exists(ctx context.Context, key []byte) (bool, error) {
+	exists, err := s.BatchExists(ctx, [][]byte{key})
+	if err != nil {
+		return false, err
+	}
+
+	return exists[0], nil
+}
+
+func (s *Store) BatchExists(ctx context.Context, keys [][]byte) ([]bool, error) {
+	resp, err := s.client.BatchExists(ctx, &pbnetkv.Keys{Keys: keys})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Exists) != len(keys) {
+		return nil, fmt.Errorf("expected %d exists results, got %d", len(keys), len(resp.Exists))
+	}
+
+	for i, key := range keys {
+		if _, found := s.writes.Get(key); found {
+			resp.Exists[i] = true
+		}
+	}
+
+	return resp.Exists, nil
+}
+
+func (s *Store) Delete(ctx context.Context, key []byte) error {
+	return s.BatchDelete(ctx, [][]byte{key})
+}
+
 func (s *Store) BatchDelete(ctx context.Context, keys [][]byte) (err error) {
+	s.writes.Discard(keys...)
+
 	if _, err := s.client.BatchDelete(ctx, &pbnetkv.Keys{Keys: keys}); err != nil {
 		return err
 	}
@@ -149,7 +229,7 @@ func (s *Store) BatchDelete(ctx context.Context, keys [][]byte) (err error) {
 }
 
 func (s *Store) Scan(ctx context.Context, start, exclusiveEnd []byte, limit int, options ...store.ReadOption) *store.Iterator {
-	it := store.NewIterator(ctx)
+	it := store.NewScanIterator(ctx, start, exclusiveEnd, limit, options)
 
 	go func() {
 		resp, err := s.client.Scan(ctx, &pbnetkv.ScanRequest{Start: start, ExclusiveEnd: exclusiveEnd, Limit: uint64(limit), Options: netkvReadOptions(options)})
@@ -164,7 +244,7 @@ func (s *Store) Scan(ctx context.Context, start, exclusiveEnd []byte, limit int,
 			}
 		}
 	}()
-	return it
+	return s.writes.OverlayScan(it, start, exclusiveEnd, limit, options)
 }
 
 func pushToIterator(it *store.Iterator, kv *pbnetkv.KeyValue, err error) bool {
@@ -182,7 +262,7 @@ func pushToIterator(it *store.Iterator, kv *pbnetkv.KeyValue, err error) bool {
 }
 
 func (s *Store) Prefix(ctx context.Context, prefix []byte, limit int, options ...store.ReadOption) *store.Iterator {
-	it := store.NewIterator(ctx)
+	it := store.NewPrefixIterator(ctx, prefix, limit, options)
 
 	go func() {
 		resp, err := s.client.Prefix(ctx, &pbnetkv.PrefixRequest{Prefix: prefix, Limit: uint64(limit), Options: netkvReadOptions(options)})
@@ -197,11 +277,11 @@ func (s *Store) Prefix(ctx context.Context, prefix []byte, limit int, options ..
 			}
 		}
 	}()
-	return it
+	return s.writes.OverlayPrefix(it, prefix, limit, options)
 }
 
 func (s *Store) BatchPrefix(ctx context.Context, prefixes [][]byte, limitPerPrefix int, options ...store.ReadOption) *store.Iterator {
-	it := store.NewIterator(ctx)
+	it := store.NewBatchPrefixIterator(ctx, prefixes, limitPerPrefix, options)
 
 	go func() {
 		resp, err := s.client.BatchPrefix(ctx, &pbnetkv.BatchPrefixRequest{Prefixes: prefixes, LimitPerPrefix: uint64(limitPerPrefix), Options: netkvReadOptions(options)})
@@ -216,6 +296,27 @@ func (s *Store) BatchPrefix(ctx context.Context, prefixes [][]byte, limitPerPref
 			}
 		}
 	}()
+	return s.writes.OverlayBatchPrefix(it, prefixes, limitPerPrefix, options)
+}
+
+// ReadFromCursor implements `store.CursorReader`, the cursor is sent as-is to the
+// server which continues the read on its side.
+func (s *Store) ReadFromCursor(ctx context.Context, cursor *store.Cursor, limit int) *store.Iterator {
+	it := store.NewCursorIterator(ctx, cursor, limit)
+
+	go func() {
+		resp, err := s.client.Scan(ctx, &pbnetkv.ScanRequest{Cursor: cursor.Bytes(), Limit: uint64(limit)})
+		if err != nil {
+			it.PushError(err)
+			return
+		}
+		for {
+			kv, err := resp.Recv()
+			if !pushToIterator(it, kv, err) {
+				break
+			}
+		}
+	}()
 	return it
 }
 
@@ -234,6 +335,13 @@ func netkvReadOptions(options []store.ReadOption) *pbnetkv.ReadOptions {
 	}
 
 	return &pbnetkv.ReadOptions{
-		KeyOnly: readOptions.KeyOnly,
+		KeyOnly:      readOptions.KeyOnly,
+		BufferSize:   uint32(readOptions.BufferSize),
+		BufferBytes:  uint64(readOptions.BufferBytes),
+		PrefetchSize: uint32(readOptions.PrefetchSize),
+		KeyRegex:     readOptions.KeyRegex,
+		ValuePrefix:  readOptions.ValuePrefix,
+		MinValueSize: uint64(readOptions.MinValueSize),
+		MaxValueSize: uint64(readOptions.MaxValueSize),
 	}
 }
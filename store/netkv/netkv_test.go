@@ -1,29 +1,111 @@
 package netkv
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"path"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
-	"github.com/streamingfast/logging"
 	"github.com/streamingfast/kvdb/store"
 	_ "github.com/streamingfast/kvdb/store/badger"
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
 	netkvserver "github.com/streamingfast/kvdb/store/netkv/server"
 	"github.com/streamingfast/kvdb/store/storetest"
+	"github.com/streamingfast/logging"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func init() {
 	logging.TestingOverride()
 }
 
+// launchTestServer serves a badger store in a temporary directory, closed at the end of the test.
+func launchTestServer(t *testing.T, opts ...netkvserver.Option) *netkvserver.Server {
+	t.Helper()
+
+	server, err := netkvserver.Launch("127.0.0.1:0", fmt.Sprintf("badger://%s", filepath.Join(t.TempDir(), "db")), opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	return server
+}
+
+// openClient opens a client of the server, closed at the end of the test. The query is the
+// one of the DSN, optionally preceded by the path selecting a hosted store (`/blocks?...`).
+func openClient(t *testing.T, server *netkvserver.Server, query string) store.KVStore {
+	t.Helper()
+
+	if !strings.HasPrefix(query, "/") {
+		query = "?" + query
+	}
+
+	kvStore, err := store.New(fmt.Sprintf("netkv://%s%s", server.Addr(), query))
+	require.NoError(t, err)
+	t.Cleanup(func() { kvStore.Close() })
+
+	return kvStore
+}
+
 func TestAll(t *testing.T) {
-	storetest.TestAll(t, "NetKV", newTestNetKVFactory(t))
+	storetest.TestAll(t, "NetKV", newTestNetKVFactory(t, ""))
+}
+
+func TestAll_ReadYourWrites(t *testing.T) {
+	storetest.TestAll(t, "NetKV", newTestNetKVFactory(t, "&read_your_writes=true"))
+}
+
+func TestScanCursor(t *testing.T) {
+	kvStore, _, cleanup := newTestNetKVFactory(t, "")()
+	defer cleanup()
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, kvStore.Put(context.Background(), []byte(key), []byte("1")))
+	}
+	require.NoError(t, kvStore.FlushPuts(context.Background()))
+
+	client := kvStore.(*Store).client
+	scan := func(req *pbnetkv.ScanRequest) (out []*pbnetkv.KeyValue) {
+		stream, err := client.Scan(context.Background(), req)
+		require.NoError(t, err)
+		for {
+			kv, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			require.NoError(t, err)
+			out = append(out, kv)
+		}
+	}
+
+	includeCursor := &pbnetkv.ReadOptions{IncludeCursor: true}
+
+	page := scan(&pbnetkv.ScanRequest{Start: []byte("a"), ExclusiveEnd: []byte("z"), Limit: 2, Options: includeCursor})
+	require.Len(t, page, 2)
+	assert.Nil(t, page[0].Cursor)
+	require.NotNil(t, page[1].Cursor)
+
+	page = scan(&pbnetkv.ScanRequest{Cursor: page[1].Cursor, Limit: 2, Options: includeCursor})
+	require.Len(t, page, 1)
+	assert.Equal(t, []byte("c"), page[0].Key)
+	assert.Nil(t, page[0].Cursor)
+
+	stream, err := client.Scan(context.Background(), &pbnetkv.ScanRequest{Cursor: []byte("invalid")})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
 }
 
-func newTestNetKVFactory(t *testing.T) storetest.DriverFactory {
+// newTestNetKVFactory serves a badger store and opens a client with the extra DSN query,
+// starting with `&`.
+func newTestNetKVFactory(t *testing.T, query string) storetest.DriverFactory {
 	return func(opts ...store.Option) (store.KVStore, *storetest.DriverCapabilities, storetest.DriverCleanupFunc) {
 		// Start a server
 		dir, err := ioutil.TempDir("", "kvdb-netkv-server")
@@ -34,7 +116,7 @@ func newTestNetKVFactory(t *testing.T) storetest.DriverFactory {
 		time.Sleep(100 * time.Millisecond)
 
 		// Setup the `netkv` client, and test it.
-		dsn2 := fmt.Sprintf("netkv://localhost:65112?insecure=true")
+		dsn2 := fmt.Sprintf("netkv://localhost:65112?insecure=true%s", query)
 		kvStore, err := store.New(dsn2, opts...)
 		require.NoError(t, err)
 
@@ -24,8 +24,54 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type ReplicationChange_Kind int32
+
+const (
+	ReplicationChange_PUT           ReplicationChange_Kind = 0
+	ReplicationChange_DELETE        ReplicationChange_Kind = 1
+	ReplicationChange_DELETE_RANGE  ReplicationChange_Kind = 2
+	ReplicationChange_DELETE_PREFIX ReplicationChange_Kind = 3
+)
+
+var ReplicationChange_Kind_name = map[int32]string{
+	0: "PUT",
+	1: "DELETE",
+	2: "DELETE_RANGE",
+	3: "DELETE_PREFIX",
+}
+
+var ReplicationChange_Kind_value = map[string]int32{
+	"PUT":           0,
+	"DELETE":        1,
+	"DELETE_RANGE":  2,
+	"DELETE_PREFIX": 3,
+}
+
+func (x ReplicationChange_Kind) String() string {
+	return proto.EnumName(ReplicationChange_Kind_name, int32(x))
+}
+
+func (ReplicationChange_Kind) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{27, 0}
+}
+
 type ReadOptions struct {
-	KeyOnly              bool     `protobuf:"varint,1,opt,name=key_only,json=keyOnly,proto3" json:"key_only,omitempty"`
+	KeyOnly bool `protobuf:"varint,1,opt,name=key_only,json=keyOnly,proto3" json:"key_only,omitempty"`
+	// include_cursor requests the server to set the `cursor` field of the last
+	// item streamed back when there is more to read after it.
+	IncludeCursor bool `protobuf:"varint,2,opt,name=include_cursor,json=includeCursor,proto3" json:"include_cursor,omitempty"`
+	// buffer_size, buffer_bytes and prefetch_size are applied to the server side
+	// read, see the `store.ReadOptions` fields of the same name.
+	BufferSize   uint32 `protobuf:"varint,3,opt,name=buffer_size,json=bufferSize,proto3" json:"buffer_size,omitempty"`
+	BufferBytes  uint64 `protobuf:"varint,4,opt,name=buffer_bytes,json=bufferBytes,proto3" json:"buffer_bytes,omitempty"`
+	PrefetchSize uint32 `protobuf:"varint,5,opt,name=prefetch_size,json=prefetchSize,proto3" json:"prefetch_size,omitempty"`
+	// key_regex, value_prefix, min_value_size and max_value_size filter the items
+	// before they are streamed back, see the `store.ReadOptions` fields of the
+	// same name.
+	KeyRegex             string   `protobuf:"bytes,6,opt,name=key_regex,json=keyRegex,proto3" json:"key_regex,omitempty"`
+	ValuePrefix          []byte   `protobuf:"bytes,7,opt,name=value_prefix,json=valuePrefix,proto3" json:"value_prefix,omitempty"`
+	MinValueSize         uint64   `protobuf:"varint,8,opt,name=min_value_size,json=minValueSize,proto3" json:"min_value_size,omitempty"`
+	MaxValueSize         uint64   `protobuf:"varint,9,opt,name=max_value_size,json=maxValueSize,proto3" json:"max_value_size,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -63,9 +109,69 @@ func (m *ReadOptions) GetKeyOnly() bool {
 	return false
 }
 
+func (m *ReadOptions) GetIncludeCursor() bool {
+	if m != nil {
+		return m.IncludeCursor
+	}
+	return false
+}
+
+func (m *ReadOptions) GetBufferSize() uint32 {
+	if m != nil {
+		return m.BufferSize
+	}
+	return 0
+}
+
+func (m *ReadOptions) GetBufferBytes() uint64 {
+	if m != nil {
+		return m.BufferBytes
+	}
+	return 0
+}
+
+func (m *ReadOptions) GetPrefetchSize() uint32 {
+	if m != nil {
+		return m.PrefetchSize
+	}
+	return 0
+}
+
+func (m *ReadOptions) GetKeyRegex() string {
+	if m != nil {
+		return m.KeyRegex
+	}
+	return ""
+}
+
+func (m *ReadOptions) GetValuePrefix() []byte {
+	if m != nil {
+		return m.ValuePrefix
+	}
+	return nil
+}
+
+func (m *ReadOptions) GetMinValueSize() uint64 {
+	if m != nil {
+		return m.MinValueSize
+	}
+	return 0
+}
+
+func (m *ReadOptions) GetMaxValueSize() uint64 {
+	if m != nil {
+		return m.MaxValueSize
+	}
+	return 0
+}
+
 type KeyValue struct {
-	Key                  []byte   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Value                []byte   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Key   []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// cursor is an opaque continuation point, set only when requested through
+	// `ReadOptions.include_cursor`, it can be sent back in a request `cursor` field
+	// to continue the read right after this item.
+	Cursor               []byte   `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -110,6 +216,13 @@ func (m *KeyValue) GetValue() []byte {
 	return nil
 }
 
+func (m *KeyValue) GetCursor() []byte {
+	if m != nil {
+		return m.Cursor
+	}
+	return nil
+}
+
 type KeyValues struct {
 	Kvs                  []*KeyValue `protobuf:"bytes,1,rep,name=kvs,proto3" json:"kvs,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
@@ -149,6 +262,96 @@ func (m *KeyValues) GetKvs() []*KeyValue {
 	return nil
 }
 
+type StreamPutRequest struct {
+	Kvs []*KeyValue `protobuf:"bytes,1,rep,name=kvs,proto3" json:"kvs,omitempty"`
+	// flush requests the server to flush the puts received so far, including the
+	// ones of this message, and to acknowledge them.
+	Flush                bool     `protobuf:"varint,2,opt,name=flush,proto3" json:"flush,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamPutRequest) Reset()         { *m = StreamPutRequest{} }
+func (m *StreamPutRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamPutRequest) ProtoMessage()    {}
+func (*StreamPutRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{3}
+}
+
+func (m *StreamPutRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamPutRequest.Unmarshal(m, b)
+}
+func (m *StreamPutRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamPutRequest.Marshal(b, m, deterministic)
+}
+func (m *StreamPutRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamPutRequest.Merge(m, src)
+}
+func (m *StreamPutRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamPutRequest.Size(m)
+}
+func (m *StreamPutRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamPutRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamPutRequest proto.InternalMessageInfo
+
+func (m *StreamPutRequest) GetKvs() []*KeyValue {
+	if m != nil {
+		return m.Kvs
+	}
+	return nil
+}
+
+func (m *StreamPutRequest) GetFlush() bool {
+	if m != nil {
+		return m.Flush
+	}
+	return false
+}
+
+type StreamPutResponse struct {
+	// committed_count is the number of puts, since the start of the stream, that
+	// were flushed to the store.
+	CommittedCount       uint64   `protobuf:"varint,1,opt,name=committed_count,json=committedCount,proto3" json:"committed_count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamPutResponse) Reset()         { *m = StreamPutResponse{} }
+func (m *StreamPutResponse) String() string { return proto.CompactTextString(m) }
+func (*StreamPutResponse) ProtoMessage()    {}
+func (*StreamPutResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{4}
+}
+
+func (m *StreamPutResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamPutResponse.Unmarshal(m, b)
+}
+func (m *StreamPutResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamPutResponse.Marshal(b, m, deterministic)
+}
+func (m *StreamPutResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamPutResponse.Merge(m, src)
+}
+func (m *StreamPutResponse) XXX_Size() int {
+	return xxx_messageInfo_StreamPutResponse.Size(m)
+}
+func (m *StreamPutResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamPutResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamPutResponse proto.InternalMessageInfo
+
+func (m *StreamPutResponse) GetCommittedCount() uint64 {
+	if m != nil {
+		return m.CommittedCount
+	}
+	return 0
+}
+
 type Keys struct {
 	Keys                 [][]byte `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -160,7 +363,7 @@ func (m *Keys) Reset()         { *m = Keys{} }
 func (m *Keys) String() string { return proto.CompactTextString(m) }
 func (*Keys) ProtoMessage()    {}
 func (*Keys) Descriptor() ([]byte, []int) {
-	return fileDescriptor_25aabd6fb5784ada, []int{3}
+	return fileDescriptor_25aabd6fb5784ada, []int{5}
 }
 
 func (m *Keys) XXX_Unmarshal(b []byte) error {
@@ -199,7 +402,7 @@ func (m *Values) Reset()         { *m = Values{} }
 func (m *Values) String() string { return proto.CompactTextString(m) }
 func (*Values) ProtoMessage()    {}
 func (*Values) Descriptor() ([]byte, []int) {
-	return fileDescriptor_25aabd6fb5784ada, []int{4}
+	return fileDescriptor_25aabd6fb5784ada, []int{6}
 }
 
 func (m *Values) XXX_Unmarshal(b []byte) error {
@@ -227,21 +430,64 @@ func (m *Values) GetValues() [][]byte {
 	return nil
 }
 
+type ExistsResponse struct {
+	// exists is in the same order as the requested keys.
+	Exists               []bool   `protobuf:"varint,1,rep,packed,name=exists,proto3" json:"exists,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExistsResponse) Reset()         { *m = ExistsResponse{} }
+func (m *ExistsResponse) String() string { return proto.CompactTextString(m) }
+func (*ExistsResponse) ProtoMessage()    {}
+func (*ExistsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{7}
+}
+
+func (m *ExistsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExistsResponse.Unmarshal(m, b)
+}
+func (m *ExistsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExistsResponse.Marshal(b, m, deterministic)
+}
+func (m *ExistsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExistsResponse.Merge(m, src)
+}
+func (m *ExistsResponse) XXX_Size() int {
+	return xxx_messageInfo_ExistsResponse.Size(m)
+}
+func (m *ExistsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExistsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExistsResponse proto.InternalMessageInfo
+
+func (m *ExistsResponse) GetExists() []bool {
+	if m != nil {
+		return m.Exists
+	}
+	return nil
+}
+
 type ScanRequest struct {
-	Start                []byte       `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
-	ExclusiveEnd         []byte       `protobuf:"bytes,2,opt,name=exclusive_end,json=exclusiveEnd,proto3" json:"exclusive_end,omitempty"`
-	Limit                uint64       `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
-	Options              *ReadOptions `protobuf:"bytes,4,opt,name=options,proto3" json:"options,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+	Start        []byte       `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	ExclusiveEnd []byte       `protobuf:"bytes,2,opt,name=exclusive_end,json=exclusiveEnd,proto3" json:"exclusive_end,omitempty"`
+	Limit        uint64       `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Options      *ReadOptions `protobuf:"bytes,4,opt,name=options,proto3" json:"options,omitempty"`
+	// cursor, when set, continues the read it was obtained from, `start` and
+	// `exclusive_end` are then ignored.
+	Cursor               []byte   `protobuf:"bytes,5,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *ScanRequest) Reset()         { *m = ScanRequest{} }
 func (m *ScanRequest) String() string { return proto.CompactTextString(m) }
 func (*ScanRequest) ProtoMessage()    {}
 func (*ScanRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_25aabd6fb5784ada, []int{5}
+	return fileDescriptor_25aabd6fb5784ada, []int{8}
 }
 
 func (m *ScanRequest) XXX_Unmarshal(b []byte) error {
@@ -290,20 +536,29 @@ func (m *ScanRequest) GetOptions() *ReadOptions {
 	return nil
 }
 
+func (m *ScanRequest) GetCursor() []byte {
+	if m != nil {
+		return m.Cursor
+	}
+	return nil
+}
+
 type BatchPrefixRequest struct {
-	Prefixes             [][]byte     `protobuf:"bytes,1,rep,name=prefixes,proto3" json:"prefixes,omitempty"`
-	LimitPerPrefix       uint64       `protobuf:"varint,2,opt,name=limit_per_prefix,json=limitPerPrefix,proto3" json:"limit_per_prefix,omitempty"`
-	Options              *ReadOptions `protobuf:"bytes,3,opt,name=options,proto3" json:"options,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+	Prefixes       [][]byte     `protobuf:"bytes,1,rep,name=prefixes,proto3" json:"prefixes,omitempty"`
+	LimitPerPrefix uint64       `protobuf:"varint,2,opt,name=limit_per_prefix,json=limitPerPrefix,proto3" json:"limit_per_prefix,omitempty"`
+	Options        *ReadOptions `protobuf:"bytes,3,opt,name=options,proto3" json:"options,omitempty"`
+	// cursor, when set, continues the read it was obtained from, `prefixes` is then ignored.
+	Cursor               []byte   `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *BatchPrefixRequest) Reset()         { *m = BatchPrefixRequest{} }
 func (m *BatchPrefixRequest) String() string { return proto.CompactTextString(m) }
 func (*BatchPrefixRequest) ProtoMessage()    {}
 func (*BatchPrefixRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_25aabd6fb5784ada, []int{6}
+	return fileDescriptor_25aabd6fb5784ada, []int{9}
 }
 
 func (m *BatchPrefixRequest) XXX_Unmarshal(b []byte) error {
@@ -345,6 +600,13 @@ func (m *BatchPrefixRequest) GetOptions() *ReadOptions {
 	return nil
 }
 
+func (m *BatchPrefixRequest) GetCursor() []byte {
+	if m != nil {
+		return m.Cursor
+	}
+	return nil
+}
+
 type BatchScanRequest struct {
 	Start                [][]byte `protobuf:"bytes,1,rep,name=start,proto3" json:"start,omitempty"`
 	ExclusiveEnd         [][]byte `protobuf:"bytes,2,rep,name=exclusive_end,json=exclusiveEnd,proto3" json:"exclusive_end,omitempty"`
@@ -358,7 +620,7 @@ func (m *BatchScanRequest) Reset()         { *m = BatchScanRequest{} }
 func (m *BatchScanRequest) String() string { return proto.CompactTextString(m) }
 func (*BatchScanRequest) ProtoMessage()    {}
 func (*BatchScanRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_25aabd6fb5784ada, []int{7}
+	return fileDescriptor_25aabd6fb5784ada, []int{10}
 }
 
 func (m *BatchScanRequest) XXX_Unmarshal(b []byte) error {
@@ -401,19 +663,21 @@ func (m *BatchScanRequest) GetLimitPerScan() uint64 {
 }
 
 type PrefixRequest struct {
-	Prefix               []byte       `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
-	Limit                uint64       `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
-	Options              *ReadOptions `protobuf:"bytes,3,opt,name=options,proto3" json:"options,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+	Prefix  []byte       `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Limit   uint64       `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Options *ReadOptions `protobuf:"bytes,3,opt,name=options,proto3" json:"options,omitempty"`
+	// cursor, when set, continues the read it was obtained from, `prefix` is then ignored.
+	Cursor               []byte   `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *PrefixRequest) Reset()         { *m = PrefixRequest{} }
 func (m *PrefixRequest) String() string { return proto.CompactTextString(m) }
 func (*PrefixRequest) ProtoMessage()    {}
 func (*PrefixRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_25aabd6fb5784ada, []int{8}
+	return fileDescriptor_25aabd6fb5784ada, []int{11}
 }
 
 func (m *PrefixRequest) XXX_Unmarshal(b []byte) error {
@@ -455,141 +719,1145 @@ func (m *PrefixRequest) GetOptions() *ReadOptions {
 	return nil
 }
 
-type EmptyResponse struct {
+func (m *PrefixRequest) GetCursor() []byte {
+	if m != nil {
+		return m.Cursor
+	}
+	return nil
+}
+
+type KeyRange struct {
+	Start []byte `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	// exclusive_end, when empty, means no upper bound for `ApproximateSize` and
+	// an empty range for `Count`, like `Scan` does.
+	ExclusiveEnd         []byte   `protobuf:"bytes,2,opt,name=exclusive_end,json=exclusiveEnd,proto3" json:"exclusive_end,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *EmptyResponse) Reset()         { *m = EmptyResponse{} }
-func (m *EmptyResponse) String() string { return proto.CompactTextString(m) }
-func (*EmptyResponse) ProtoMessage()    {}
-func (*EmptyResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_25aabd6fb5784ada, []int{9}
+func (m *KeyRange) Reset()         { *m = KeyRange{} }
+func (m *KeyRange) String() string { return proto.CompactTextString(m) }
+func (*KeyRange) ProtoMessage()    {}
+func (*KeyRange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{12}
 }
 
-func (m *EmptyResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_EmptyResponse.Unmarshal(m, b)
+func (m *KeyRange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyRange.Unmarshal(m, b)
 }
-func (m *EmptyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_EmptyResponse.Marshal(b, m, deterministic)
+func (m *KeyRange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyRange.Marshal(b, m, deterministic)
 }
-func (m *EmptyResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_EmptyResponse.Merge(m, src)
+func (m *KeyRange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyRange.Merge(m, src)
 }
-func (m *EmptyResponse) XXX_Size() int {
-	return xxx_messageInfo_EmptyResponse.Size(m)
+func (m *KeyRange) XXX_Size() int {
+	return xxx_messageInfo_KeyRange.Size(m)
 }
-func (m *EmptyResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_EmptyResponse.DiscardUnknown(m)
+func (m *KeyRange) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyRange.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_EmptyResponse proto.InternalMessageInfo
+var xxx_messageInfo_KeyRange proto.InternalMessageInfo
 
-func init() {
-	proto.RegisterType((*ReadOptions)(nil), "dfuse.netkv.v1.ReadOptions")
-	proto.RegisterType((*KeyValue)(nil), "dfuse.netkv.v1.KeyValue")
-	proto.RegisterType((*KeyValues)(nil), "dfuse.netkv.v1.KeyValues")
-	proto.RegisterType((*Keys)(nil), "dfuse.netkv.v1.Keys")
-	proto.RegisterType((*Values)(nil), "dfuse.netkv.v1.Values")
-	proto.RegisterType((*ScanRequest)(nil), "dfuse.netkv.v1.ScanRequest")
-	proto.RegisterType((*BatchPrefixRequest)(nil), "dfuse.netkv.v1.BatchPrefixRequest")
-	proto.RegisterType((*BatchScanRequest)(nil), "dfuse.netkv.v1.BatchScanRequest")
-	proto.RegisterType((*PrefixRequest)(nil), "dfuse.netkv.v1.PrefixRequest")
-	proto.RegisterType((*EmptyResponse)(nil), "dfuse.netkv.v1.EmptyResponse")
+func (m *KeyRange) GetStart() []byte {
+	if m != nil {
+		return m.Start
+	}
+	return nil
 }
 
-func init() { proto.RegisterFile("netkv.proto", fileDescriptor_25aabd6fb5784ada) }
+func (m *KeyRange) GetExclusiveEnd() []byte {
+	if m != nil {
+		return m.ExclusiveEnd
+	}
+	return nil
+}
 
-var fileDescriptor_25aabd6fb5784ada = []byte{
-	// 512 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0x4d, 0x6b, 0xdb, 0x40,
-	0x10, 0x45, 0x91, 0x22, 0xcb, 0x23, 0xdb, 0x35, 0x4b, 0x08, 0x8a, 0x4b, 0x41, 0xa8, 0x3d, 0x88,
-	0x1e, 0x4c, 0xeb, 0x52, 0x7a, 0x29, 0x14, 0xdc, 0x84, 0x52, 0x4c, 0x9b, 0xb0, 0x85, 0x1c, 0x7a,
-	0x31, 0x8a, 0x3d, 0xa1, 0x42, 0x8a, 0xa4, 0x6a, 0x57, 0x22, 0xfa, 0x19, 0x3d, 0xf4, 0x77, 0xf6,
-	0x2f, 0x14, 0xed, 0xae, 0x1c, 0x7f, 0x44, 0x6e, 0x7d, 0xd3, 0xcc, 0xbc, 0x7d, 0xfb, 0xde, 0x9b,
-	0x45, 0x60, 0x27, 0xc8, 0xa3, 0x72, 0x9c, 0xe5, 0x29, 0x4f, 0xc9, 0x60, 0x79, 0x5b, 0x30, 0x1c,
-	0xcb, 0x56, 0xf9, 0xda, 0xf3, 0xc1, 0xa6, 0x18, 0x2c, 0x2f, 0x33, 0x1e, 0xa6, 0x09, 0x23, 0x67,
-	0x60, 0x45, 0x58, 0xcd, 0xd3, 0x24, 0xae, 0x1c, 0xcd, 0xd5, 0x7c, 0x8b, 0x76, 0x22, 0xac, 0x2e,
-	0x93, 0xb8, 0xf2, 0x26, 0x60, 0xcd, 0xb0, 0xba, 0x0e, 0xe2, 0x02, 0xc9, 0x10, 0xf4, 0x08, 0x25,
-	0xa2, 0x47, 0xeb, 0x4f, 0x72, 0x02, 0xc7, 0x65, 0x3d, 0x72, 0x8e, 0x44, 0x4f, 0x16, 0xde, 0x3b,
-	0xe8, 0x36, 0x67, 0x18, 0x79, 0x09, 0x7a, 0x54, 0x32, 0x47, 0x73, 0x75, 0xdf, 0x9e, 0x38, 0xe3,
-	0x4d, 0x21, 0xe3, 0x06, 0x47, 0x6b, 0x90, 0x37, 0x02, 0x63, 0x86, 0x15, 0x23, 0x04, 0x8c, 0x08,
-	0x2b, 0x79, 0xa8, 0x47, 0xc5, 0xb7, 0xe7, 0x82, 0xa9, 0x18, 0x4f, 0xc1, 0x14, 0xf7, 0x34, 0x73,
-	0x55, 0x79, 0xbf, 0x35, 0xb0, 0xbf, 0x2d, 0x82, 0x84, 0xe2, 0xcf, 0x02, 0x19, 0xaf, 0xc5, 0x31,
-	0x1e, 0xe4, 0x5c, 0x09, 0x96, 0x05, 0x79, 0x0e, 0x7d, 0xbc, 0x5f, 0xc4, 0x05, 0x0b, 0x4b, 0x9c,
-	0x63, 0xb2, 0x54, 0xd2, 0x7b, 0xab, 0xe6, 0x45, 0xb2, 0xac, 0x8f, 0xc6, 0xe1, 0x5d, 0xc8, 0x1d,
-	0xdd, 0xd5, 0x7c, 0x83, 0xca, 0x82, 0xbc, 0x85, 0x4e, 0x2a, 0x13, 0x73, 0x0c, 0x57, 0xf3, 0xed,
-	0xc9, 0xd3, 0x6d, 0x3b, 0x6b, 0xa1, 0xd2, 0x06, 0xeb, 0xfd, 0xd2, 0x80, 0x4c, 0x03, 0xbe, 0xf8,
-	0x71, 0x95, 0xe3, 0x6d, 0x78, 0xdf, 0xc8, 0x1b, 0x81, 0x95, 0x89, 0xc6, 0xca, 0xc8, 0xaa, 0x26,
-	0x3e, 0x0c, 0xc5, 0x95, 0xf3, 0x0c, 0xf3, 0xb9, 0xec, 0x0a, 0x9d, 0x06, 0x1d, 0x88, 0xfe, 0x15,
-	0xe6, 0x92, 0x6c, 0x5d, 0x93, 0x7e, 0x80, 0x26, 0x06, 0x43, 0x21, 0xa9, 0x25, 0x2f, 0x7d, 0x6f,
-	0x5e, 0xfa, 0x4e, 0x5e, 0x2f, 0x60, 0xf0, 0xa0, 0x97, 0x2d, 0x82, 0x44, 0x05, 0xd7, 0x6b, 0xd4,
-	0xd6, 0xf7, 0x78, 0x1c, 0xfa, 0x9b, 0x11, 0x9c, 0x82, 0xa9, 0xcc, 0xc9, 0x15, 0xa9, 0xea, 0x21,
-	0xfe, 0xa3, 0x96, 0xf8, 0x0f, 0xb1, 0xfa, 0x04, 0xfa, 0x17, 0x77, 0x19, 0xaf, 0x28, 0xb2, 0x2c,
-	0x4d, 0x18, 0x4e, 0xfe, 0xe8, 0x70, 0xfc, 0x15, 0xf9, 0xec, 0x9a, 0x9c, 0x83, 0x25, 0x17, 0x53,
-	0x70, 0x72, 0xd6, 0xf6, 0x34, 0xd9, 0xe8, 0xd9, 0xf6, 0x68, 0x83, 0x8f, 0xbc, 0x57, 0x2c, 0x9f,
-	0x90, 0x93, 0x93, 0x47, 0x58, 0xd8, 0xa8, 0xf5, 0xd9, 0xbf, 0xd2, 0xc8, 0x07, 0x30, 0xea, 0x70,
-	0xc8, 0x8e, 0x99, 0xb5, 0xd5, 0xec, 0x25, 0xf8, 0x0c, 0xdd, 0xd5, 0x2a, 0x89, 0xbb, 0x0d, 0xdc,
-	0xde, 0xf2, 0x5e, 0xaa, 0x29, 0xd8, 0x02, 0x7f, 0x8e, 0x31, 0x72, 0x6c, 0x31, 0xf3, 0x8f, 0x34,
-	0x3e, 0x82, 0xa9, 0x9e, 0xe6, 0x0e, 0x70, 0x63, 0xf9, 0x7b, 0x85, 0x7c, 0x51, 0x42, 0x14, 0x93,
-	0xf7, 0xa8, 0xab, 0xff, 0xa6, 0x9b, 0x76, 0xbf, 0x77, 0xb2, 0x1b, 0x31, 0xb8, 0x31, 0xc5, 0x0f,
-	0xf1, 0xcd, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x20, 0x4f, 0x5f, 0x52, 0x1f, 0x05, 0x00, 0x00,
+type CountRequest struct {
+	// Types that are valid to be assigned to Range:
+	//	*CountRequest_KeyRange
+	//	*CountRequest_Prefix
+	Range                isCountRequest_Range `protobuf_oneof:"range"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *CountRequest) Reset()         { *m = CountRequest{} }
+func (m *CountRequest) String() string { return proto.CompactTextString(m) }
+func (*CountRequest) ProtoMessage()    {}
+func (*CountRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{13}
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *CountRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CountRequest.Unmarshal(m, b)
+}
+func (m *CountRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CountRequest.Marshal(b, m, deterministic)
+}
+func (m *CountRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CountRequest.Merge(m, src)
+}
+func (m *CountRequest) XXX_Size() int {
+	return xxx_messageInfo_CountRequest.Size(m)
+}
+func (m *CountRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CountRequest.DiscardUnknown(m)
+}
 
-// NetKVClient is the client API for NetKV service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type NetKVClient interface {
-	BatchPut(ctx context.Context, in *KeyValues, opts ...grpc.CallOption) (*EmptyResponse, error)
-	// TODO: we need to be able to get individual responses
-	// regarding Not-Foundness of each key, otherwise, we'll have
-	// a hard time knowing which key was not found, etc..
-	// This will happen more with sparse trxdb.
-	BatchGet(ctx context.Context, in *Keys, opts ...grpc.CallOption) (NetKV_BatchGetClient, error)
-	Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (NetKV_ScanClient, error)
-	BatchScan(ctx context.Context, in *BatchScanRequest, opts ...grpc.CallOption) (NetKV_BatchScanClient, error)
-	BatchDelete(ctx context.Context, in *Keys, opts ...grpc.CallOption) (*EmptyResponse, error)
-	Prefix(ctx context.Context, in *PrefixRequest, opts ...grpc.CallOption) (NetKV_PrefixClient, error)
-	BatchPrefix(ctx context.Context, in *BatchPrefixRequest, opts ...grpc.CallOption) (NetKV_BatchPrefixClient, error)
+var xxx_messageInfo_CountRequest proto.InternalMessageInfo
+
+type isCountRequest_Range interface {
+	isCountRequest_Range()
 }
 
-type netKVClient struct {
-	cc *grpc.ClientConn
+type CountRequest_KeyRange struct {
+	KeyRange *KeyRange `protobuf:"bytes,1,opt,name=key_range,json=keyRange,proto3,oneof"`
 }
 
-func NewNetKVClient(cc *grpc.ClientConn) NetKVClient {
-	return &netKVClient{cc}
+type CountRequest_Prefix struct {
+	Prefix []byte `protobuf:"bytes,2,opt,name=prefix,proto3,oneof"`
 }
 
-func (c *netKVClient) BatchPut(ctx context.Context, in *KeyValues, opts ...grpc.CallOption) (*EmptyResponse, error) {
-	out := new(EmptyResponse)
-	err := c.cc.Invoke(ctx, "/dfuse.netkv.v1.NetKV/BatchPut", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (*CountRequest_KeyRange) isCountRequest_Range() {}
+
+func (*CountRequest_Prefix) isCountRequest_Range() {}
+
+func (m *CountRequest) GetRange() isCountRequest_Range {
+	if m != nil {
+		return m.Range
 	}
-	return out, nil
+	return nil
 }
 
-func (c *netKVClient) BatchGet(ctx context.Context, in *Keys, opts ...grpc.CallOption) (NetKV_BatchGetClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_NetKV_serviceDesc.Streams[0], "/dfuse.netkv.v1.NetKV/BatchGet", opts...)
-	if err != nil {
-		return nil, err
+func (m *CountRequest) GetKeyRange() *KeyRange {
+	if x, ok := m.GetRange().(*CountRequest_KeyRange); ok {
+		return x.KeyRange
 	}
-	x := &netKVBatchGetClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
+	return nil
+}
+
+func (m *CountRequest) GetPrefix() []byte {
+	if x, ok := m.GetRange().(*CountRequest_Prefix); ok {
+		return x.Prefix
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*CountRequest) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*CountRequest_KeyRange)(nil),
+		(*CountRequest_Prefix)(nil),
+	}
+}
+
+type CountResponse struct {
+	Count                uint64   `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CountResponse) Reset()         { *m = CountResponse{} }
+func (m *CountResponse) String() string { return proto.CompactTextString(m) }
+func (*CountResponse) ProtoMessage()    {}
+func (*CountResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{14}
+}
+
+func (m *CountResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CountResponse.Unmarshal(m, b)
+}
+func (m *CountResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CountResponse.Marshal(b, m, deterministic)
+}
+func (m *CountResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CountResponse.Merge(m, src)
+}
+func (m *CountResponse) XXX_Size() int {
+	return xxx_messageInfo_CountResponse.Size(m)
+}
+func (m *CountResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CountResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CountResponse proto.InternalMessageInfo
+
+func (m *CountResponse) GetCount() uint64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type ApproximateSizeResponse struct {
+	SizeBytes            uint64   `protobuf:"varint,1,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ApproximateSizeResponse) Reset()         { *m = ApproximateSizeResponse{} }
+func (m *ApproximateSizeResponse) String() string { return proto.CompactTextString(m) }
+func (*ApproximateSizeResponse) ProtoMessage()    {}
+func (*ApproximateSizeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{15}
+}
+
+func (m *ApproximateSizeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ApproximateSizeResponse.Unmarshal(m, b)
+}
+func (m *ApproximateSizeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ApproximateSizeResponse.Marshal(b, m, deterministic)
+}
+func (m *ApproximateSizeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ApproximateSizeResponse.Merge(m, src)
+}
+func (m *ApproximateSizeResponse) XXX_Size() int {
+	return xxx_messageInfo_ApproximateSizeResponse.Size(m)
+}
+func (m *ApproximateSizeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ApproximateSizeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ApproximateSizeResponse proto.InternalMessageInfo
+
+func (m *ApproximateSizeResponse) GetSizeBytes() uint64 {
+	if m != nil {
+		return m.SizeBytes
+	}
+	return 0
+}
+
+type DeleteRangeRequest struct {
+	// Types that are valid to be assigned to Range:
+	//	*DeleteRangeRequest_KeyRange
+	//	*DeleteRangeRequest_Prefix
+	Range                isDeleteRangeRequest_Range `protobuf_oneof:"range"`
+	XXX_NoUnkeyedLiteral struct{}                   `json:"-"`
+	XXX_unrecognized     []byte                     `json:"-"`
+	XXX_sizecache        int32                      `json:"-"`
+}
+
+func (m *DeleteRangeRequest) Reset()         { *m = DeleteRangeRequest{} }
+func (m *DeleteRangeRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteRangeRequest) ProtoMessage()    {}
+func (*DeleteRangeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{16}
+}
+
+func (m *DeleteRangeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteRangeRequest.Unmarshal(m, b)
+}
+func (m *DeleteRangeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteRangeRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteRangeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteRangeRequest.Merge(m, src)
+}
+func (m *DeleteRangeRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteRangeRequest.Size(m)
+}
+func (m *DeleteRangeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteRangeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteRangeRequest proto.InternalMessageInfo
+
+type isDeleteRangeRequest_Range interface {
+	isDeleteRangeRequest_Range()
+}
+
+type DeleteRangeRequest_KeyRange struct {
+	KeyRange *KeyRange `protobuf:"bytes,1,opt,name=key_range,json=keyRange,proto3,oneof"`
+}
+
+type DeleteRangeRequest_Prefix struct {
+	Prefix []byte `protobuf:"bytes,2,opt,name=prefix,proto3,oneof"`
+}
+
+func (*DeleteRangeRequest_KeyRange) isDeleteRangeRequest_Range() {}
+
+func (*DeleteRangeRequest_Prefix) isDeleteRangeRequest_Range() {}
+
+func (m *DeleteRangeRequest) GetRange() isDeleteRangeRequest_Range {
+	if m != nil {
+		return m.Range
+	}
+	return nil
+}
+
+func (m *DeleteRangeRequest) GetKeyRange() *KeyRange {
+	if x, ok := m.GetRange().(*DeleteRangeRequest_KeyRange); ok {
+		return x.KeyRange
+	}
+	return nil
+}
+
+func (m *DeleteRangeRequest) GetPrefix() []byte {
+	if x, ok := m.GetRange().(*DeleteRangeRequest_Prefix); ok {
+		return x.Prefix
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*DeleteRangeRequest) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*DeleteRangeRequest_KeyRange)(nil),
+		(*DeleteRangeRequest_Prefix)(nil),
+	}
+}
+
+type EmptyResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EmptyResponse) Reset()         { *m = EmptyResponse{} }
+func (m *EmptyResponse) String() string { return proto.CompactTextString(m) }
+func (*EmptyResponse) ProtoMessage()    {}
+func (*EmptyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{17}
+}
+
+func (m *EmptyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EmptyResponse.Unmarshal(m, b)
+}
+func (m *EmptyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EmptyResponse.Marshal(b, m, deterministic)
+}
+func (m *EmptyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EmptyResponse.Merge(m, src)
+}
+func (m *EmptyResponse) XXX_Size() int {
+	return xxx_messageInfo_EmptyResponse.Size(m)
+}
+func (m *EmptyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_EmptyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EmptyResponse proto.InternalMessageInfo
+
+type ListStoresRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListStoresRequest) Reset()         { *m = ListStoresRequest{} }
+func (m *ListStoresRequest) String() string { return proto.CompactTextString(m) }
+func (*ListStoresRequest) ProtoMessage()    {}
+func (*ListStoresRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{18}
+}
+
+func (m *ListStoresRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListStoresRequest.Unmarshal(m, b)
+}
+func (m *ListStoresRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListStoresRequest.Marshal(b, m, deterministic)
+}
+func (m *ListStoresRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListStoresRequest.Merge(m, src)
+}
+func (m *ListStoresRequest) XXX_Size() int {
+	return xxx_messageInfo_ListStoresRequest.Size(m)
+}
+func (m *ListStoresRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListStoresRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListStoresRequest proto.InternalMessageInfo
+
+type ListStoresResponse struct {
+	Stores               []*StoreInfo `protobuf:"bytes,1,rep,name=stores,proto3" json:"stores,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *ListStoresResponse) Reset()         { *m = ListStoresResponse{} }
+func (m *ListStoresResponse) String() string { return proto.CompactTextString(m) }
+func (*ListStoresResponse) ProtoMessage()    {}
+func (*ListStoresResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{19}
+}
+
+func (m *ListStoresResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListStoresResponse.Unmarshal(m, b)
+}
+func (m *ListStoresResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListStoresResponse.Marshal(b, m, deterministic)
+}
+func (m *ListStoresResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListStoresResponse.Merge(m, src)
+}
+func (m *ListStoresResponse) XXX_Size() int {
+	return xxx_messageInfo_ListStoresResponse.Size(m)
+}
+func (m *ListStoresResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListStoresResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListStoresResponse proto.InternalMessageInfo
+
+func (m *ListStoresResponse) GetStores() []*StoreInfo {
+	if m != nil {
+		return m.Stores
+	}
+	return nil
+}
+
+type StoreInfo struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// driver is the registered name of the driver backing the store, i.e. `badger`.
+	Driver string `protobuf:"bytes,2,opt,name=driver,proto3" json:"driver,omitempty"`
+	// approximate_size_bytes is only meaningful when `size_known` is set, some
+	// drivers cannot estimate their size.
+	ApproximateSizeBytes uint64 `protobuf:"varint,3,opt,name=approximate_size_bytes,json=approximateSizeBytes,proto3" json:"approximate_size_bytes,omitempty"`
+	SizeKnown            bool   `protobuf:"varint,4,opt,name=size_known,json=sizeKnown,proto3" json:"size_known,omitempty"`
+	// read_calls and write_calls count the calls served since the server started.
+	ReadCalls  uint64 `protobuf:"varint,5,opt,name=read_calls,json=readCalls,proto3" json:"read_calls,omitempty"`
+	WriteCalls uint64 `protobuf:"varint,6,opt,name=write_calls,json=writeCalls,proto3" json:"write_calls,omitempty"`
+	// key_count is only filled by `Stats` when `count_keys` is requested.
+	KeyCount      uint64 `protobuf:"varint,7,opt,name=key_count,json=keyCount,proto3" json:"key_count,omitempty"`
+	KeyCountKnown bool   `protobuf:"varint,8,opt,name=key_count_known,json=keyCountKnown,proto3" json:"key_count_known,omitempty"`
+	// capabilities are the names of the capabilities the store reports, see
+	// `store.DriverCapabilities.Names`.
+	Capabilities         []string `protobuf:"bytes,9,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StoreInfo) Reset()         { *m = StoreInfo{} }
+func (m *StoreInfo) String() string { return proto.CompactTextString(m) }
+func (*StoreInfo) ProtoMessage()    {}
+func (*StoreInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{20}
+}
+
+func (m *StoreInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StoreInfo.Unmarshal(m, b)
+}
+func (m *StoreInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StoreInfo.Marshal(b, m, deterministic)
+}
+func (m *StoreInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StoreInfo.Merge(m, src)
+}
+func (m *StoreInfo) XXX_Size() int {
+	return xxx_messageInfo_StoreInfo.Size(m)
+}
+func (m *StoreInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_StoreInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StoreInfo proto.InternalMessageInfo
+
+func (m *StoreInfo) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StoreInfo) GetDriver() string {
+	if m != nil {
+		return m.Driver
+	}
+	return ""
+}
+
+func (m *StoreInfo) GetApproximateSizeBytes() uint64 {
+	if m != nil {
+		return m.ApproximateSizeBytes
+	}
+	return 0
+}
+
+func (m *StoreInfo) GetSizeKnown() bool {
+	if m != nil {
+		return m.SizeKnown
+	}
+	return false
+}
+
+func (m *StoreInfo) GetReadCalls() uint64 {
+	if m != nil {
+		return m.ReadCalls
+	}
+	return 0
+}
+
+func (m *StoreInfo) GetWriteCalls() uint64 {
+	if m != nil {
+		return m.WriteCalls
+	}
+	return 0
+}
+
+func (m *StoreInfo) GetKeyCount() uint64 {
+	if m != nil {
+		return m.KeyCount
+	}
+	return 0
+}
+
+func (m *StoreInfo) GetKeyCountKnown() bool {
+	if m != nil {
+		return m.KeyCountKnown
+	}
+	return false
+}
+
+func (m *StoreInfo) GetCapabilities() []string {
+	if m != nil {
+		return m.Capabilities
+	}
+	return nil
+}
+
+type StatsRequest struct {
+	// count_keys counts the keys of each store, which reads all of them on
+	// drivers without native counting.
+	CountKeys            bool     `protobuf:"varint,1,opt,name=count_keys,json=countKeys,proto3" json:"count_keys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StatsRequest) Reset()         { *m = StatsRequest{} }
+func (m *StatsRequest) String() string { return proto.CompactTextString(m) }
+func (*StatsRequest) ProtoMessage()    {}
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{21}
+}
+
+func (m *StatsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatsRequest.Unmarshal(m, b)
+}
+func (m *StatsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatsRequest.Marshal(b, m, deterministic)
+}
+func (m *StatsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatsRequest.Merge(m, src)
+}
+func (m *StatsRequest) XXX_Size() int {
+	return xxx_messageInfo_StatsRequest.Size(m)
+}
+func (m *StatsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatsRequest proto.InternalMessageInfo
+
+func (m *StatsRequest) GetCountKeys() bool {
+	if m != nil {
+		return m.CountKeys
+	}
+	return false
+}
+
+type StatsResponse struct {
+	Stores        []*StoreInfo `protobuf:"bytes,1,rep,name=stores,proto3" json:"stores,omitempty"`
+	UptimeSeconds uint64       `protobuf:"varint,2,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	// active_calls is the number of NetKV and NetKVAdmin calls in progress,
+	// including this one.
+	ActiveCalls          uint64   `protobuf:"varint,3,opt,name=active_calls,json=activeCalls,proto3" json:"active_calls,omitempty"`
+	ReadOnly             bool     `protobuf:"varint,4,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	Draining             bool     `protobuf:"varint,5,opt,name=draining,proto3" json:"draining,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StatsResponse) Reset()         { *m = StatsResponse{} }
+func (m *StatsResponse) String() string { return proto.CompactTextString(m) }
+func (*StatsResponse) ProtoMessage()    {}
+func (*StatsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{22}
+}
+
+func (m *StatsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatsResponse.Unmarshal(m, b)
+}
+func (m *StatsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatsResponse.Marshal(b, m, deterministic)
+}
+func (m *StatsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatsResponse.Merge(m, src)
+}
+func (m *StatsResponse) XXX_Size() int {
+	return xxx_messageInfo_StatsResponse.Size(m)
+}
+func (m *StatsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatsResponse proto.InternalMessageInfo
+
+func (m *StatsResponse) GetStores() []*StoreInfo {
+	if m != nil {
+		return m.Stores
+	}
+	return nil
+}
+
+func (m *StatsResponse) GetUptimeSeconds() uint64 {
+	if m != nil {
+		return m.UptimeSeconds
+	}
+	return 0
+}
+
+func (m *StatsResponse) GetActiveCalls() uint64 {
+	if m != nil {
+		return m.ActiveCalls
+	}
+	return 0
+}
+
+func (m *StatsResponse) GetReadOnly() bool {
+	if m != nil {
+		return m.ReadOnly
+	}
+	return false
+}
+
+func (m *StatsResponse) GetDraining() bool {
+	if m != nil {
+		return m.Draining
+	}
+	return false
+}
+
+type CompactRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CompactRequest) Reset()         { *m = CompactRequest{} }
+func (m *CompactRequest) String() string { return proto.CompactTextString(m) }
+func (*CompactRequest) ProtoMessage()    {}
+func (*CompactRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{23}
+}
+
+func (m *CompactRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompactRequest.Unmarshal(m, b)
+}
+func (m *CompactRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompactRequest.Marshal(b, m, deterministic)
+}
+func (m *CompactRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompactRequest.Merge(m, src)
+}
+func (m *CompactRequest) XXX_Size() int {
+	return xxx_messageInfo_CompactRequest.Size(m)
+}
+func (m *CompactRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompactRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompactRequest proto.InternalMessageInfo
+
+type FlushRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FlushRequest) Reset()         { *m = FlushRequest{} }
+func (m *FlushRequest) String() string { return proto.CompactTextString(m) }
+func (*FlushRequest) ProtoMessage()    {}
+func (*FlushRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{24}
+}
+
+func (m *FlushRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FlushRequest.Unmarshal(m, b)
+}
+func (m *FlushRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FlushRequest.Marshal(b, m, deterministic)
+}
+func (m *FlushRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FlushRequest.Merge(m, src)
+}
+func (m *FlushRequest) XXX_Size() int {
+	return xxx_messageInfo_FlushRequest.Size(m)
+}
+func (m *FlushRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FlushRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FlushRequest proto.InternalMessageInfo
+
+type BackupRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BackupRequest) Reset()         { *m = BackupRequest{} }
+func (m *BackupRequest) String() string { return proto.CompactTextString(m) }
+func (*BackupRequest) ProtoMessage()    {}
+func (*BackupRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{25}
+}
+
+func (m *BackupRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackupRequest.Unmarshal(m, b)
+}
+func (m *BackupRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackupRequest.Marshal(b, m, deterministic)
+}
+func (m *BackupRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupRequest.Merge(m, src)
+}
+func (m *BackupRequest) XXX_Size() int {
+	return xxx_messageInfo_BackupRequest.Size(m)
+}
+func (m *BackupRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupRequest proto.InternalMessageInfo
+
+type ReplicateRequest struct {
+	// log_id and from_sequence are the position of the follower, the changes made
+	// after it are streamed back. A snapshot of the store is sent first when the
+	// position is unknown to the server (no log_id, another log or evicted).
+	LogId                string   `protobuf:"bytes,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	FromSequence         uint64   `protobuf:"varint,2,opt,name=from_sequence,json=fromSequence,proto3" json:"from_sequence,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReplicateRequest) Reset()         { *m = ReplicateRequest{} }
+func (m *ReplicateRequest) String() string { return proto.CompactTextString(m) }
+func (*ReplicateRequest) ProtoMessage()    {}
+func (*ReplicateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{26}
+}
+
+func (m *ReplicateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReplicateRequest.Unmarshal(m, b)
+}
+func (m *ReplicateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReplicateRequest.Marshal(b, m, deterministic)
+}
+func (m *ReplicateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReplicateRequest.Merge(m, src)
+}
+func (m *ReplicateRequest) XXX_Size() int {
+	return xxx_messageInfo_ReplicateRequest.Size(m)
+}
+func (m *ReplicateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReplicateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReplicateRequest proto.InternalMessageInfo
+
+func (m *ReplicateRequest) GetLogId() string {
+	if m != nil {
+		return m.LogId
+	}
+	return ""
+}
+
+func (m *ReplicateRequest) GetFromSequence() uint64 {
+	if m != nil {
+		return m.FromSequence
+	}
+	return 0
+}
+
+type ReplicationChange struct {
+	Kind ReplicationChange_Kind `protobuf:"varint,1,opt,name=kind,proto3,enum=dfuse.netkv.v1.ReplicationChange_Kind" json:"kind,omitempty"`
+	// key is the key put or deleted, the start of the range or the prefix deleted.
+	Key                  []byte   `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Value                []byte   `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	ExclusiveEnd         []byte   `protobuf:"bytes,4,opt,name=exclusive_end,json=exclusiveEnd,proto3" json:"exclusive_end,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReplicationChange) Reset()         { *m = ReplicationChange{} }
+func (m *ReplicationChange) String() string { return proto.CompactTextString(m) }
+func (*ReplicationChange) ProtoMessage()    {}
+func (*ReplicationChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{27}
+}
+
+func (m *ReplicationChange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReplicationChange.Unmarshal(m, b)
+}
+func (m *ReplicationChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReplicationChange.Marshal(b, m, deterministic)
+}
+func (m *ReplicationChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReplicationChange.Merge(m, src)
+}
+func (m *ReplicationChange) XXX_Size() int {
+	return xxx_messageInfo_ReplicationChange.Size(m)
+}
+func (m *ReplicationChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReplicationChange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReplicationChange proto.InternalMessageInfo
+
+func (m *ReplicationChange) GetKind() ReplicationChange_Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return ReplicationChange_PUT
+}
+
+func (m *ReplicationChange) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *ReplicationChange) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *ReplicationChange) GetExclusiveEnd() []byte {
+	if m != nil {
+		return m.ExclusiveEnd
+	}
+	return nil
+}
+
+type ReplicationBatch struct {
+	// log_id identifies the change log of the server, positions are meaningless
+	// in another log.
+	LogId string `protobuf:"bytes,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	// sequence is the position in the log once the batch is applied.
+	Sequence uint64 `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// clear is set on the first batch of a snapshot, the follower must delete all
+	// its keys before applying it.
+	Clear bool `protobuf:"varint,3,opt,name=clear,proto3" json:"clear,omitempty"`
+	// snapshot is set on the batches of a snapshot, the follower position is only
+	// reached once a batch without it, possibly empty, is applied.
+	Snapshot             bool                 `protobuf:"varint,4,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	Changes              []*ReplicationChange `protobuf:"bytes,5,rep,name=changes,proto3" json:"changes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ReplicationBatch) Reset()         { *m = ReplicationBatch{} }
+func (m *ReplicationBatch) String() string { return proto.CompactTextString(m) }
+func (*ReplicationBatch) ProtoMessage()    {}
+func (*ReplicationBatch) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25aabd6fb5784ada, []int{28}
+}
+
+func (m *ReplicationBatch) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReplicationBatch.Unmarshal(m, b)
+}
+func (m *ReplicationBatch) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReplicationBatch.Marshal(b, m, deterministic)
+}
+func (m *ReplicationBatch) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReplicationBatch.Merge(m, src)
+}
+func (m *ReplicationBatch) XXX_Size() int {
+	return xxx_messageInfo_ReplicationBatch.Size(m)
+}
+func (m *ReplicationBatch) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReplicationBatch.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReplicationBatch proto.InternalMessageInfo
+
+func (m *ReplicationBatch) GetLogId() string {
+	if m != nil {
+		return m.LogId
+	}
+	return ""
+}
+
+func (m *ReplicationBatch) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *ReplicationBatch) GetClear() bool {
+	if m != nil {
+		return m.Clear
+	}
+	return false
+}
+
+func (m *ReplicationBatch) GetSnapshot() bool {
+	if m != nil {
+		return m.Snapshot
+	}
+	return false
+}
+
+func (m *ReplicationBatch) GetChanges() []*ReplicationChange {
+	if m != nil {
+		return m.Changes
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("dfuse.netkv.v1.ReplicationChange_Kind", ReplicationChange_Kind_name, ReplicationChange_Kind_value)
+	proto.RegisterType((*ReadOptions)(nil), "dfuse.netkv.v1.ReadOptions")
+	proto.RegisterType((*KeyValue)(nil), "dfuse.netkv.v1.KeyValue")
+	proto.RegisterType((*KeyValues)(nil), "dfuse.netkv.v1.KeyValues")
+	proto.RegisterType((*StreamPutRequest)(nil), "dfuse.netkv.v1.StreamPutRequest")
+	proto.RegisterType((*StreamPutResponse)(nil), "dfuse.netkv.v1.StreamPutResponse")
+	proto.RegisterType((*Keys)(nil), "dfuse.netkv.v1.Keys")
+	proto.RegisterType((*Values)(nil), "dfuse.netkv.v1.Values")
+	proto.RegisterType((*ExistsResponse)(nil), "dfuse.netkv.v1.ExistsResponse")
+	proto.RegisterType((*ScanRequest)(nil), "dfuse.netkv.v1.ScanRequest")
+	proto.RegisterType((*BatchPrefixRequest)(nil), "dfuse.netkv.v1.BatchPrefixRequest")
+	proto.RegisterType((*BatchScanRequest)(nil), "dfuse.netkv.v1.BatchScanRequest")
+	proto.RegisterType((*PrefixRequest)(nil), "dfuse.netkv.v1.PrefixRequest")
+	proto.RegisterType((*KeyRange)(nil), "dfuse.netkv.v1.KeyRange")
+	proto.RegisterType((*CountRequest)(nil), "dfuse.netkv.v1.CountRequest")
+	proto.RegisterType((*CountResponse)(nil), "dfuse.netkv.v1.CountResponse")
+	proto.RegisterType((*ApproximateSizeResponse)(nil), "dfuse.netkv.v1.ApproximateSizeResponse")
+	proto.RegisterType((*DeleteRangeRequest)(nil), "dfuse.netkv.v1.DeleteRangeRequest")
+	proto.RegisterType((*EmptyResponse)(nil), "dfuse.netkv.v1.EmptyResponse")
+	proto.RegisterType((*ListStoresRequest)(nil), "dfuse.netkv.v1.ListStoresRequest")
+	proto.RegisterType((*ListStoresResponse)(nil), "dfuse.netkv.v1.ListStoresResponse")
+	proto.RegisterType((*StoreInfo)(nil), "dfuse.netkv.v1.StoreInfo")
+	proto.RegisterType((*StatsRequest)(nil), "dfuse.netkv.v1.StatsRequest")
+	proto.RegisterType((*StatsResponse)(nil), "dfuse.netkv.v1.StatsResponse")
+	proto.RegisterType((*CompactRequest)(nil), "dfuse.netkv.v1.CompactRequest")
+	proto.RegisterType((*FlushRequest)(nil), "dfuse.netkv.v1.FlushRequest")
+	proto.RegisterType((*BackupRequest)(nil), "dfuse.netkv.v1.BackupRequest")
+	proto.RegisterType((*ReplicateRequest)(nil), "dfuse.netkv.v1.ReplicateRequest")
+	proto.RegisterType((*ReplicationChange)(nil), "dfuse.netkv.v1.ReplicationChange")
+	proto.RegisterType((*ReplicationBatch)(nil), "dfuse.netkv.v1.ReplicationBatch")
+}
+
+func init() { proto.RegisterFile("netkv.proto", fileDescriptor_25aabd6fb5784ada) }
+
+var fileDescriptor_25aabd6fb5784ada = []byte{
+	// 1527 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x58, 0xdd, 0x72, 0x1b, 0xc5,
+	0x12, 0xce, 0xea, 0x5f, 0xad, 0x95, 0x2c, 0xcf, 0xf1, 0xf1, 0x51, 0x94, 0xe3, 0xa0, 0x2c, 0xf9,
+	0x51, 0x51, 0x85, 0xcb, 0x31, 0x50, 0xa1, 0x20, 0x55, 0x10, 0xdb, 0x4a, 0x62, 0x1c, 0x12, 0xb3,
+	0x32, 0x29, 0x8a, 0x1b, 0xd5, 0x7a, 0x35, 0xb6, 0x07, 0xad, 0x76, 0x97, 0x9d, 0x91, 0x63, 0xe5,
+	0x19, 0x78, 0x11, 0x2e, 0xb8, 0x83, 0x37, 0x80, 0x2b, 0xde, 0x81, 0x37, 0xa1, 0x8a, 0x9a, 0x9e,
+	0xd9, 0xf5, 0x4a, 0x6b, 0xd9, 0x10, 0x28, 0xee, 0xd4, 0xdf, 0xf4, 0xf4, 0x76, 0x7f, 0xdd, 0xd3,
+	0xdd, 0x25, 0xa8, 0xf9, 0x54, 0x8c, 0x4e, 0xd7, 0xc3, 0x28, 0x10, 0x01, 0x69, 0x0c, 0x8f, 0x26,
+	0x9c, 0xae, 0x2b, 0xe8, 0xf4, 0xbe, 0xf5, 0x73, 0x0e, 0x6a, 0x36, 0x75, 0x86, 0x2f, 0x42, 0xc1,
+	0x02, 0x9f, 0x93, 0xeb, 0x50, 0x19, 0xd1, 0xe9, 0x20, 0xf0, 0xbd, 0x69, 0xcb, 0xe8, 0x18, 0xdd,
+	0x8a, 0x5d, 0x1e, 0xd1, 0xe9, 0x0b, 0xdf, 0x9b, 0x92, 0x3b, 0xd0, 0x60, 0xbe, 0xeb, 0x4d, 0x86,
+	0x74, 0xe0, 0x4e, 0x22, 0x1e, 0x44, 0xad, 0x1c, 0x2a, 0xd4, 0x35, 0xba, 0x8d, 0x20, 0x79, 0x0b,
+	0x6a, 0x87, 0x93, 0xa3, 0x23, 0x1a, 0x0d, 0x38, 0x7b, 0x4d, 0x5b, 0xf9, 0x8e, 0xd1, 0xad, 0xdb,
+	0xa0, 0xa0, 0x3e, 0x7b, 0x4d, 0xc9, 0x2d, 0x30, 0xb5, 0xc2, 0xe1, 0x54, 0x50, 0xde, 0x2a, 0x74,
+	0x8c, 0x6e, 0xc1, 0xd6, 0x97, 0xb6, 0x24, 0x44, 0xde, 0x86, 0x7a, 0x18, 0xd1, 0x23, 0x2a, 0xdc,
+	0x13, 0x65, 0xa5, 0x88, 0x56, 0xcc, 0x18, 0x44, 0x3b, 0x37, 0xa0, 0x2a, 0x5d, 0x8d, 0xe8, 0x31,
+	0x3d, 0x6b, 0x95, 0x3a, 0x46, 0xb7, 0x6a, 0x4b, 0xdf, 0x6d, 0x29, 0xcb, 0x8f, 0x9c, 0x3a, 0xde,
+	0x84, 0x0e, 0xe4, 0x15, 0x76, 0xd6, 0x2a, 0x77, 0x8c, 0xae, 0x69, 0xd7, 0x10, 0xdb, 0x47, 0x88,
+	0xdc, 0x86, 0xc6, 0x98, 0xf9, 0x03, 0xa5, 0x86, 0x5f, 0xa9, 0xa0, 0x27, 0xe6, 0x98, 0xf9, 0x2f,
+	0x25, 0x88, 0x5f, 0x91, 0x5a, 0xce, 0x59, 0x5a, 0xab, 0xaa, 0xb5, 0x9c, 0xb3, 0x44, 0xcb, 0xfa,
+	0x0c, 0x2a, 0x7b, 0x74, 0x8a, 0x32, 0x69, 0x42, 0x7e, 0x44, 0x15, 0x7b, 0xa6, 0x2d, 0x7f, 0x92,
+	0x15, 0x28, 0xe2, 0x7d, 0x24, 0xcc, 0xb4, 0x95, 0x40, 0x56, 0xa1, 0xa4, 0x79, 0x2c, 0x20, 0xac,
+	0x25, 0xeb, 0x01, 0x54, 0x63, 0x5b, 0x9c, 0xbc, 0x03, 0xf9, 0xd1, 0x29, 0x6f, 0x19, 0x9d, 0x7c,
+	0xb7, 0xb6, 0xd9, 0x5a, 0x9f, 0xcd, 0xde, 0x7a, 0xac, 0x67, 0x4b, 0x25, 0xeb, 0x00, 0x9a, 0x7d,
+	0x11, 0x51, 0x67, 0xbc, 0x3f, 0x11, 0x36, 0xfd, 0x76, 0x42, 0xb9, 0xf8, 0x2b, 0xf7, 0xa5, 0x9b,
+	0x47, 0xde, 0x84, 0x9f, 0xe8, 0xbc, 0x2a, 0xc1, 0x7a, 0x08, 0xcb, 0x29, 0xab, 0x3c, 0x0c, 0x7c,
+	0x4e, 0xc9, 0x3d, 0x58, 0x72, 0x83, 0xf1, 0x98, 0x09, 0x41, 0x87, 0x03, 0x37, 0x98, 0xf8, 0x02,
+	0xe3, 0x2d, 0xd8, 0x8d, 0x04, 0xde, 0x96, 0xa8, 0xd5, 0x86, 0xc2, 0x1e, 0x9d, 0x72, 0x42, 0xa0,
+	0x30, 0xa2, 0x53, 0xe5, 0x88, 0x69, 0xe3, 0x6f, 0xab, 0x03, 0x25, 0x1d, 0xe5, 0x2a, 0x94, 0x90,
+	0x93, 0xf8, 0x5c, 0x4b, 0x56, 0x17, 0x1a, 0xbd, 0x33, 0xc6, 0x05, 0x4f, 0x3e, 0xbc, 0x0a, 0x25,
+	0x8a, 0x08, 0x6a, 0x56, 0x6c, 0x2d, 0x59, 0x3f, 0x18, 0x50, 0xeb, 0xbb, 0x8e, 0x1f, 0xc7, 0xbd,
+	0x02, 0x45, 0x2e, 0x9c, 0x48, 0xe8, 0x34, 0x28, 0x41, 0xd6, 0x15, 0x3d, 0x73, 0xbd, 0x09, 0x67,
+	0xa7, 0x74, 0x40, 0xfd, 0xa1, 0x4e, 0x88, 0x99, 0x80, 0x3d, 0x7f, 0x28, 0xaf, 0x7a, 0x6c, 0xcc,
+	0x04, 0x96, 0x6e, 0xc1, 0x56, 0x02, 0xf9, 0x00, 0xca, 0x81, 0x7a, 0x23, 0x98, 0xae, 0xda, 0xe6,
+	0x8d, 0x79, 0x32, 0x53, 0xcf, 0xc8, 0x8e, 0x75, 0x53, 0x49, 0x2e, 0xce, 0x24, 0xf9, 0x7b, 0x03,
+	0xc8, 0x96, 0x23, 0xdc, 0x13, 0x55, 0x8c, 0xb1, 0xdb, 0x6d, 0xa8, 0xa8, 0x82, 0x4d, 0xa8, 0x48,
+	0x64, 0xd2, 0x85, 0x26, 0xba, 0x32, 0x08, 0x69, 0x14, 0x97, 0x75, 0x4e, 0x91, 0x8e, 0xf8, 0x3e,
+	0x8d, 0x74, 0x65, 0xa7, 0x7c, 0xcd, 0xbf, 0x91, 0xaf, 0xb3, 0x05, 0xc9, 0xa1, 0x89, 0xae, 0x2e,
+	0xe0, 0x37, 0x7f, 0x29, 0xbf, 0xf9, 0x0c, 0xbf, 0xb7, 0xa1, 0x71, 0x1e, 0x07, 0x77, 0x1d, 0x5f,
+	0x13, 0x6d, 0xc6, 0x51, 0xc8, 0xef, 0x58, 0xdf, 0x19, 0x50, 0x9f, 0xe5, 0x66, 0x15, 0x4a, 0x3a,
+	0x6a, 0x95, 0x53, 0x2d, 0x9d, 0xe7, 0x2b, 0xb7, 0x20, 0x5f, 0xff, 0x04, 0x07, 0x3d, 0x7c, 0xe0,
+	0xb6, 0xe3, 0x1f, 0xd3, 0xbf, 0x51, 0x5b, 0xd6, 0x37, 0x60, 0xe2, 0xbb, 0x88, 0x63, 0x7a, 0xa0,
+	0x7b, 0x98, 0xb4, 0x8b, 0xe6, 0x2e, 0x7e, 0xa4, 0xf8, 0xdd, 0xa7, 0xd7, 0x54, 0x7f, 0x43, 0x1f,
+	0x5a, 0x09, 0x19, 0xf8, 0x99, 0xa7, 0xd7, 0x62, 0x3a, 0xb6, 0xca, 0x50, 0x44, 0x73, 0xd6, 0x1d,
+	0xa8, 0xeb, 0x6f, 0xe9, 0xb7, 0xb3, 0x02, 0xc5, 0xf4, 0x53, 0x55, 0x82, 0xf5, 0x21, 0xfc, 0xef,
+	0x51, 0x18, 0x46, 0xc1, 0x19, 0x1b, 0x3b, 0x02, 0xbb, 0x59, 0x72, 0x61, 0x0d, 0x40, 0x76, 0x3c,
+	0xdd, 0xa7, 0xd5, 0xad, 0xaa, 0x44, 0xb0, 0x4b, 0x5b, 0x21, 0x90, 0x1d, 0xea, 0x51, 0x41, 0xd1,
+	0xa5, 0x7f, 0x23, 0xa4, 0x25, 0xa8, 0xf7, 0xc6, 0xa1, 0x98, 0xc6, 0x1e, 0x5a, 0xff, 0x81, 0xe5,
+	0x67, 0x8c, 0x8b, 0xbe, 0x08, 0x22, 0xca, 0xb5, 0x07, 0xd6, 0x13, 0x20, 0x69, 0x50, 0x07, 0x73,
+	0x1f, 0x4a, 0x1c, 0x11, 0xdd, 0x0c, 0xaf, 0xcf, 0x3b, 0x85, 0xfa, 0xbb, 0xfe, 0x51, 0x60, 0x6b,
+	0x45, 0xeb, 0xa7, 0x1c, 0x54, 0x13, 0x54, 0xb6, 0x30, 0xdf, 0x19, 0xab, 0x98, 0xaa, 0x36, 0xfe,
+	0x96, 0xe5, 0x32, 0x8c, 0xd8, 0x29, 0x55, 0xb3, 0xb0, 0x6a, 0x6b, 0x89, 0xbc, 0x0f, 0xab, 0xce,
+	0x39, 0xa9, 0x83, 0x14, 0x8b, 0xaa, 0xd6, 0x57, 0x9c, 0x59, 0xca, 0xd5, 0xd8, 0x8b, 0xf9, 0x1e,
+	0xf9, 0xc1, 0x2b, 0x1f, 0x0b, 0xb0, 0xa2, 0xf8, 0xde, 0x93, 0x80, 0x3c, 0x8e, 0xa8, 0x33, 0x1c,
+	0xb8, 0x8e, 0xe7, 0x71, 0xec, 0x27, 0x05, 0xbb, 0x2a, 0x91, 0x6d, 0x09, 0xc8, 0xc1, 0xfb, 0x2a,
+	0x62, 0x82, 0xea, 0xf3, 0x12, 0x9e, 0x03, 0x42, 0x4a, 0x41, 0x0f, 0x4c, 0x55, 0x03, 0x65, 0x3c,
+	0x96, 0xec, 0x63, 0x91, 0x90, 0xbb, 0xb0, 0x94, 0x1c, 0x6a, 0x07, 0x2a, 0x6a, 0xbc, 0xc7, 0x2a,
+	0xca, 0x09, 0x0b, 0x4c, 0xd7, 0x09, 0x9d, 0x43, 0xe6, 0x31, 0xc1, 0x28, 0x6f, 0x55, 0x3b, 0xf9,
+	0x6e, 0xd5, 0x9e, 0xc1, 0xac, 0x77, 0xc1, 0xec, 0x0b, 0x47, 0xc4, 0x09, 0x91, 0x8e, 0x6b, 0xbb,
+	0x6a, 0x04, 0x60, 0x5c, 0x88, 0xc8, 0xd9, 0x60, 0xfd, 0x62, 0x40, 0x5d, 0xeb, 0xbf, 0x71, 0xae,
+	0xe4, 0x76, 0x32, 0x09, 0x05, 0x1b, 0xd3, 0x01, 0xa7, 0x6e, 0xe0, 0x0f, 0xb9, 0x6e, 0x07, 0x75,
+	0x85, 0xf6, 0x15, 0x28, 0xf7, 0x02, 0xc7, 0x15, 0xec, 0x34, 0x66, 0x49, 0xa5, 0xa3, 0xa6, 0xb0,
+	0x84, 0x26, 0xa4, 0x19, 0x77, 0x20, 0x95, 0x84, 0x8a, 0x04, 0x70, 0x09, 0x6a, 0x43, 0x65, 0x18,
+	0x39, 0xcc, 0x67, 0xfe, 0x31, 0x66, 0xa0, 0x62, 0x27, 0xb2, 0xd5, 0x84, 0xc6, 0x76, 0x30, 0x0e,
+	0x1d, 0x37, 0x7e, 0xde, 0x56, 0x03, 0xcc, 0xc7, 0x72, 0x88, 0xc6, 0xf2, 0x12, 0xd4, 0xb7, 0x1c,
+	0x77, 0x34, 0x09, 0x63, 0xe0, 0x39, 0x34, 0x6d, 0x1a, 0x7a, 0xcc, 0x75, 0x44, 0xf2, 0x80, 0xfe,
+	0x0b, 0x25, 0x2f, 0x38, 0x1e, 0xb0, 0xa1, 0xae, 0xb4, 0xa2, 0x17, 0x1c, 0xef, 0x0e, 0x65, 0x7f,
+	0x39, 0x8a, 0x82, 0xf1, 0x80, 0x4b, 0x35, 0xdf, 0xa5, 0x3a, 0x3e, 0x53, 0x82, 0x7d, 0x8d, 0x59,
+	0xbf, 0x19, 0xb0, 0x1c, 0x1b, 0x64, 0x81, 0xbf, 0x7d, 0x82, 0x2f, 0xeb, 0x23, 0x28, 0x8c, 0x98,
+	0xaf, 0xec, 0x35, 0x36, 0xef, 0x66, 0x1b, 0xe1, 0xdc, 0x85, 0xf5, 0x3d, 0xe6, 0x0f, 0x6d, 0xbc,
+	0x13, 0x6f, 0x33, 0xb9, 0x0b, 0xb6, 0x99, 0x7c, 0x7a, 0x9b, 0xc9, 0xb4, 0xbf, 0xc2, 0x05, 0xed,
+	0xef, 0x53, 0x28, 0x48, 0xd3, 0xa4, 0x0c, 0xf9, 0xfd, 0x2f, 0x0f, 0x9a, 0xd7, 0x08, 0x40, 0x69,
+	0xa7, 0xf7, 0xac, 0x77, 0xd0, 0x6b, 0x1a, 0xa4, 0x09, 0xa6, 0xfa, 0x3d, 0xb0, 0x1f, 0x3d, 0x7f,
+	0xd2, 0x6b, 0xe6, 0xc8, 0x32, 0xd4, 0x35, 0xb2, 0x6f, 0xf7, 0x1e, 0xef, 0x7e, 0xd5, 0xcc, 0x5b,
+	0x3f, 0x1a, 0xe7, 0x8c, 0xb1, 0xc0, 0xc7, 0xb9, 0xb4, 0x88, 0xb1, 0x36, 0x54, 0xe6, 0xc8, 0x4a,
+	0x64, 0xec, 0x85, 0x1e, 0x75, 0x22, 0x0c, 0xa2, 0x62, 0x2b, 0x01, 0x6f, 0xf8, 0x4e, 0xc8, 0x4f,
+	0x02, 0x11, 0x67, 0x3e, 0x96, 0xc9, 0xc7, 0x50, 0x76, 0x91, 0x1d, 0xf9, 0xf4, 0x64, 0x51, 0xde,
+	0xba, 0x92, 0x47, 0x3b, 0xbe, 0xb1, 0xf9, 0x6b, 0x19, 0x8a, 0xcf, 0xa9, 0xd8, 0x7b, 0x49, 0x76,
+	0xa0, 0xa2, 0xe6, 0xfe, 0x44, 0x90, 0xeb, 0x8b, 0xf6, 0x31, 0xde, 0x5e, 0x9b, 0x3f, 0x9a, 0xe9,
+	0x7b, 0xe4, 0x00, 0xaa, 0xc9, 0x52, 0x46, 0x3a, 0xd9, 0xd7, 0x31, 0xbb, 0x05, 0xb6, 0x6f, 0x5d,
+	0xa2, 0xa1, 0x2c, 0x76, 0x8d, 0x0d, 0x83, 0x3c, 0xd4, 0xbe, 0x3d, 0xa1, 0x82, 0xac, 0x5c, 0xe0,
+	0x1b, 0x6f, 0x2f, 0xdc, 0x20, 0x37, 0x0c, 0xb2, 0x0d, 0x35, 0xbc, 0xad, 0x36, 0xb6, 0x05, 0x06,
+	0x6e, 0x66, 0xe2, 0x9a, 0xdd, 0xef, 0x3e, 0x81, 0x82, 0x1c, 0xff, 0x24, 0x33, 0xad, 0x53, 0xcb,
+	0xc7, 0xa5, 0x5e, 0xec, 0x42, 0x35, 0x59, 0x56, 0xb2, 0xcc, 0xcc, 0xef, 0x31, 0x97, 0x9a, 0xda,
+	0xd2, 0x01, 0xa9, 0x21, 0xb7, 0x20, 0xa0, 0x2b, 0x12, 0xb5, 0x0d, 0x25, 0xbd, 0x94, 0x65, 0x14,
+	0x67, 0xb6, 0x9b, 0x4b, 0x1d, 0xf9, 0x5c, 0x3b, 0xa2, 0x2d, 0x59, 0x17, 0x46, 0xf5, 0xe7, 0xcd,
+	0xed, 0x40, 0x51, 0xf5, 0xfc, 0xff, 0xcf, 0x2b, 0xa5, 0x77, 0x93, 0xf6, 0xda, 0x82, 0xd3, 0xa4,
+	0x04, 0x97, 0xe6, 0xf6, 0x06, 0xb2, 0x70, 0xce, 0xb7, 0xef, 0xcd, 0x9f, 0x2c, 0x5a, 0x39, 0xf6,
+	0xa1, 0x96, 0xda, 0x29, 0xb2, 0xa1, 0x66, 0x17, 0x8e, 0xab, 0x32, 0xf0, 0x05, 0x54, 0x93, 0x16,
+	0x9b, 0x2d, 0x88, 0xf9, 0xee, 0xdb, 0xee, 0x5c, 0xf2, 0xaa, 0x91, 0xe7, 0x0d, 0x63, 0xf3, 0xf7,
+	0x1c, 0x00, 0xbe, 0xe6, 0x47, 0xc3, 0x31, 0xf3, 0x49, 0x1f, 0xe0, 0x7c, 0xdf, 0x20, 0x99, 0xb7,
+	0x96, 0x59, 0x50, 0xda, 0xd6, 0x65, 0x2a, 0xda, 0xed, 0x1d, 0x28, 0xe2, 0x4c, 0xcc, 0x26, 0x29,
+	0x3d, 0x5a, 0xdb, 0x6b, 0x0b, 0x4e, 0xb5, 0x95, 0xa7, 0x50, 0xd6, 0x23, 0x89, 0xdc, 0xcc, 0xa6,
+	0x33, 0x3d, 0xab, 0xae, 0xa2, 0x71, 0x07, 0x8a, 0x38, 0xca, 0xb2, 0xfe, 0xa4, 0x27, 0xdc, 0xd5,
+	0x56, 0x4a, 0x6a, 0x00, 0x66, 0x9f, 0xc3, 0xcc, 0x60, 0x6c, 0x2f, 0x6e, 0x8d, 0x1b, 0xc6, 0x56,
+	0xf5, 0xeb, 0x72, 0x78, 0x88, 0x27, 0x87, 0x25, 0xfc, 0x5b, 0xe3, 0xbd, 0x3f, 0x06, 0x00, 0x69,
+	0xff, 0xca, 0x1d, 0xe5, 0x10, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// NetKVClient is the client API for NetKV service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type NetKVClient interface {
+	BatchPut(ctx context.Context, in *KeyValues, opts ...grpc.CallOption) (*EmptyResponse, error)
+	// StreamPut writes the puts streamed by the client, the server flushes them to
+	// the store when requested or once enough are pending, acknowledging the puts
+	// committed so far after each flush. The client must not send more than it
+	// can afford to lose or resend before an acknowledgement.
+	StreamPut(ctx context.Context, opts ...grpc.CallOption) (NetKV_StreamPutClient, error)
+	// TODO: we need to be able to get individual responses
+	// regarding Not-Foundness of each key, otherwise, we'll have
+	// a hard time knowing which key was not found, etc..
+	// This will happen more with sparse trxdb.
+	BatchGet(ctx context.Context, in *Keys, opts ...grpc.CallOption) (NetKV_BatchGetClient, error)
+	// BatchExists checks the presence of keys without transferring their value.
+	BatchExists(ctx context.Context, in *Keys, opts ...grpc.CallOption) (*ExistsResponse, error)
+	Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (NetKV_ScanClient, error)
+	BatchScan(ctx context.Context, in *BatchScanRequest, opts ...grpc.CallOption) (NetKV_BatchScanClient, error)
+	BatchDelete(ctx context.Context, in *Keys, opts ...grpc.CallOption) (*EmptyResponse, error)
+	Prefix(ctx context.Context, in *PrefixRequest, opts ...grpc.CallOption) (NetKV_PrefixClient, error)
+	BatchPrefix(ctx context.Context, in *BatchPrefixRequest, opts ...grpc.CallOption) (NetKV_BatchPrefixClient, error)
+	// Count and ApproximateSize answer with `Unimplemented` when the served store
+	// cannot provide them.
+	Count(ctx context.Context, in *CountRequest, opts ...grpc.CallOption) (*CountResponse, error)
+	ApproximateSize(ctx context.Context, in *KeyRange, opts ...grpc.CallOption) (*ApproximateSizeResponse, error)
+	DeleteRange(ctx context.Context, in *DeleteRangeRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	// Replicate streams the changes made through the server to the store, for
+	// followers to replicate it. It answers with `FailedPrecondition` when the
+	// server does not keep a replication log.
+	Replicate(ctx context.Context, in *ReplicateRequest, opts ...grpc.CallOption) (NetKV_ReplicateClient, error)
+}
+
+type netKVClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewNetKVClient(cc *grpc.ClientConn) NetKVClient {
+	return &netKVClient{cc}
+}
+
+func (c *netKVClient) BatchPut(ctx context.Context, in *KeyValues, opts ...grpc.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	err := c.cc.Invoke(ctx, "/dfuse.netkv.v1.NetKV/BatchPut", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *netKVClient) StreamPut(ctx context.Context, opts ...grpc.CallOption) (NetKV_StreamPutClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_NetKV_serviceDesc.Streams[0], "/dfuse.netkv.v1.NetKV/StreamPut", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &netKVStreamPutClient{stream}
+	return x, nil
+}
+
+type NetKV_StreamPutClient interface {
+	Send(*StreamPutRequest) error
+	Recv() (*StreamPutResponse, error)
+	grpc.ClientStream
+}
+
+type netKVStreamPutClient struct {
+	grpc.ClientStream
+}
+
+func (x *netKVStreamPutClient) Send(m *StreamPutRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *netKVStreamPutClient) Recv() (*StreamPutResponse, error) {
+	m := new(StreamPutResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *netKVClient) BatchGet(ctx context.Context, in *Keys, opts ...grpc.CallOption) (NetKV_BatchGetClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_NetKV_serviceDesc.Streams[1], "/dfuse.netkv.v1.NetKV/BatchGet", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &netKVBatchGetClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
 	}
 	return x, nil
 }
@@ -611,8 +1879,17 @@ func (x *netKVBatchGetClient) Recv() (*KeyValue, error) {
 	return m, nil
 }
 
+func (c *netKVClient) BatchExists(ctx context.Context, in *Keys, opts ...grpc.CallOption) (*ExistsResponse, error) {
+	out := new(ExistsResponse)
+	err := c.cc.Invoke(ctx, "/dfuse.netkv.v1.NetKV/BatchExists", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *netKVClient) Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (NetKV_ScanClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_NetKV_serviceDesc.Streams[1], "/dfuse.netkv.v1.NetKV/Scan", opts...)
+	stream, err := c.cc.NewStream(ctx, &_NetKV_serviceDesc.Streams[2], "/dfuse.netkv.v1.NetKV/Scan", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -644,7 +1921,7 @@ func (x *netKVScanClient) Recv() (*KeyValue, error) {
 }
 
 func (c *netKVClient) BatchScan(ctx context.Context, in *BatchScanRequest, opts ...grpc.CallOption) (NetKV_BatchScanClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_NetKV_serviceDesc.Streams[2], "/dfuse.netkv.v1.NetKV/BatchScan", opts...)
+	stream, err := c.cc.NewStream(ctx, &_NetKV_serviceDesc.Streams[3], "/dfuse.netkv.v1.NetKV/BatchScan", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -685,7 +1962,7 @@ func (c *netKVClient) BatchDelete(ctx context.Context, in *Keys, opts ...grpc.Ca
 }
 
 func (c *netKVClient) Prefix(ctx context.Context, in *PrefixRequest, opts ...grpc.CallOption) (NetKV_PrefixClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_NetKV_serviceDesc.Streams[3], "/dfuse.netkv.v1.NetKV/Prefix", opts...)
+	stream, err := c.cc.NewStream(ctx, &_NetKV_serviceDesc.Streams[4], "/dfuse.netkv.v1.NetKV/Prefix", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -717,7 +1994,7 @@ func (x *netKVPrefixClient) Recv() (*KeyValue, error) {
 }
 
 func (c *netKVClient) BatchPrefix(ctx context.Context, in *BatchPrefixRequest, opts ...grpc.CallOption) (NetKV_BatchPrefixClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_NetKV_serviceDesc.Streams[4], "/dfuse.netkv.v1.NetKV/BatchPrefix", opts...)
+	stream, err := c.cc.NewStream(ctx, &_NetKV_serviceDesc.Streams[5], "/dfuse.netkv.v1.NetKV/BatchPrefix", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -748,19 +2025,94 @@ func (x *netKVBatchPrefixClient) Recv() (*KeyValue, error) {
 	return m, nil
 }
 
+func (c *netKVClient) Count(ctx context.Context, in *CountRequest, opts ...grpc.CallOption) (*CountResponse, error) {
+	out := new(CountResponse)
+	err := c.cc.Invoke(ctx, "/dfuse.netkv.v1.NetKV/Count", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *netKVClient) ApproximateSize(ctx context.Context, in *KeyRange, opts ...grpc.CallOption) (*ApproximateSizeResponse, error) {
+	out := new(ApproximateSizeResponse)
+	err := c.cc.Invoke(ctx, "/dfuse.netkv.v1.NetKV/ApproximateSize", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *netKVClient) DeleteRange(ctx context.Context, in *DeleteRangeRequest, opts ...grpc.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	err := c.cc.Invoke(ctx, "/dfuse.netkv.v1.NetKV/DeleteRange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *netKVClient) Replicate(ctx context.Context, in *ReplicateRequest, opts ...grpc.CallOption) (NetKV_ReplicateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_NetKV_serviceDesc.Streams[6], "/dfuse.netkv.v1.NetKV/Replicate", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &netKVReplicateClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type NetKV_ReplicateClient interface {
+	Recv() (*ReplicationBatch, error)
+	grpc.ClientStream
+}
+
+type netKVReplicateClient struct {
+	grpc.ClientStream
+}
+
+func (x *netKVReplicateClient) Recv() (*ReplicationBatch, error) {
+	m := new(ReplicationBatch)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // NetKVServer is the server API for NetKV service.
 type NetKVServer interface {
 	BatchPut(context.Context, *KeyValues) (*EmptyResponse, error)
+	// StreamPut writes the puts streamed by the client, the server flushes them to
+	// the store when requested or once enough are pending, acknowledging the puts
+	// committed so far after each flush. The client must not send more than it
+	// can afford to lose or resend before an acknowledgement.
+	StreamPut(NetKV_StreamPutServer) error
 	// TODO: we need to be able to get individual responses
 	// regarding Not-Foundness of each key, otherwise, we'll have
 	// a hard time knowing which key was not found, etc..
 	// This will happen more with sparse trxdb.
 	BatchGet(*Keys, NetKV_BatchGetServer) error
+	// BatchExists checks the presence of keys without transferring their value.
+	BatchExists(context.Context, *Keys) (*ExistsResponse, error)
 	Scan(*ScanRequest, NetKV_ScanServer) error
 	BatchScan(*BatchScanRequest, NetKV_BatchScanServer) error
 	BatchDelete(context.Context, *Keys) (*EmptyResponse, error)
 	Prefix(*PrefixRequest, NetKV_PrefixServer) error
 	BatchPrefix(*BatchPrefixRequest, NetKV_BatchPrefixServer) error
+	// Count and ApproximateSize answer with `Unimplemented` when the served store
+	// cannot provide them.
+	Count(context.Context, *CountRequest) (*CountResponse, error)
+	ApproximateSize(context.Context, *KeyRange) (*ApproximateSizeResponse, error)
+	DeleteRange(context.Context, *DeleteRangeRequest) (*EmptyResponse, error)
+	// Replicate streams the changes made through the server to the store, for
+	// followers to replicate it. It answers with `FailedPrecondition` when the
+	// server does not keep a replication log.
+	Replicate(*ReplicateRequest, NetKV_ReplicateServer) error
 }
 
 // UnimplementedNetKVServer can be embedded to have forward compatible implementations.
@@ -770,9 +2122,15 @@ type UnimplementedNetKVServer struct {
 func (*UnimplementedNetKVServer) BatchPut(ctx context.Context, req *KeyValues) (*EmptyResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BatchPut not implemented")
 }
+func (*UnimplementedNetKVServer) StreamPut(srv NetKV_StreamPutServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamPut not implemented")
+}
 func (*UnimplementedNetKVServer) BatchGet(req *Keys, srv NetKV_BatchGetServer) error {
 	return status.Errorf(codes.Unimplemented, "method BatchGet not implemented")
 }
+func (*UnimplementedNetKVServer) BatchExists(ctx context.Context, req *Keys) (*ExistsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchExists not implemented")
+}
 func (*UnimplementedNetKVServer) Scan(req *ScanRequest, srv NetKV_ScanServer) error {
 	return status.Errorf(codes.Unimplemented, "method Scan not implemented")
 }
@@ -788,6 +2146,18 @@ func (*UnimplementedNetKVServer) Prefix(req *PrefixRequest, srv NetKV_PrefixServ
 func (*UnimplementedNetKVServer) BatchPrefix(req *BatchPrefixRequest, srv NetKV_BatchPrefixServer) error {
 	return status.Errorf(codes.Unimplemented, "method BatchPrefix not implemented")
 }
+func (*UnimplementedNetKVServer) Count(ctx context.Context, req *CountRequest) (*CountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Count not implemented")
+}
+func (*UnimplementedNetKVServer) ApproximateSize(ctx context.Context, req *KeyRange) (*ApproximateSizeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproximateSize not implemented")
+}
+func (*UnimplementedNetKVServer) DeleteRange(ctx context.Context, req *DeleteRangeRequest) (*EmptyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRange not implemented")
+}
+func (*UnimplementedNetKVServer) Replicate(req *ReplicateRequest, srv NetKV_ReplicateServer) error {
+	return status.Errorf(codes.Unimplemented, "method Replicate not implemented")
+}
 
 func RegisterNetKVServer(s *grpc.Server, srv NetKVServer) {
 	s.RegisterService(&_NetKV_serviceDesc, srv)
@@ -811,6 +2181,32 @@ func _NetKV_BatchPut_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _NetKV_StreamPut_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(NetKVServer).StreamPut(&netKVStreamPutServer{stream})
+}
+
+type NetKV_StreamPutServer interface {
+	Send(*StreamPutResponse) error
+	Recv() (*StreamPutRequest, error)
+	grpc.ServerStream
+}
+
+type netKVStreamPutServer struct {
+	grpc.ServerStream
+}
+
+func (x *netKVStreamPutServer) Send(m *StreamPutResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *netKVStreamPutServer) Recv() (*StreamPutRequest, error) {
+	m := new(StreamPutRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _NetKV_BatchGet_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(Keys)
 	if err := stream.RecvMsg(m); err != nil {
@@ -832,6 +2228,24 @@ func (x *netKVBatchGetServer) Send(m *KeyValue) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _NetKV_BatchExists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Keys)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetKVServer).BatchExists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dfuse.netkv.v1.NetKV/BatchExists",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetKVServer).BatchExists(ctx, req.(*Keys))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _NetKV_Scan_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(ScanRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -934,6 +2348,81 @@ func (x *netKVBatchPrefixServer) Send(m *KeyValue) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _NetKV_Count_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetKVServer).Count(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dfuse.netkv.v1.NetKV/Count",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetKVServer).Count(ctx, req.(*CountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NetKV_ApproximateSize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyRange)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetKVServer).ApproximateSize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dfuse.netkv.v1.NetKV/ApproximateSize",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetKVServer).ApproximateSize(ctx, req.(*KeyRange))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NetKV_DeleteRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetKVServer).DeleteRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dfuse.netkv.v1.NetKV/DeleteRange",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetKVServer).DeleteRange(ctx, req.(*DeleteRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NetKV_Replicate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReplicateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NetKVServer).Replicate(m, &netKVReplicateServer{stream})
+}
+
+type NetKV_ReplicateServer interface {
+	Send(*ReplicationBatch) error
+	grpc.ServerStream
+}
+
+type netKVReplicateServer struct {
+	grpc.ServerStream
+}
+
+func (x *netKVReplicateServer) Send(m *ReplicationBatch) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _NetKV_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "dfuse.netkv.v1.NetKV",
 	HandlerType: (*NetKVServer)(nil),
@@ -942,12 +2431,34 @@ var _NetKV_serviceDesc = grpc.ServiceDesc{
 			MethodName: "BatchPut",
 			Handler:    _NetKV_BatchPut_Handler,
 		},
+		{
+			MethodName: "BatchExists",
+			Handler:    _NetKV_BatchExists_Handler,
+		},
 		{
 			MethodName: "BatchDelete",
 			Handler:    _NetKV_BatchDelete_Handler,
 		},
+		{
+			MethodName: "Count",
+			Handler:    _NetKV_Count_Handler,
+		},
+		{
+			MethodName: "ApproximateSize",
+			Handler:    _NetKV_ApproximateSize_Handler,
+		},
+		{
+			MethodName: "DeleteRange",
+			Handler:    _NetKV_DeleteRange_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamPut",
+			Handler:       _NetKV_StreamPut_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "BatchGet",
 			Handler:       _NetKV_BatchGet_Handler,
@@ -973,6 +2484,271 @@ var _NetKV_serviceDesc = grpc.ServiceDesc{
 			Handler:       _NetKV_BatchPrefix_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Replicate",
+			Handler:       _NetKV_Replicate_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "netkv.proto",
+}
+
+// NetKVAdminClient is the client API for NetKVAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type NetKVAdminClient interface {
+	// ListStores returns the stores hosted by the server that the caller can access.
+	ListStores(ctx context.Context, in *ListStoresRequest, opts ...grpc.CallOption) (*ListStoresResponse, error)
+	// Stats returns the statistics of the server and of the stores the caller can
+	// access.
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	// Compact reclaims the storage used by deleted and overwritten keys, it answers
+	// with `Unimplemented` when the store's driver cannot compact on demand.
+	Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	// Flush writes the puts buffered by the store to its backend.
+	Flush(ctx context.Context, in *FlushRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	// Backup streams all the keys of the store, in batches. Restoring them is a
+	// matter of putting them in an empty store.
+	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (NetKVAdmin_BackupClient, error)
+}
+
+type netKVAdminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewNetKVAdminClient(cc *grpc.ClientConn) NetKVAdminClient {
+	return &netKVAdminClient{cc}
+}
+
+func (c *netKVAdminClient) ListStores(ctx context.Context, in *ListStoresRequest, opts ...grpc.CallOption) (*ListStoresResponse, error) {
+	out := new(ListStoresResponse)
+	err := c.cc.Invoke(ctx, "/dfuse.netkv.v1.NetKVAdmin/ListStores", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *netKVAdminClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, "/dfuse.netkv.v1.NetKVAdmin/Stats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *netKVAdminClient) Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	err := c.cc.Invoke(ctx, "/dfuse.netkv.v1.NetKVAdmin/Compact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *netKVAdminClient) Flush(ctx context.Context, in *FlushRequest, opts ...grpc.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	err := c.cc.Invoke(ctx, "/dfuse.netkv.v1.NetKVAdmin/Flush", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *netKVAdminClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (NetKVAdmin_BackupClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_NetKVAdmin_serviceDesc.Streams[0], "/dfuse.netkv.v1.NetKVAdmin/Backup", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &netKVAdminBackupClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type NetKVAdmin_BackupClient interface {
+	Recv() (*KeyValues, error)
+	grpc.ClientStream
+}
+
+type netKVAdminBackupClient struct {
+	grpc.ClientStream
+}
+
+func (x *netKVAdminBackupClient) Recv() (*KeyValues, error) {
+	m := new(KeyValues)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NetKVAdminServer is the server API for NetKVAdmin service.
+type NetKVAdminServer interface {
+	// ListStores returns the stores hosted by the server that the caller can access.
+	ListStores(context.Context, *ListStoresRequest) (*ListStoresResponse, error)
+	// Stats returns the statistics of the server and of the stores the caller can
+	// access.
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	// Compact reclaims the storage used by deleted and overwritten keys, it answers
+	// with `Unimplemented` when the store's driver cannot compact on demand.
+	Compact(context.Context, *CompactRequest) (*EmptyResponse, error)
+	// Flush writes the puts buffered by the store to its backend.
+	Flush(context.Context, *FlushRequest) (*EmptyResponse, error)
+	// Backup streams all the keys of the store, in batches. Restoring them is a
+	// matter of putting them in an empty store.
+	Backup(*BackupRequest, NetKVAdmin_BackupServer) error
+}
+
+// UnimplementedNetKVAdminServer can be embedded to have forward compatible implementations.
+type UnimplementedNetKVAdminServer struct {
+}
+
+func (*UnimplementedNetKVAdminServer) ListStores(ctx context.Context, req *ListStoresRequest) (*ListStoresResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStores not implemented")
+}
+func (*UnimplementedNetKVAdminServer) Stats(ctx context.Context, req *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (*UnimplementedNetKVAdminServer) Compact(ctx context.Context, req *CompactRequest) (*EmptyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Compact not implemented")
+}
+func (*UnimplementedNetKVAdminServer) Flush(ctx context.Context, req *FlushRequest) (*EmptyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Flush not implemented")
+}
+func (*UnimplementedNetKVAdminServer) Backup(req *BackupRequest, srv NetKVAdmin_BackupServer) error {
+	return status.Errorf(codes.Unimplemented, "method Backup not implemented")
+}
+
+func RegisterNetKVAdminServer(s *grpc.Server, srv NetKVAdminServer) {
+	s.RegisterService(&_NetKVAdmin_serviceDesc, srv)
+}
+
+func _NetKVAdmin_ListStores_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStoresRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetKVAdminServer).ListStores(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dfuse.netkv.v1.NetKVAdmin/ListStores",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetKVAdminServer).ListStores(ctx, req.(*ListStoresRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NetKVAdmin_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetKVAdminServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dfuse.netkv.v1.NetKVAdmin/Stats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetKVAdminServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NetKVAdmin_Compact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetKVAdminServer).Compact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dfuse.netkv.v1.NetKVAdmin/Compact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetKVAdminServer).Compact(ctx, req.(*CompactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NetKVAdmin_Flush_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FlushRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetKVAdminServer).Flush(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dfuse.netkv.v1.NetKVAdmin/Flush",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetKVAdminServer).Flush(ctx, req.(*FlushRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NetKVAdmin_Backup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BackupRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NetKVAdminServer).Backup(m, &netKVAdminBackupServer{stream})
+}
+
+type NetKVAdmin_BackupServer interface {
+	Send(*KeyValues) error
+	grpc.ServerStream
+}
+
+type netKVAdminBackupServer struct {
+	grpc.ServerStream
+}
+
+func (x *netKVAdminBackupServer) Send(m *KeyValues) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _NetKVAdmin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dfuse.netkv.v1.NetKVAdmin",
+	HandlerType: (*NetKVAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListStores",
+			Handler:    _NetKVAdmin_ListStores_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _NetKVAdmin_Stats_Handler,
+		},
+		{
+			MethodName: "Compact",
+			Handler:    _NetKVAdmin_Compact_Handler,
+		},
+		{
+			MethodName: "Flush",
+			Handler:    _NetKVAdmin_Flush_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Backup",
+			Handler:       _NetKVAdmin_Backup_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "netkv.proto",
 }
@@ -0,0 +1,118 @@
+package netkv
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/streamingfast/kvdb/store"
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var followRetryDelay = 5 * time.Second
+
+// Follow replicates the store served by the server into `local` until the context is
+// canceled, the server must keep a replication log (`netkvserver.WithReplicationLog`).
+// Following starts with a snapshot of the served store replacing all keys of `local`,
+// interrupted replications are resumed where they stopped when possible. It only
+// returns when the context is canceled or the server rejects the replication.
+func (s *Store) Follow(ctx context.Context, local store.KVStore) error {
+	follower := &follower{client: s.client, local: local}
+
+	for {
+		err := follower.replicate(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		switch status.Code(err) {
+		case codes.FailedPrecondition, codes.PermissionDenied, codes.Unauthenticated, codes.InvalidArgument, codes.Unimplemented:
+			return err
+		}
+
+		zlog.Warn("replication interrupted, resuming", zap.Stringer("store", s), zap.Error(err), zap.Duration("retry_delay", followRetryDelay))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(followRetryDelay):
+		}
+	}
+}
+
+type follower struct {
+	client pbnetkv.NetKVClient
+	local  store.KVStore
+
+	// logID and sequence are the position of `local` in the server change log, an
+	// empty log id means it must start over from a snapshot.
+	logID    string
+	sequence uint64
+}
+
+func (f *follower) replicate(ctx context.Context) error {
+	stream, err := f.client.Replicate(ctx, &pbnetkv.ReplicateRequest{LogId: f.logID, FromSequence: f.sequence})
+	if err != nil {
+		return err
+	}
+
+	for {
+		batch, err := stream.Recv()
+		if err == io.EOF {
+			return fmt.Errorf("server ended the replication stream")
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := f.apply(ctx, batch); err != nil {
+			return fmt.Errorf("apply replication batch: %w", err)
+		}
+	}
+}
+
+func (f *follower) apply(ctx context.Context, batch *pbnetkv.ReplicationBatch) error {
+	if batch.Clear {
+		f.logID = ""
+		if err := store.DeletePrefix(ctx, f.local, nil); err != nil {
+			return fmt.Errorf("clear store: %w", err)
+		}
+	}
+
+	for _, change := range batch.Changes {
+		var err error
+		switch change.Kind {
+		case pbnetkv.ReplicationChange_PUT:
+			err = f.local.Put(ctx, change.Key, change.Value)
+		case pbnetkv.ReplicationChange_DELETE:
+			err = store.Delete(ctx, f.local, change.Key)
+		case pbnetkv.ReplicationChange_DELETE_RANGE:
+			// Range deletions do not discard pending puts
+			if err = f.local.FlushPuts(ctx); err == nil {
+				err = store.DeleteRange(ctx, f.local, change.Key, change.ExclusiveEnd)
+			}
+		case pbnetkv.ReplicationChange_DELETE_PREFIX:
+			if err = f.local.FlushPuts(ctx); err == nil {
+				err = store.DeletePrefix(ctx, f.local, change.Key)
+			}
+		default:
+			err = fmt.Errorf("unknown change kind %s", change.Kind)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := f.local.FlushPuts(ctx); err != nil {
+		return err
+	}
+
+	if !batch.Snapshot {
+		f.logID, f.sequence = batch.LogId, batch.Sequence
+	}
+	return nil
+}
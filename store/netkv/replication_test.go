@@ -0,0 +1,120 @@
+package netkv
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/streamingfast/kvdb/store"
+	netkvserver "github.com/streamingfast/kvdb/store/netkv/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFollow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leaderServer := launchTestServer(t, netkvserver.WithReplicationLog(0))
+	followerServer := launchTestServer(t, netkvserver.WithReadOnly())
+	leader, follower := openClient(t, leaderServer, "insecure=true"), openClient(t, followerServer, "insecure=true")
+
+	put := func(keys ...string) {
+		for _, key := range keys {
+			require.NoError(t, leader.Put(ctx, []byte(key), []byte("v"+key)))
+		}
+		require.NoError(t, leader.FlushPuts(ctx))
+	}
+
+	followerKeys := func() (keys []string) {
+		kvs, err := store.Collect(follower.Prefix(ctx, nil, store.Unlimited))
+		require.NoError(t, err)
+		for _, kv := range kvs {
+			keys = append(keys, string(kv.Key))
+		}
+		return keys
+	}
+
+	local := followerServer.Store(netkvserver.DefaultStoreName)
+	require.NoError(t, local.Put(ctx, []byte("stale"), []byte("1")))
+	require.NoError(t, local.FlushPuts(ctx))
+
+	put("a1", "a2")
+
+	followed := make(chan error, 1)
+	go func() { followed <- leader.(*Store).Follow(ctx, local) }()
+
+	assert.Eventually(t, func() bool { return assert.ObjectsAreEqual([]string{"a1", "a2"}, followerKeys()) }, 5*time.Second, 10*time.Millisecond, "snapshot replaces follower keys")
+
+	put("b1", "b2")
+	require.NoError(t, store.Delete(ctx, leader, []byte("a1")))
+	require.NoError(t, store.DeletePrefix(ctx, leader, []byte("a")))
+	put("c1")
+
+	assert.Eventually(t, func() bool { return assert.ObjectsAreEqual([]string{"b1", "b2", "c1"}, followerKeys()) }, 5*time.Second, 10*time.Millisecond, "changes are replicated")
+
+	value, err := follower.Get(ctx, []byte("c1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("vc1"), value)
+
+	require.NoError(t, follower.Put(ctx, []byte("d"), []byte("1")))
+	assert.Equal(t, codes.PermissionDenied, status.Code(follower.FlushPuts(ctx)), "follower is read only")
+
+	cancel()
+	select {
+	case err := <-followed:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("follow did not return on cancel")
+	}
+}
+
+func TestFollow_ConcurrentWriters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leaderServer := launchTestServer(t, netkvserver.WithReplicationLog(0))
+
+	follower, err := store.New(fmt.Sprintf("badger://%s", filepath.Join(t.TempDir(), "follower")))
+	require.NoError(t, err)
+	defer follower.Close()
+
+	leader := openClient(t, leaderServer, "insecure=true")
+
+	go leader.(*Store).Follow(ctx, follower)
+
+	// Writers race on the same key, the follower must end with the value the leader kept
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		writer := openClient(t, leaderServer, "insecure=true")
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				assert.NoError(t, writer.Put(ctx, []byte("k"), []byte(fmt.Sprintf("w%d-%d", i, j))))
+				assert.NoError(t, writer.FlushPuts(ctx))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	expected, err := leader.Get(ctx, []byte("k"))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		value, err := follower.Get(ctx, []byte("k"))
+		return err == nil && string(value) == string(expected)
+	}, 5*time.Second, 10*time.Millisecond, "follower has the last value written by the leader")
+}
+
+func TestFollow_NoReplicationLog(t *testing.T) {
+	kvStore := openClient(t, launchTestServer(t), "insecure=true")
+
+	err := kvStore.(*Store).Follow(context.Background(), store.KVStore(nil))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
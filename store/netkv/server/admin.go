@@ -0,0 +1,107 @@
+package netkvserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/streamingfast/kvdb/store"
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// backupBatchBytes is the bytes of keys and values above which `Backup` sends a batch
+const backupBatchBytes = 1024 * 1024
+
+func (s *Server) Stats(ctx context.Context, req *pbnetkv.StatsRequest) (*pbnetkv.StatsResponse, error) {
+	resp := &pbnetkv.StatsResponse{
+		UptimeSeconds: uint64(time.Since(s.startedAt).Seconds()),
+		ActiveCalls:   s.calls.active(),
+		ReadOnly:      s.readOnly,
+		Draining:      s.calls.isDraining(),
+	}
+
+	for _, name := range s.accessibleStores(ctx) {
+		info, err := s.storeInfo(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.CountKeys {
+			count, err := store.CountPrefix(ctx, s.stores[name].KVStore, nil)
+			if err != nil {
+				return nil, fmt.Errorf("count keys of store %q: %w", name, err)
+			}
+			info.KeyCount, info.KeyCountKnown = count, true
+		}
+
+		resp.Stores = append(resp.Stores, info)
+	}
+
+	return resp, nil
+}
+
+func (s *Server) Compact(ctx context.Context, req *pbnetkv.CompactRequest) (*pbnetkv.EmptyResponse, error) {
+	kvStore, err := s.storeFor(ctx, PermissionAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.Compact(ctx, kvStore); err != nil {
+		if errors.Is(err, store.ErrNotSupported) {
+			return nil, status.Error(codes.Unimplemented, "store driver cannot compact on demand")
+		}
+		return nil, err
+	}
+
+	return &pbnetkv.EmptyResponse{}, nil
+}
+
+func (s *Server) Flush(ctx context.Context, req *pbnetkv.FlushRequest) (*pbnetkv.EmptyResponse, error) {
+	kvStore, err := s.storeFor(ctx, PermissionAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := kvStore.FlushPuts(ctx); err != nil {
+		return nil, err
+	}
+
+	return &pbnetkv.EmptyResponse{}, nil
+}
+
+func (s *Server) Backup(req *pbnetkv.BackupRequest, stream pbnetkv.NetKVAdmin_BackupServer) error {
+	kvStore, err := s.storeFor(stream.Context(), PermissionAdmin)
+	if err != nil {
+		return err
+	}
+
+	batch := &pbnetkv.KeyValues{}
+	batchSize := 0
+
+	it := kvStore.Prefix(stream.Context(), nil, store.Unlimited)
+	for it.Next() {
+		item := it.Item()
+		batch.Kvs = append(batch.Kvs, &pbnetkv.KeyValue{Key: item.Key, Value: item.Value})
+		batchSize += len(item.Key) + len(item.Value)
+
+		if batchSize >= backupBatchBytes {
+			if err := stream.Send(batch); err != nil {
+				return err
+			}
+
+			batch = &pbnetkv.KeyValues{}
+			batchSize = 0
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if len(batch.Kvs) == 0 {
+		return nil
+	}
+	return stream.Send(batch)
+}
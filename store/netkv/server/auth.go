@@ -0,0 +1,424 @@
+package netkvserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/streamingfast/kvdb/store"
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type Permission uint8
+
+const (
+	PermissionRead Permission = 1 << iota
+	PermissionWrite
+	// PermissionAdmin grants the NetKVAdmin calls acting on stores, like `Compact` or
+	// `Backup`, on all the keys of the stores the ACL allows.
+	PermissionAdmin
+)
+
+// ACL restricts what the holder of a token can do on the served store.
+type ACL struct {
+	// Name identifies the token holder in errors and logs.
+	Name        string
+	Permissions Permission
+	// Prefixes are the key prefixes the token holder can access, all keys when empty.
+	Prefixes [][]byte
+	// Stores are the names of the hosted stores the token holder can access, all stores
+	// when empty.
+	Stores []string
+}
+
+// AllowsStore returns whether the store is one of the ACL stores.
+func (a *ACL) AllowsStore(name string) bool {
+	if len(a.Stores) == 0 {
+		return true
+	}
+
+	for _, store := range a.Stores {
+		if store == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows returns whether the ACL grants the permission.
+func (a *ACL) Allows(permission Permission) bool {
+	return a.Permissions&permission == permission
+}
+
+// AllowsKey returns whether the key starts with one of the ACL prefixes.
+func (a *ACL) AllowsKey(key []byte) bool {
+	if len(a.Prefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range a.Prefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsRange returns whether all the keys of the range start with the same ACL
+// prefix, an empty `exclusiveEnd` meaning the range is not bounded.
+func (a *ACL) AllowsRange(start, exclusiveEnd []byte) bool {
+	if len(a.Prefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range a.Prefixes {
+		if !bytes.HasPrefix(start, prefix) {
+			continue
+		}
+
+		prefixEnd := prefixExclusiveEnd(prefix)
+		if prefixEnd == nil || (len(exclusiveEnd) > 0 && bytes.Compare(exclusiveEnd, prefixEnd) <= 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixExclusiveEnd returns the smallest key greater than all keys starting with
+// prefix, or nil if there is no such key (empty prefix or prefix made only of 0xFF bytes).
+func prefixExclusiveEnd(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xFF {
+			end := make([]byte, i+1)
+			copy(end, prefix)
+			end[i]++
+			return end
+		}
+	}
+
+	return nil
+}
+
+// TokenVerifier authenticates the bearer tokens sent by clients, returning the ACL
+// granted to the token holder. An invalid token must be reported by returning an error.
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) (*ACL, error)
+}
+
+type TokenVerifierFunc func(ctx context.Context, token string) (*ACL, error)
+
+func (f TokenVerifierFunc) VerifyToken(ctx context.Context, token string) (*ACL, error) {
+	return f(ctx, token)
+}
+
+// StaticTokens is a `TokenVerifier` granting a fixed ACL to each known token.
+type StaticTokens map[string]*ACL
+
+func (t StaticTokens) VerifyToken(ctx context.Context, token string) (*ACL, error) {
+	var found *ACL
+	for candidate, acl := range t {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			found = acl
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("unknown token")
+	}
+	return found, nil
+}
+
+type tokenEntry struct {
+	Name        string   `json:"name"`
+	Token       string   `json:"token"`
+	Permissions []string `json:"permissions"`
+	Prefixes    []string `json:"prefixes"`
+	Stores      []string `json:"stores"`
+}
+
+// LoadTokensFile reads `StaticTokens` from a JSON file holding a list of entries of
+// the form `{"name": "indexer", "token": "secret", "permissions": ["read", "write"], "prefixes": ["0a"], "stores": ["blocks"]}`,
+// prefixes being hex encoded. Accepted permissions are "read", "write" and "admin".
+func LoadTokensFile(path string) (StaticTokens, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tokens file: %w", err)
+	}
+
+	var entries []*tokenEntry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("parse tokens file %q: %w", path, err)
+	}
+
+	tokens := StaticTokens{}
+	for i, entry := range entries {
+		if entry.Token == "" {
+			return nil, fmt.Errorf("tokens file %q: entry #%d has no token", path, i)
+		}
+		if _, found := tokens[entry.Token]; found {
+			return nil, fmt.Errorf("tokens file %q: entry #%d token is used more than once", path, i)
+		}
+
+		acl := &ACL{Name: entry.Name, Stores: entry.Stores}
+		for _, permission := range entry.Permissions {
+			switch permission {
+			case "read":
+				acl.Permissions |= PermissionRead
+			case "write":
+				acl.Permissions |= PermissionWrite
+			case "admin":
+				acl.Permissions |= PermissionAdmin
+			default:
+				return nil, fmt.Errorf("tokens file %q: entry #%d has unknown permission %q, accepted values are \"read\", \"write\" and \"admin\"", path, i, permission)
+			}
+		}
+
+		for _, prefix := range entry.Prefixes {
+			decoded, err := hex.DecodeString(prefix)
+			if err != nil {
+				return nil, fmt.Errorf("tokens file %q: entry #%d prefix %q is not valid hex: %w", path, i, prefix, err)
+			}
+			acl.Prefixes = append(acl.Prefixes, decoded)
+		}
+
+		tokens[entry.Token] = acl
+	}
+
+	return tokens, nil
+}
+
+type aclKey struct{}
+
+// ACLFromContext returns the ACL of the client making the call, nil when the server
+// does not authenticate clients.
+func ACLFromContext(ctx context.Context) *ACL {
+	acl, _ := ctx.Value(aclKey{}).(*ACL)
+	return acl
+}
+
+const (
+	netKVServicePrefix      = "/dfuse.netkv.v1.NetKV/"
+	netKVAdminServicePrefix = "/dfuse.netkv.v1.NetKVAdmin/"
+	// healthServicePrefix is the standard gRPC health service, reachable without token
+	// for load balancers and orchestrators to probe the server
+	healthServicePrefix = "/grpc.health.v1.Health/"
+)
+
+// servicePermissions are the permissions required by each method of the NetKV services,
+// other services only require the client to be authenticated.
+var servicePermissions = map[string]map[string]Permission{
+	netKVServicePrefix:      methodPermissions,
+	netKVAdminServicePrefix: adminMethodPermissions,
+}
+
+var methodPermissions = map[string]Permission{
+	"BatchPut":        PermissionWrite,
+	"StreamPut":       PermissionWrite,
+	"BatchGet":        PermissionRead,
+	"BatchExists":     PermissionRead,
+	"Scan":            PermissionRead,
+	"BatchScan":       PermissionRead,
+	"BatchDelete":     PermissionWrite,
+	"Prefix":          PermissionRead,
+	"BatchPrefix":     PermissionRead,
+	"Count":           PermissionRead,
+	"ApproximateSize": PermissionRead,
+	"DeleteRange":     PermissionWrite,
+	"Replicate":       PermissionRead,
+}
+
+var adminMethodPermissions = map[string]Permission{
+	"ListStores": 0,
+	"Stats":      PermissionAdmin,
+	"Compact":    PermissionAdmin,
+	"Flush":      PermissionAdmin,
+	"Backup":     PermissionAdmin,
+}
+
+type authenticator struct {
+	verifier TokenVerifier
+}
+
+// authenticate verifies the bearer token of the call and checks that its ACL grants
+// the permission required by the method.
+func (a *authenticator) authenticate(ctx context.Context, fullMethod string) (context.Context, *ACL, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+
+	token, found := cutPrefixFold(values[0], "bearer ")
+	if !found {
+		return nil, nil, status.Error(codes.Unauthenticated, "authorization is not a bearer token")
+	}
+
+	acl, err := a.verifier.VerifyToken(ctx, strings.TrimSpace(token))
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Unauthenticated, "invalid bearer token: %s", err)
+	}
+
+	split := strings.LastIndex(fullMethod, "/") + 1
+	service, method := fullMethod[:split], fullMethod[split:]
+	if permissions, found := servicePermissions[service]; found {
+		permission, known := permissions[method]
+		if !known || !acl.Allows(permission) {
+			return nil, nil, status.Errorf(codes.PermissionDenied, "token %q is not allowed to call %s", acl.Name, method)
+		}
+	}
+
+	return context.WithValue(ctx, aclKey{}, acl), acl, nil
+}
+
+func (a *authenticator) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+		return handler(ctx, req)
+	}
+
+	ctx, acl, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(info.FullMethod, netKVServicePrefix) {
+		if err := authorizeRequest(acl, req); err != nil {
+			return nil, err
+		}
+	}
+
+	return handler(ctx, req)
+}
+
+func (a *authenticator) streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+		return handler(srv, stream)
+	}
+
+	ctx, acl, err := a.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(info.FullMethod, netKVServicePrefix) {
+		acl = nil
+	}
+
+	return handler(srv, &authorizedStream{ServerStream: stream, ctx: ctx, acl: acl})
+}
+
+// authorizedStream checks the keys of each message received against the ACL of the client.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	// acl is nil for methods outside of the NetKV service, their messages are not checked
+	acl *ACL
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authorizedStream) RecvMsg(msg interface{}) error {
+	if err := s.ServerStream.RecvMsg(msg); err != nil || s.acl == nil {
+		return err
+	}
+
+	return authorizeRequest(s.acl, msg)
+}
+
+// authorizeRequest checks that all the keys the request can touch are allowed by the ACL
+// prefixes, requests of unknown types are rejected when the ACL restricts prefixes.
+func authorizeRequest(acl *ACL, req interface{}) error {
+	if len(acl.Prefixes) == 0 {
+		return nil
+	}
+
+	allowed := true
+	switch r := req.(type) {
+	case *pbnetkv.KeyValues:
+		for _, kv := range r.Kvs {
+			allowed = allowed && acl.AllowsKey(kv.Key)
+		}
+	case *pbnetkv.StreamPutRequest:
+		for _, kv := range r.Kvs {
+			allowed = allowed && acl.AllowsKey(kv.Key)
+		}
+	case *pbnetkv.Keys:
+		for _, key := range r.Keys {
+			allowed = allowed && acl.AllowsKey(key)
+		}
+	case *pbnetkv.KeyRange:
+		allowed = acl.AllowsRange(r.Start, r.ExclusiveEnd)
+	case *pbnetkv.ScanRequest:
+		allowed = acl.AllowsRange(r.Start, r.ExclusiveEnd)
+		if len(r.Cursor) > 0 {
+			allowed = cursorAllowed(acl, r.Cursor)
+		}
+	case *pbnetkv.BatchScanRequest:
+		if len(r.Start) != len(r.ExclusiveEnd) {
+			allowed = false
+		}
+		for i := 0; allowed && i < len(r.Start); i++ {
+			allowed = acl.AllowsRange(r.Start[i], r.ExclusiveEnd[i])
+		}
+	case *pbnetkv.PrefixRequest:
+		allowed = acl.AllowsKey(r.Prefix)
+		if len(r.Cursor) > 0 {
+			allowed = cursorAllowed(acl, r.Cursor)
+		}
+	case *pbnetkv.BatchPrefixRequest:
+		for _, prefix := range r.Prefixes {
+			allowed = allowed && acl.AllowsKey(prefix)
+		}
+		if len(r.Cursor) > 0 {
+			allowed = cursorAllowed(acl, r.Cursor)
+		}
+	case *pbnetkv.CountRequest:
+		switch countRange := r.Range.(type) {
+		case *pbnetkv.CountRequest_KeyRange:
+			allowed = acl.AllowsRange(countRange.KeyRange.Start, countRange.KeyRange.ExclusiveEnd)
+		case *pbnetkv.CountRequest_Prefix:
+			allowed = acl.AllowsKey(countRange.Prefix)
+		}
+	case *pbnetkv.DeleteRangeRequest:
+		switch deleteRange := r.Range.(type) {
+		case *pbnetkv.DeleteRangeRequest_KeyRange:
+			allowed = acl.AllowsRange(deleteRange.KeyRange.Start, deleteRange.KeyRange.ExclusiveEnd)
+		case *pbnetkv.DeleteRangeRequest_Prefix:
+			allowed = acl.AllowsKey(deleteRange.Prefix)
+		}
+	default:
+		allowed = false
+	}
+
+	if !allowed {
+		return status.Errorf(codes.PermissionDenied, "token %q is not allowed to access keys outside of its prefixes", acl.Name)
+	}
+	return nil
+}
+
+func cursorAllowed(acl *ACL, rawCursor []byte) bool {
+	cursor, err := store.ParseCursor(rawCursor)
+	if err != nil {
+		// Invalid cursors are reported by the method itself
+		return true
+	}
+
+	return acl.AllowsRange(cursor.KeyRange())
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
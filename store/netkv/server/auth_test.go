@@ -0,0 +1,107 @@
+package netkvserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/streamingfast/kvdb/store"
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACL_AllowsRange(t *testing.T) {
+	acl := &ACL{Prefixes: [][]byte{[]byte("ab"), {0xFF}}}
+
+	tests := []struct {
+		start, end string
+		expect     bool
+	}{
+		{"ab", "ac", true},
+		{"ab1", "ab2", true},
+		{"ab", "ad", false},
+		{"aa", "ab", false},
+		{"ab", "", false},
+		{"\xFF", "", true},
+		{"\xFF\x01", "", true},
+		{"\xFE", "", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expect, acl.AllowsRange([]byte(test.start), []byte(test.end)), "%q-%q", test.start, test.end)
+	}
+
+	assert.True(t, (&ACL{}).AllowsRange(nil, nil), "no prefixes allows all keys")
+}
+
+func TestAuthorizeRequest(t *testing.T) {
+	acl := &ACL{Name: "test", Prefixes: [][]byte{[]byte("a")}}
+
+	cursor := func(prefix string) []byte {
+		it := store.NewPrefixIterator(context.Background(), []byte(prefix), store.Unlimited, nil)
+		it.PushItem(store.KV{Key: []byte(prefix + "1")})
+		it.PushFinished()
+		require.True(t, it.Next())
+		return it.Cursor().Bytes()
+	}
+
+	tests := []struct {
+		name   string
+		req    interface{}
+		expect bool
+	}{
+		{"keys allowed", &pbnetkv.Keys{Keys: [][]byte{[]byte("a1"), []byte("a2")}}, true},
+		{"keys denied", &pbnetkv.Keys{Keys: [][]byte{[]byte("a1"), []byte("b2")}}, false},
+		{"prefix allowed", &pbnetkv.PrefixRequest{Prefix: []byte("a1")}, true},
+		{"prefix denied", &pbnetkv.PrefixRequest{Prefix: []byte("")}, false},
+		{"cursor allowed", &pbnetkv.PrefixRequest{Cursor: cursor("a")}, true},
+		{"cursor denied", &pbnetkv.ScanRequest{Start: []byte("a"), Cursor: cursor("b")}, false},
+		{"delete prefix denied", &pbnetkv.DeleteRangeRequest{Range: &pbnetkv.DeleteRangeRequest_Prefix{Prefix: []byte("b")}}, false},
+		{"count range allowed", &pbnetkv.CountRequest{Range: &pbnetkv.CountRequest_KeyRange{KeyRange: &pbnetkv.KeyRange{Start: []byte("a"), ExclusiveEnd: []byte("b")}}}, true},
+		{"unknown request", &pbnetkv.EmptyResponse{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := authorizeRequest(acl, test.req)
+			if test.expect {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestLoadTokensFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"name": "indexer", "token": "secret", "permissions": ["read", "write"], "prefixes": ["0a"]},
+		{"name": "reader", "token": "other", "permissions": ["read"]}
+	]`), 0600))
+
+	tokens, err := LoadTokensFile(path)
+	require.NoError(t, err)
+
+	acl, err := tokens.VerifyToken(context.Background(), "secret")
+	require.NoError(t, err)
+	assert.Equal(t, &ACL{Name: "indexer", Permissions: PermissionRead | PermissionWrite, Prefixes: [][]byte{{0x0a}}}, acl)
+
+	acl, err = tokens.VerifyToken(context.Background(), "other")
+	require.NoError(t, err)
+	assert.False(t, acl.Allows(PermissionWrite))
+
+	_, err = tokens.VerifyToken(context.Background(), "unknown")
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(`[{"token": "secret", "permissions": ["admin"]}]`), 0600))
+	tokens, err = LoadTokensFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, PermissionAdmin, tokens["secret"].Permissions)
+
+	require.NoError(t, os.WriteFile(path, []byte(`[{"token": "secret", "permissions": ["owner"]}]`), 0600))
+	_, err = LoadTokensFile(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+package netkvserver
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// callTracker counts the NetKV and NetKVAdmin calls in progress, so that the server
+// can be drained before it's closed.
+type callTracker struct {
+	lock     sync.Mutex
+	count    uint64
+	draining bool
+	// drained is closed when draining starts
+	drained chan struct{}
+	// idle is closed once draining and no call is in progress anymore
+	idle chan struct{}
+}
+
+func newCallTracker() *callTracker {
+	return &callTracker{drained: make(chan struct{}), idle: make(chan struct{})}
+}
+
+// start counts a new call, false when the server is draining and the call must be rejected.
+func (t *callTracker) start() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.draining {
+		return false
+	}
+
+	t.count++
+	return true
+}
+
+func (t *callTracker) done() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.count--
+	if t.draining && t.count == 0 {
+		close(t.idle)
+	}
+}
+
+// drain rejects new calls, returning a channel closed once the calls in progress are done.
+func (t *callTracker) drain() <-chan struct{} {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !t.draining {
+		t.draining = true
+		close(t.drained)
+		if t.count == 0 {
+			close(t.idle)
+		}
+	}
+
+	return t.idle
+}
+
+func (t *callTracker) active() uint64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.count
+}
+
+func (t *callTracker) isDraining() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.draining
+}
+
+// Drain prepares the server to stop: health checks report it as not serving, new calls
+// are rejected with `Unavailable` and replication streams are ended. It returns once
+// the calls in progress are done, or with the context error if it's done before.
+// Connections are kept open, `Close` must still be called.
+func (s *Server) Drain(ctx context.Context) error {
+	s.health.Shutdown()
+
+	select {
+	case <-s.calls.drain():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tracked returns whether the method's calls are tracked, the other services, like
+// health checks, keep being served while the server drains.
+func tracked(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, netKVServicePrefix) || strings.HasPrefix(fullMethod, netKVAdminServicePrefix)
+}
+
+// trackCall counts the call until the returned function is called, the call's context
+// being canceled when the server is closed.
+func (s *Server) trackCall(ctx context.Context) (context.Context, func(), error) {
+	if !s.calls.start() {
+		return nil, nil, status.Error(codes.Unavailable, "server is draining")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(s.closing, cancel)
+
+	return ctx, func() {
+		stop()
+		cancel()
+		s.calls.done()
+	}, nil
+}
+
+func (s *Server) trackingUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !tracked(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	ctx, done, err := s.trackCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	return handler(ctx, req)
+}
+
+func (s *Server) trackingStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !tracked(info.FullMethod) {
+		return handler(srv, stream)
+	}
+
+	ctx, done, err := s.trackCall(stream.Context())
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return handler(srv, &trackedStream{ServerStream: stream, ctx: ctx})
+}
+
+type trackedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *trackedStream) Context() context.Context {
+	return s.ctx
+}
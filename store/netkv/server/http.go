@@ -0,0 +1,382 @@
+package netkvserver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/streamingfast/kvdb/store"
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// httpGateway exposes the NetKV reads and writes over HTTP, each route being served by
+// the NetKV method it mirrors so that authentication, ACLs, read-only mode, draining and
+// replication apply the same way.
+type httpGateway struct {
+	server *Server
+}
+
+// HTTPHandler returns the HTTP/JSON gateway to the hosted stores, served by
+// `WithHTTPGateway`. Routes are:
+//
+//   - `GET /kv/{key}` returning `{"key": ..., "value": ...}`, or the raw value with `raw=true`
+//   - `PUT /kv/{key}` storing the request body as the value, at most `WithMaxRecvMsgBytes` bytes
+//   - `DELETE /kv/{key}`
+//   - `GET /scan?start=&end=&limit=` streaming the keys in [start, end)
+//   - `GET /prefix/{prefix}?limit=` streaming the keys starting with prefix
+//
+// Keys, and values in responses, are hex encoded unless `encoding=base64` is provided
+// (URL-safe, without padding). Streamed responses are newline delimited JSON objects,
+// `key_only=true` omits values and `include_cursor=true` adds a `cursor` to the last
+// object, passed back with `cursor=` to continue the read. An error occurring once
+// streaming started is reported as a last `{"error": ...}` object. All routes accept
+// `store=` to select a hosted store and a bearer token in the `Authorization` header.
+func (s *Server) HTTPHandler() http.Handler {
+	gateway := &httpGateway{server: s}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /kv/{key}", gateway.get)
+	mux.HandleFunc("PUT /kv/{key}", gateway.put)
+	mux.HandleFunc("DELETE /kv/{key}", gateway.delete)
+	mux.HandleFunc("GET /scan", gateway.scan)
+	mux.HandleFunc("GET /prefix/{prefix...}", gateway.prefix)
+
+	return mux
+}
+
+type httpKeyValue struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+type httpError struct {
+	Error string `json:"error"`
+}
+
+func (g *httpGateway) get(w http.ResponseWriter, r *http.Request) {
+	key, ok := decodeHTTPKey(w, r, r.PathValue("key"))
+	if !ok {
+		return
+	}
+
+	ctx, done, ok := g.call(w, r, "BatchGet", &pbnetkv.Keys{Keys: [][]byte{key}})
+	if !ok {
+		return
+	}
+	defer done()
+
+	kvStore, err := g.server.storeFor(ctx, PermissionRead)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	value, err := kvStore.Get(ctx, key)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	if r.URL.Query().Get("raw") == "true" {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(value)
+		return
+	}
+
+	encode := httpEncoder(r)
+	writeHTTPJSON(w, http.StatusOK, &httpKeyValue{Key: encode(key), Value: encode(value)})
+}
+
+func (g *httpGateway) put(w http.ResponseWriter, r *http.Request) {
+	key, ok := decodeHTTPKey(w, r, r.PathValue("key"))
+	if !ok {
+		return
+	}
+
+	// The caller is authorized on the key before the value is read
+	req := &pbnetkv.KeyValues{Kvs: []*pbnetkv.KeyValue{{Key: key}}}
+	ctx, done, ok := g.call(w, r, "BatchPut", req)
+	if !ok {
+		return
+	}
+	defer done()
+
+	value, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(g.server.maxRecvMsgBytes)))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeHTTPError(w, status.Errorf(codes.ResourceExhausted, "value larger than %d bytes", tooLarge.Limit))
+			return
+		}
+
+		writeHTTPError(w, status.Errorf(codes.InvalidArgument, "read value: %s", err))
+		return
+	}
+	req.Kvs[0].Value = value
+
+	if _, err := g.server.BatchPut(ctx, req); err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (g *httpGateway) delete(w http.ResponseWriter, r *http.Request) {
+	key, ok := decodeHTTPKey(w, r, r.PathValue("key"))
+	if !ok {
+		return
+	}
+
+	req := &pbnetkv.Keys{Keys: [][]byte{key}}
+	ctx, done, ok := g.call(w, r, "BatchDelete", req)
+	if !ok {
+		return
+	}
+	defer done()
+
+	if _, err := g.server.BatchDelete(ctx, req); err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (g *httpGateway) scan(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	start, ok := decodeHTTPKey(w, r, query.Get("start"))
+	if !ok {
+		return
+	}
+	end, ok := decodeHTTPKey(w, r, query.Get("end"))
+	if !ok {
+		return
+	}
+
+	req := &pbnetkv.ScanRequest{Start: start, ExclusiveEnd: end}
+	if req.Limit, req.Options, req.Cursor, ok = decodeHTTPRead(w, r); !ok {
+		return
+	}
+	if len(end) == 0 && len(req.Cursor) == 0 {
+		writeHTTPError(w, status.Error(codes.InvalidArgument, "end required, use /prefix/ to read all keys"))
+		return
+	}
+
+	ctx, done, ok := g.call(w, r, "Scan", req)
+	if !ok {
+		return
+	}
+	defer done()
+
+	g.stream(ctx, w, r, func(stream keyValueStream) error {
+		return g.server.Scan(req, stream)
+	})
+}
+
+func (g *httpGateway) prefix(w http.ResponseWriter, r *http.Request) {
+	prefix, ok := decodeHTTPKey(w, r, r.PathValue("prefix"))
+	if !ok {
+		return
+	}
+
+	req := &pbnetkv.PrefixRequest{Prefix: prefix}
+	if req.Limit, req.Options, req.Cursor, ok = decodeHTTPRead(w, r); !ok {
+		return
+	}
+
+	ctx, done, ok := g.call(w, r, "Prefix", req)
+	if !ok {
+		return
+	}
+	defer done()
+
+	g.stream(ctx, w, r, func(stream keyValueStream) error {
+		return g.server.Prefix(req, stream)
+	})
+}
+
+// call prepares the context of a request served by the NetKV `method`, tracking it
+// for draining and checking the caller's token against the method and `req`.
+func (g *httpGateway) call(w http.ResponseWriter, r *http.Request, method string, req interface{}) (context.Context, func(), bool) {
+	ctx, done, err := g.server.trackCall(r.Context())
+	if err != nil {
+		writeHTTPError(w, err)
+		return nil, nil, false
+	}
+
+	md := metadata.MD{}
+	if name := r.URL.Query().Get("store"); name != "" {
+		md.Set(StoreMetadataKey, name)
+	}
+	if authorization := r.Header.Get("Authorization"); authorization != "" {
+		md.Set("authorization", authorization)
+	}
+	ctx = metadata.NewIncomingContext(ctx, md)
+
+	if g.server.auth != nil {
+		var acl *ACL
+		if ctx, acl, err = g.server.auth.authenticate(ctx, netKVServicePrefix+method); err == nil {
+			err = authorizeRequest(acl, req)
+		}
+		if err != nil {
+			done()
+			writeHTTPError(w, err)
+			return nil, nil, false
+		}
+	}
+
+	return ctx, done, true
+}
+
+func (g *httpGateway) stream(ctx context.Context, w http.ResponseWriter, r *http.Request, read func(stream keyValueStream) error) {
+	stream := &httpKeyValueStream{ctx: ctx, writer: w, encode: httpEncoder(r)}
+	stream.flusher, _ = w.(http.Flusher)
+
+	if err := read(stream); err != nil {
+		if !stream.started {
+			writeHTTPError(w, err)
+			return
+		}
+
+		stream.encoder.Encode(&httpError{Error: err.Error()})
+		return
+	}
+
+	if !stream.started {
+		stream.start()
+	}
+}
+
+// httpKeyValueStream writes the items sent by a NetKV read method as newline delimited
+// JSON, the response status is only written with the first item so that errors occurring
+// before are reported with a proper status.
+type httpKeyValueStream struct {
+	grpc.ServerStream
+
+	ctx     context.Context
+	writer  http.ResponseWriter
+	flusher http.Flusher
+	encoder *json.Encoder
+	encode  func([]byte) string
+	started bool
+}
+
+func (s *httpKeyValueStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *httpKeyValueStream) start() {
+	s.writer.Header().Set("Content-Type", "application/x-ndjson")
+	s.writer.WriteHeader(http.StatusOK)
+	s.encoder = json.NewEncoder(s.writer)
+	s.started = true
+}
+
+func (s *httpKeyValueStream) Send(kv *pbnetkv.KeyValue) error {
+	if !s.started {
+		s.start()
+	}
+
+	out := &httpKeyValue{Key: s.encode(kv.Key), Value: s.encode(kv.Value), Cursor: s.encode(kv.Cursor)}
+	if err := s.encoder.Encode(out); err != nil {
+		return err
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+	return nil
+}
+
+func httpEncoder(r *http.Request) func([]byte) string {
+	if r.URL.Query().Get("encoding") == "base64" {
+		return base64.RawURLEncoding.EncodeToString
+	}
+	return hex.EncodeToString
+}
+
+// decodeHTTPKey decodes a key, or any binary parameter, with the request encoding,
+// writing a `400 Bad Request` response when it's invalid.
+func decodeHTTPKey(w http.ResponseWriter, r *http.Request, in string) ([]byte, bool) {
+	var out []byte
+	var err error
+	switch encoding := r.URL.Query().Get("encoding"); encoding {
+	case "", "hex":
+		out, err = hex.DecodeString(in)
+	case "base64":
+		out, err = base64.RawURLEncoding.DecodeString(in)
+	default:
+		err = fmt.Errorf("unknown encoding %q, accepted values are hex and base64", encoding)
+	}
+
+	if err != nil {
+		writeHTTPError(w, status.Errorf(codes.InvalidArgument, "invalid key %q: %s", in, err))
+		return nil, false
+	}
+	return out, true
+}
+
+func decodeHTTPRead(w http.ResponseWriter, r *http.Request) (limit uint64, options *pbnetkv.ReadOptions, cursor []byte, ok bool) {
+	query := r.URL.Query()
+
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		var err error
+		if limit, err = strconv.ParseUint(rawLimit, 10, 64); err != nil {
+			writeHTTPError(w, status.Errorf(codes.InvalidArgument, "invalid limit %q: %s", rawLimit, err))
+			return 0, nil, nil, false
+		}
+	}
+
+	if cursor, ok = decodeHTTPKey(w, r, query.Get("cursor")); !ok {
+		return 0, nil, nil, false
+	}
+
+	options = &pbnetkv.ReadOptions{KeyOnly: query.Get("key_only") == "true", IncludeCursor: query.Get("include_cursor") == "true"}
+	return limit, options, cursor, true
+}
+
+func writeHTTPJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeHTTPError(w http.ResponseWriter, err error) {
+	writeHTTPJSON(w, httpStatus(err), &httpError{Error: status.Convert(err).Message()})
+}
+
+// httpStatus maps the errors of NetKV methods, mostly gRPC status errors, to HTTP ones.
+func httpStatus(err error) int {
+	if errors.Is(err, store.ErrNotFound) {
+		return http.StatusNotFound
+	}
+
+	switch status.Code(err) {
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.ResourceExhausted:
+		return http.StatusRequestEntityTooLarge
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
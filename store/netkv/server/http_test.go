@@ -0,0 +1,128 @@
+package netkvserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/streamingfast/kvdb/store/badger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPGateway(t *testing.T) {
+	server, err := Launch("127.0.0.1:0", fmt.Sprintf("badger://%s", filepath.Join(t.TempDir(), "db")),
+		WithHTTPGateway("127.0.0.1:0"),
+		WithTokenVerifier(StaticTokens{
+			"writer": {Name: "writer", Permissions: PermissionRead | PermissionWrite},
+			"reader": {Name: "reader", Permissions: PermissionRead, Prefixes: [][]byte{[]byte("a")}},
+		}),
+	)
+	require.NoError(t, err)
+	defer server.Close()
+
+	call := func(method, path, token, body string) (int, string) {
+		req, err := http.NewRequest(method, fmt.Sprintf("http://%s%s", server.HTTPAddr(), path), strings.NewReader(body))
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		out, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(out)
+	}
+
+	lines := func(body string) (out []map[string]string) {
+		scanner := bufio.NewScanner(strings.NewReader(body))
+		for scanner.Scan() {
+			line := map[string]string{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+			out = append(out, line)
+		}
+		return
+	}
+
+	for _, key := range []string{"a1", "a2", "b1"} {
+		code, _ := call("PUT", "/kv/"+fmt.Sprintf("%x", key), "writer", "value-"+key)
+		require.Equal(t, http.StatusNoContent, code)
+	}
+
+	code, body := call("GET", "/kv/6131", "reader", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"key": "6131", "value": "76616c75652d6131"}`, body)
+
+	code, body = call("GET", "/kv/YTE?encoding=base64&raw=true", "reader", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "value-a1", body)
+
+	code, _ = call("GET", "/kv/6139", "reader", "")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = call("GET", "/kv/zz", "reader", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = call("GET", "/kv/6131", "", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = call("GET", "/kv/6231", "reader", "")
+	assert.Equal(t, http.StatusForbidden, code, "key outside of token prefixes")
+	code, _ = call("PUT", "/kv/6131", "reader", "value")
+	assert.Equal(t, http.StatusForbidden, code, "token without write permission")
+
+	code, body = call("GET", "/prefix/?key_only=true", "writer", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []map[string]string{{"key": "6131"}, {"key": "6132"}, {"key": "6231"}}, lines(body))
+
+	code, body = call("GET", "/scan?start=6132&end=63", "writer", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []map[string]string{{"key": "6132", "value": "76616c75652d6132"}, {"key": "6231", "value": "76616c75652d6231"}}, lines(body))
+
+	code, body = call("GET", "/prefix/61?limit=1&include_cursor=true", "reader", "")
+	assert.Equal(t, http.StatusOK, code)
+	page := lines(body)
+	require.Len(t, page, 1)
+	require.NotEmpty(t, page[0]["cursor"])
+
+	code, body = call("GET", "/prefix/61?cursor="+page[0]["cursor"], "reader", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []map[string]string{{"key": "6132", "value": "76616c75652d6132"}}, lines(body))
+
+	code, _ = call("DELETE", "/kv/6131", "writer", "")
+	assert.Equal(t, http.StatusNoContent, code)
+	code, _ = call("GET", "/kv/6131", "writer", "")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestHTTPGateway_PutLimits(t *testing.T) {
+	server, err := Launch("127.0.0.1:0", fmt.Sprintf("badger://%s", filepath.Join(t.TempDir(), "db")),
+		WithHTTPGateway("127.0.0.1:0"),
+		WithMaxRecvMsgBytes(16),
+		WithTokenVerifier(StaticTokens{"writer": {Name: "writer", Permissions: PermissionRead | PermissionWrite}}),
+	)
+	require.NoError(t, err)
+	defer server.Close()
+
+	put := func(token, value string) int {
+		req, err := http.NewRequest("PUT", fmt.Sprintf("http://%s/kv/6131", server.HTTPAddr()), strings.NewReader(value))
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, put("", strings.Repeat("v", 1024)), "caller is authenticated before the value is read")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, put("writer", strings.Repeat("v", 17)))
+	assert.Equal(t, http.StatusNoContent, put("writer", strings.Repeat("v", 16)))
+}
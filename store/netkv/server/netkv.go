@@ -2,87 +2,210 @@ package netkvserver
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
-	"io"
 	"net"
+	"net/http"
+	"time"
 
 	"github.com/streamingfast/kvdb/store"
+	"github.com/streamingfast/kvdb/store/netkv/encoding/zstd"
 	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 )
 
+// minClientKeepaliveTime is the shortest interval at which clients can ping idle
+// connections, it must match the netkv client's `minKeepaliveTime`.
+const minClientKeepaliveTime = 10 * time.Second
+
+// DefaultMaxRecvMsgBytes is the largest message accepted from clients when not specified,
+// it matches the default `max_send_msg_bytes` of the netkv client.
+const DefaultMaxRecvMsgBytes = 100 * 1024 * 1024
+
+const (
+	// httpReadHeaderTimeout bounds the time a client of the HTTP gateway takes to send its request headers
+	httpReadHeaderTimeout = 10 * time.Second
+	// httpReadTimeout bounds the time a client of the HTTP gateway takes to send its whole request
+	httpReadTimeout = 5 * time.Minute
+)
+
 // Implement the SERVER aspect of netbadger, which defers to the `badger` KV store
 // implementation.
 
 type Server struct {
-	store      store.KVStore
-	grpcServer *grpc.Server
-	listener   net.Listener
+	stores           map[string]*hostedStore
+	grpcServer       *grpc.Server
+	listener         net.Listener
+	streamFlushBytes int
+	maxRecvMsgBytes  int
+	readOnly         bool
+	startedAt        time.Time
+
+	// auth is nil when clients are not authenticated
+	auth   *authenticator
+	health *health.Server
+	calls  *callTracker
+	// httpServer is nil unless `WithHTTPGateway` is provided
+	httpServer   *http.Server
+	httpListener net.Listener
+	// closing is canceled by `Close`, ending the calls still in progress
+	closing     context.Context
+	cancelCalls context.CancelFunc
 }
 
-func Launch(listenAddr string, dsn string) (*Server, error) {
-	str, err := store.New(dsn)
+// Launch serves the store of the DSN on the listen address, as the `default` store,
+// see `LaunchStores`.
+func Launch(listenAddr string, dsn string, opts ...Option) (*Server, error) {
+	return LaunchStores(listenAddr, []*StoreConfig{{Name: DefaultStoreName, DSN: dsn}}, opts...)
+}
+
+// LaunchStores serves the stores on the listen address, in plain text unless `WithTLS`
+// is provided and without authentication unless `WithTokenVerifier` is. The standard
+// gRPC health service is served too, reporting the server as serving until it's drained.
+func LaunchStores(listenAddr string, configs []*StoreConfig, opts ...Option) (*Server, error) {
+	options := &options{streamFlushBytes: DefaultStreamFlushBytes, maxRecvMsgBytes: DefaultMaxRecvMsgBytes}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	stores, err := openStores(configs, options.replicationLogBytes)
 	if err != nil {
-		return nil, fmt.Errorf("setting up kvdb store: %w", err)
+		return nil, err
 	}
 
 	lis, err := net.Listen("tcp", listenAddr)
 	if err != nil {
+		closeStores(stores)
 		return nil, fmt.Errorf("failed listening: %w", err)
 	}
 
-	gsrv := grpc.NewServer(grpc.MaxRecvMsgSize(1024 * 1024 * 100))
-
+	closing, cancelCalls := context.WithCancel(context.Background())
 	s := &Server{
-		store:      str,
-		grpcServer: gsrv,
-		listener:   lis,
+		stores:           stores,
+		listener:         lis,
+		streamFlushBytes: options.streamFlushBytes,
+		maxRecvMsgBytes:  options.maxRecvMsgBytes,
+		readOnly:         options.readOnly,
+		startedAt:        time.Now(),
+		health:           health.NewServer(),
+		calls:            newCallTracker(),
+		closing:          closing,
+		cancelCalls:      cancelCalls,
+	}
+
+	unaryInterceptors := []grpc.UnaryServerInterceptor{s.trackingUnaryInterceptor}
+	streamInterceptors := []grpc.StreamServerInterceptor{s.trackingStreamInterceptor}
+	if options.verifier != nil {
+		s.auth = &authenticator{verifier: options.verifier}
+		unaryInterceptors = append(unaryInterceptors, s.auth.unaryInterceptor)
+		streamInterceptors = append(streamInterceptors, s.auth.streamInterceptor)
 	}
 
+	zstd.AllowDecompressedBytes(options.maxRecvMsgBytes)
+
+	grpcOpts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(options.maxRecvMsgBytes),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: minClientKeepaliveTime, PermitWithoutStream: true}),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	}
+	if options.tlsConfig != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(options.tlsConfig)))
+	}
+
+	gsrv := grpc.NewServer(grpcOpts...)
+	s.grpcServer = gsrv
+
 	reflection.Register(gsrv)
+	healthpb.RegisterHealthServer(gsrv, s.health)
 	pbnetkv.RegisterNetKVServer(gsrv, s)
+	pbnetkv.RegisterNetKVAdminServer(gsrv, s)
+
+	for _, service := range []string{"", "dfuse.netkv.v1.NetKV", "dfuse.netkv.v1.NetKVAdmin"} {
+		s.health.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+
+	if options.httpListenAddr != "" {
+		if s.httpListener, err = net.Listen("tcp", options.httpListenAddr); err != nil {
+			lis.Close()
+			closeStores(stores)
+			return nil, fmt.Errorf("failed listening for http gateway: %w", err)
+		}
+		if options.tlsConfig != nil {
+			s.httpListener = tls.NewListener(s.httpListener, options.tlsConfig)
+		}
+
+		s.httpServer = &http.Server{Handler: s.HTTPHandler(), ReadHeaderTimeout: httpReadHeaderTimeout, ReadTimeout: httpReadTimeout}
+		go s.httpServer.Serve(s.httpListener)
+	}
 
 	go gsrv.Serve(lis)
 
 	return s, nil
 }
 
-func (s *Server) Close() error {
-	if closer, ok := s.store.(io.Closer); ok {
-		if err := closer.Close(); err != nil {
-			return err
-		}
+// Addr returns the address the server listens on.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// HTTPAddr returns the address the HTTP gateway listens on, nil without `WithHTTPGateway`.
+func (s *Server) HTTPAddr() net.Addr {
+	if s.httpListener == nil {
+		return nil
 	}
+	return s.httpListener.Addr()
+}
+
+// Close stops the server and closes its stores, the calls still in progress are
+// canceled, use `Drain` beforehand to let them complete.
+func (s *Server) Close() error {
+	s.health.Shutdown()
+	idle := s.calls.drain()
+	s.cancelCalls()
+	<-idle
+
 	s.grpcServer.GracefulStop()
-	if err := s.listener.Close(); err != nil {
-		return err
+	if s.httpServer != nil {
+		s.httpServer.Close()
 	}
-	return nil
+	return closeStores(s.stores)
 }
 
 func (s *Server) BatchPut(ctx context.Context, kvs *pbnetkv.KeyValues) (*pbnetkv.EmptyResponse, error) {
-	for _, kv := range kvs.Kvs {
-		err := s.store.Put(ctx, kv.Key, kv.Value)
-		if err != nil {
-			return nil, err
-		}
+	hosted, err := s.hostedStoreFor(ctx, PermissionWrite)
+	if err != nil {
+		return nil, err
 	}
-	if err := s.store.FlushPuts(ctx); err != nil {
+
+	if err := hosted.writePuts(ctx, kvs.Kvs); err != nil {
 		return nil, err
 	}
+
 	return &pbnetkv.EmptyResponse{}, nil
 }
 
 // BatchGet returns only values, and assumes the same order in values as the order of the input keys.
 func (s *Server) BatchGet(keys *pbnetkv.Keys, stream pbnetkv.NetKV_BatchGetServer) error {
+	kvStore, err := s.storeFor(stream.Context(), PermissionRead)
+	if err != nil {
+		return err
+	}
+
 	if len(keys.Keys) == 0 {
 		return status.Newf(codes.InvalidArgument, "at least one key required for BatchGet").Err()
 	}
 	if len(keys.Keys) == 1 {
-		val, err := s.store.Get(stream.Context(), keys.Keys[0])
+		val, err := kvStore.Get(stream.Context(), keys.Keys[0])
 		if err != nil {
 			return wrapNotFoundError(err)
 		}
@@ -92,7 +215,7 @@ func (s *Server) BatchGet(keys *pbnetkv.Keys, stream pbnetkv.NetKV_BatchGetServe
 		return nil
 	}
 
-	it := s.store.BatchGet(stream.Context(), keys.Keys)
+	it := kvStore.BatchGet(stream.Context(), keys.Keys)
 
 	for it.Next() {
 		if err := stream.Send(&pbnetkv.KeyValue{Value: it.Item().Value, Key: it.Item().Key}); err != nil {
@@ -105,12 +228,36 @@ func (s *Server) BatchGet(keys *pbnetkv.Keys, stream pbnetkv.NetKV_BatchGetServe
 	return nil
 }
 
+func (s *Server) BatchExists(ctx context.Context, keys *pbnetkv.Keys) (*pbnetkv.ExistsResponse, error) {
+	kvStore, err := s.storeFor(ctx, PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := store.BatchExists(ctx, kvStore, keys.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbnetkv.ExistsResponse{Exists: exists}, nil
+}
+
 func (s *Server) BatchDelete(ctx context.Context, keys *pbnetkv.Keys) (*pbnetkv.EmptyResponse, error) {
+	hosted, err := s.hostedStoreFor(ctx, PermissionWrite)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(keys.Keys) == 0 {
 		return &pbnetkv.EmptyResponse{}, nil
 	}
 
-	err := s.store.BatchDelete(ctx, keys.Keys)
+	changes := make([]*pbnetkv.ReplicationChange, len(keys.Keys))
+	for i, key := range keys.Keys {
+		changes[i] = &pbnetkv.ReplicationChange{Kind: pbnetkv.ReplicationChange_DELETE, Key: key}
+	}
+
+	err = hosted.writeChanges(changes, func() error { return hosted.BatchDelete(ctx, keys.Keys) })
 	if err != nil {
 		return nil, err
 	}
@@ -128,17 +275,17 @@ func wrapNotFoundError(err error) error {
 }
 
 func (s *Server) Scan(req *pbnetkv.ScanRequest, stream pbnetkv.NetKV_ScanServer) error {
-	it := s.store.Scan(stream.Context(), req.Start, req.ExclusiveEnd, int(req.Limit), storeReadOptions(req.Options)...)
-	for it.Next() {
-		item := it.Item()
-		if err := stream.Send(&pbnetkv.KeyValue{Key: item.Key, Value: item.Value}); err != nil {
-			return err
-		}
+	kvStore, err := s.storeFor(stream.Context(), PermissionRead)
+	if err != nil {
+		return err
 	}
-	if it.Err() != nil {
-		return it.Err()
+
+	if len(req.Cursor) > 0 {
+		return readFromCursor(kvStore, req.Cursor, req.Limit, req.Options, stream)
 	}
-	return nil
+
+	it := kvStore.Scan(stream.Context(), req.Start, req.ExclusiveEnd, int(req.Limit), storeReadOptions(req.Options)...)
+	return sendIterator(it, req.Options, stream)
 }
 
 func (s *Server) BatchScan(req *pbnetkv.BatchScanRequest, stream pbnetkv.NetKV_BatchScanServer) error {
@@ -146,38 +293,175 @@ func (s *Server) BatchScan(req *pbnetkv.BatchScanRequest, stream pbnetkv.NetKV_B
 }
 
 func (s *Server) Prefix(req *pbnetkv.PrefixRequest, stream pbnetkv.NetKV_PrefixServer) error {
-	it := s.store.Prefix(stream.Context(), req.Prefix, int(req.Limit), storeReadOptions(req.Options)...)
-	for it.Next() {
-		item := it.Item()
-		if err := stream.Send(&pbnetkv.KeyValue{Key: item.Key, Value: item.Value}); err != nil {
-			return err
-		}
+	kvStore, err := s.storeFor(stream.Context(), PermissionRead)
+	if err != nil {
+		return err
 	}
-	if it.Err() != nil {
-		return it.Err()
+
+	if len(req.Cursor) > 0 {
+		return readFromCursor(kvStore, req.Cursor, req.Limit, req.Options, stream)
 	}
-	return nil
+
+	it := kvStore.Prefix(stream.Context(), req.Prefix, int(req.Limit), storeReadOptions(req.Options)...)
+	return sendIterator(it, req.Options, stream)
 }
 
 func (s *Server) BatchPrefix(req *pbnetkv.BatchPrefixRequest, stream pbnetkv.NetKV_BatchPrefixServer) error {
-	it := s.store.BatchPrefix(stream.Context(), req.Prefixes, int(req.LimitPerPrefix), storeReadOptions(req.Options)...)
+	kvStore, err := s.storeFor(stream.Context(), PermissionRead)
+	if err != nil {
+		return err
+	}
+
+	if len(req.Cursor) > 0 {
+		return readFromCursor(kvStore, req.Cursor, req.LimitPerPrefix, req.Options, stream)
+	}
+
+	it := kvStore.BatchPrefix(stream.Context(), req.Prefixes, int(req.LimitPerPrefix), storeReadOptions(req.Options)...)
+	return sendIterator(it, req.Options, stream)
+}
+
+func (s *Server) Count(ctx context.Context, req *pbnetkv.CountRequest) (*pbnetkv.CountResponse, error) {
+	kvStore, err := s.storeFor(ctx, PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	var count uint64
+	switch r := req.Range.(type) {
+	case *pbnetkv.CountRequest_KeyRange:
+		count, err = store.Count(ctx, kvStore, r.KeyRange.Start, r.KeyRange.ExclusiveEnd)
+	case *pbnetkv.CountRequest_Prefix:
+		count, err = store.CountPrefix(ctx, kvStore, r.Prefix)
+	default:
+		return nil, status.Newf(codes.InvalidArgument, "either key range or prefix required for Count").Err()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbnetkv.CountResponse{Count: count}, nil
+}
+
+func (s *Server) ApproximateSize(ctx context.Context, req *pbnetkv.KeyRange) (*pbnetkv.ApproximateSizeResponse, error) {
+	kvStore, err := s.storeFor(ctx, PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := store.ApproximateSize(ctx, kvStore, req.Start, req.ExclusiveEnd)
+	if err != nil {
+		if errors.Is(err, store.ErrNotSupported) {
+			return nil, status.Newf(codes.Unimplemented, "approximate size not supported by served store: %s", err).Err()
+		}
+		return nil, err
+	}
+
+	return &pbnetkv.ApproximateSizeResponse{SizeBytes: size}, nil
+}
+
+func (s *Server) DeleteRange(ctx context.Context, req *pbnetkv.DeleteRangeRequest) (*pbnetkv.EmptyResponse, error) {
+	hosted, err := s.hostedStoreFor(ctx, PermissionWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	var change *pbnetkv.ReplicationChange
+	var write func() error
+	switch r := req.Range.(type) {
+	case *pbnetkv.DeleteRangeRequest_KeyRange:
+		change = &pbnetkv.ReplicationChange{Kind: pbnetkv.ReplicationChange_DELETE_RANGE, Key: r.KeyRange.Start, ExclusiveEnd: r.KeyRange.ExclusiveEnd}
+		write = func() error { return store.DeleteRange(ctx, hosted.KVStore, r.KeyRange.Start, r.KeyRange.ExclusiveEnd) }
+	case *pbnetkv.DeleteRangeRequest_Prefix:
+		change = &pbnetkv.ReplicationChange{Kind: pbnetkv.ReplicationChange_DELETE_PREFIX, Key: r.Prefix}
+		write = func() error { return store.DeletePrefix(ctx, hosted.KVStore, r.Prefix) }
+	default:
+		return nil, status.Newf(codes.InvalidArgument, "either key range or prefix required for DeleteRange").Err()
+	}
+
+	if err := hosted.writeChanges([]*pbnetkv.ReplicationChange{change}, write); err != nil {
+		return nil, err
+	}
+
+	return &pbnetkv.EmptyResponse{}, nil
+}
+
+type keyValueStream interface {
+	grpc.ServerStream
+	Send(*pbnetkv.KeyValue) error
+}
+
+func readFromCursor(kvStore store.KVStore, rawCursor []byte, limit uint64, options *pbnetkv.ReadOptions, stream keyValueStream) error {
+	cursor, err := store.ParseCursor(rawCursor)
+	if err != nil {
+		return status.Newf(codes.InvalidArgument, err.Error()).Err()
+	}
+
+	it := store.ReadFromCursor(stream.Context(), kvStore, cursor, int(limit), storeReadOptions(options)...)
+	return sendIterator(it, options, stream)
+}
+
+// sendIterator streams back the items of the iterator, the last item sent carries
+// the iterator's cursor if it was requested and there is more to read after it.
+func sendIterator(it *store.Iterator, options *pbnetkv.ReadOptions, stream keyValueStream) error {
+	includeCursor := options != nil && options.IncludeCursor
+
+	var pending *pbnetkv.KeyValue
 	for it.Next() {
+		if pending != nil {
+			if err := stream.Send(pending); err != nil {
+				return err
+			}
+		}
+
 		item := it.Item()
-		if err := stream.Send(&pbnetkv.KeyValue{Key: item.Key, Value: item.Value}); err != nil {
-			return err
+		pending = &pbnetkv.KeyValue{Key: item.Key, Value: item.Value}
+		if !includeCursor {
+			if err := stream.Send(pending); err != nil {
+				return err
+			}
+			pending = nil
 		}
 	}
 	if it.Err() != nil {
 		return it.Err()
 	}
+
+	if pending != nil {
+		if cursor := it.Cursor(); cursor != nil {
+			pending.Cursor = cursor.Bytes()
+		}
+		return stream.Send(pending)
+	}
 	return nil
 }
 
 func storeReadOptions(options *pbnetkv.ReadOptions) (out []store.ReadOption) {
-	if options != nil && options.KeyOnly {
-		return []store.ReadOption{store.KeyOnly()}
+	if options == nil {
+		return nil
 	}
 
-	// There is not options for now, so we can always return nothing from that point
-	return nil
+	if options.KeyOnly {
+		out = append(out, store.KeyOnly())
+	}
+	if options.BufferSize > 0 {
+		out = append(out, store.WithBufferSize(int(options.BufferSize)))
+	}
+	if options.BufferBytes > 0 {
+		out = append(out, store.WithBufferBytes(int(options.BufferBytes)))
+	}
+	if options.PrefetchSize > 0 {
+		out = append(out, store.WithPrefetchSize(int(options.PrefetchSize)))
+	}
+	if options.KeyRegex != "" {
+		out = append(out, store.WithKeyRegex(options.KeyRegex))
+	}
+	if len(options.ValuePrefix) > 0 {
+		out = append(out, store.WithValuePrefix(options.ValuePrefix))
+	}
+	if options.MinValueSize > 0 || options.MaxValueSize > 0 {
+		out = append(out, store.WithValueSizeRange(int(options.MinValueSize), int(options.MaxValueSize)))
+	}
+
+	return out
 }
@@ -1,30 +1,55 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	_ "github.com/streamingfast/kvdb/store/badger"
+	"github.com/streamingfast/kvdb/store/netkv"
 	netkvserver "github.com/streamingfast/kvdb/store/netkv/server"
 )
 
 // This is a simple, example server for hosting a NetKV, backed (most
 // probably by) Badger storage.
 
-var flagBackendDSN = flag.String("backend-dsn", "badger://./netkv", "KVDB storage backing this NetKV instance")
-var flagListenAddr = flag.String("listen-addr", ":65211", "gRPC listening address (insecure)")
+var flagBackendDSN = flag.String("backend-dsn", "badger://./netkv", "KVDB storage backing this NetKV instance, served as the 'default' store")
+var flagStoresFile = flag.String("stores-file", "", "JSON file of the named stores to host, replaces -backend-dsn")
+var flagListenAddr = flag.String("listen-addr", ":65211", "gRPC listening address, plain text unless -tls-cert-file is provided")
+var flagHTTPListenAddr = flag.String("http-listen-addr", "", "HTTP/JSON gateway listening address, disabled when empty, uses TLS like the gRPC server")
+var flagTLSCertFile = flag.String("tls-cert-file", "", "PEM file of the server certificate, enables TLS")
+var flagTLSKeyFile = flag.String("tls-key-file", "", "PEM file of the server certificate private key")
+var flagTLSClientCAFile = flag.String("tls-client-ca-file", "", "PEM file of the certificate authorities of client certificates, enables mTLS")
+var flagStreamFlushBytes = flag.Int("stream-flush-bytes", netkvserver.DefaultStreamFlushBytes, "Bytes of streamed puts above which they are flushed to the store")
+var flagMaxRecvMsgBytes = flag.Int("max-recv-msg-bytes", netkvserver.DefaultMaxRecvMsgBytes, "Largest message accepted from clients, their max_send_msg_bytes must not be larger")
+var flagReadOnly = flag.Bool("read-only", false, "Reject all writes")
+var flagReplicationLogBytes = flag.Int("replication-log-bytes", 0, "Bytes of recent changes kept for followers to replicate, 0 disables replication")
+var flagFollow = flag.String("follow", "", "NetKV DSN of a server to replicate, each hosted store follows the leader store of the same name, implies -read-only")
+var flagTokensFile = flag.String("tokens-file", "", "JSON file of the bearer tokens accepted, and their ACL, enables authentication")
+var flagDrainTimeout = flag.Duration("drain-timeout", 30*time.Second, "Time given to calls in progress to complete on shutdown, health checks report the server as not serving meanwhile")
 
 func main() {
 	flag.Parse()
 
-	pwd, _ := os.Getwd()
-	backendDSN := strings.Replace(*flagBackendDSN, "//./", fmt.Sprintf("//%s/", pwd), 1)
+	stores, err := storeConfigs()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
 
-	srv, err := netkvserver.Launch(*flagListenAddr, backendDSN)
+	opts, err := serverOptions()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	srv, err := netkvserver.LaunchStores(*flagListenAddr, stores, opts...)
 	if err != nil {
 		fmt.Println("Error:", err)
 		os.Exit(1)
@@ -32,11 +57,26 @@ func main() {
 
 	fmt.Println("Listening", *flagListenAddr)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	if *flagFollow != "" {
+		for _, config := range stores {
+			go follow(ctx, srv, config.Name)
+		}
+	}
+
 	signals := make(chan os.Signal)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
 	<-signals
 
+	fmt.Println("Draining")
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), *flagDrainTimeout)
+	if err := srv.Drain(drainCtx); err != nil {
+		fmt.Println("Calls still in progress after", *flagDrainTimeout, "are canceled")
+	}
+	cancelDrain()
+
 	fmt.Println("Shutting down")
+	cancel()
 
 	if err := srv.Close(); err != nil {
 		fmt.Println(err)
@@ -44,3 +84,77 @@ func main() {
 		fmt.Println("Done")
 	}
 }
+
+func storeConfigs() ([]*netkvserver.StoreConfig, error) {
+	stores := []*netkvserver.StoreConfig{{Name: netkvserver.DefaultStoreName, DSN: *flagBackendDSN}}
+	if *flagStoresFile != "" {
+		var err error
+		if stores, err = netkvserver.LoadStoresFile(*flagStoresFile); err != nil {
+			return nil, err
+		}
+	}
+
+	pwd, _ := os.Getwd()
+	for _, config := range stores {
+		config.DSN = strings.Replace(config.DSN, "//./", fmt.Sprintf("//%s/", pwd), 1)
+	}
+
+	return stores, nil
+}
+
+func follow(ctx context.Context, srv *netkvserver.Server, name string) {
+	leaderDSN, err := url.Parse(*flagFollow)
+	if err != nil {
+		fmt.Println("Error: invalid -follow dsn:", err)
+		os.Exit(1)
+	}
+	leaderDSN.Path = "/" + name
+
+	leader, err := netkv.NewStore(leaderDSN.String())
+	if err != nil {
+		fmt.Println("Error: following store", name, ":", err)
+		os.Exit(1)
+	}
+	defer leader.Close()
+
+	fmt.Println("Following", name)
+	if err := leader.(*netkv.Store).Follow(ctx, srv.Store(name)); err != nil && err != context.Canceled {
+		fmt.Println("Error: following store", name, ":", err)
+		os.Exit(1)
+	}
+}
+
+func serverOptions() (opts []netkvserver.Option, err error) {
+	opts = append(opts, netkvserver.WithStreamFlushBytes(*flagStreamFlushBytes))
+	opts = append(opts, netkvserver.WithMaxRecvMsgBytes(*flagMaxRecvMsgBytes))
+
+	if *flagReadOnly || *flagFollow != "" {
+		opts = append(opts, netkvserver.WithReadOnly())
+	}
+	if *flagHTTPListenAddr != "" {
+		opts = append(opts, netkvserver.WithHTTPGateway(*flagHTTPListenAddr))
+	}
+	if *flagReplicationLogBytes > 0 {
+		opts = append(opts, netkvserver.WithReplicationLog(*flagReplicationLogBytes))
+	}
+
+	if *flagTLSCertFile != "" {
+		config, err := netkvserver.NewServerTLSConfig(*flagTLSCertFile, *flagTLSKeyFile, *flagTLSClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, netkvserver.WithTLS(config))
+	} else if *flagTLSClientCAFile != "" {
+		return nil, fmt.Errorf("-tls-client-ca-file requires -tls-cert-file")
+	}
+
+	if *flagTokensFile != "" {
+		tokens, err := netkvserver.LoadTokensFile(*flagTokensFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, netkvserver.WithTokenVerifier(tokens))
+	}
+
+	return opts, nil
+}
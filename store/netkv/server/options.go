@@ -0,0 +1,116 @@
+package netkvserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+type Option func(o *options)
+
+type options struct {
+	tlsConfig           *tls.Config
+	verifier            TokenVerifier
+	streamFlushBytes    int
+	readOnly            bool
+	replicationLogBytes int
+	httpListenAddr      string
+	maxRecvMsgBytes     int
+}
+
+// WithTLS serves over TLS with the given configuration, see `NewServerTLSConfig`.
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = config
+	}
+}
+
+// WithTokenVerifier requires clients to send a bearer token, verified by `verifier`,
+// restricting each client to the operations and keys its ACL grants.
+func WithTokenVerifier(verifier TokenVerifier) Option {
+	return func(o *options) {
+		o.verifier = verifier
+	}
+}
+
+// WithStreamFlushBytes sets the bytes of keys and values received through `StreamPut`
+// above which they are flushed to the store, `DefaultStreamFlushBytes` by default.
+func WithStreamFlushBytes(bytes int) Option {
+	return func(o *options) {
+		o.streamFlushBytes = bytes
+	}
+}
+
+// WithMaxRecvMsgBytes sets the largest message accepted from clients, `DefaultMaxRecvMsgBytes`
+// by default, clients must not set a larger `max_send_msg_bytes`.
+func WithMaxRecvMsgBytes(bytes int) Option {
+	return func(o *options) {
+		o.maxRecvMsgBytes = bytes
+	}
+}
+
+// WithReadOnly rejects all writes with `PermissionDenied`.
+func WithReadOnly() Option {
+	return func(o *options) {
+		o.readOnly = true
+	}
+}
+
+// WithReplicationLog keeps the last `bytes` of changes made through the server to each
+// store, `DefaultReplicationLogBytes` when 0, so that followers can replicate them.
+func WithReplicationLog(bytes int) Option {
+	return func(o *options) {
+		o.replicationLogBytes = bytes
+		if bytes == 0 {
+			o.replicationLogBytes = DefaultReplicationLogBytes
+		}
+	}
+}
+
+// WithHTTPGateway also serves the HTTP/JSON gateway described by `Server.HTTPHandler` on
+// the listen address, over TLS when `WithTLS` is provided.
+func WithHTTPGateway(listenAddr string) Option {
+	return func(o *options) {
+		o.httpListenAddr = listenAddr
+	}
+}
+
+// NewServerTLSConfig loads the server certificate and key PEM files, when `clientCAFile`
+// is provided, clients must present a certificate signed by one of its authorities (mTLS).
+func NewServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load server certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCAFile != "" {
+		pool, err := loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate authorities: %w", err)
+		}
+
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(content) {
+		return nil, fmt.Errorf("no PEM certificate found in %q", file)
+	}
+	return pool, nil
+}
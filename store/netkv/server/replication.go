@@ -0,0 +1,194 @@
+package netkvserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+
+	"github.com/streamingfast/kvdb/store"
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultReplicationLogBytes is the bytes of changes kept by `WithReplicationLog` when
+// not specified, followers lagging further behind must start over from a snapshot.
+const DefaultReplicationLogBytes = 64 * 1024 * 1024
+
+const snapshotBatchBytes = 1024 * 1024
+
+// changeLog keeps the most recent changes made through the server to a store, for
+// followers to tail them. Changes are identified by their sequence in the log, the log
+// id telling apart logs of different server runs.
+type changeLog struct {
+	id       string
+	maxBytes int
+
+	lock     sync.Mutex
+	batches  []*pbnetkv.ReplicationBatch
+	sizes    []int
+	size     int
+	first    uint64
+	sequence uint64
+	changed  chan struct{}
+}
+
+func newChangeLog(maxBytes int) *changeLog {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+
+	return &changeLog{id: hex.EncodeToString(id), maxBytes: maxBytes, changed: make(chan struct{})}
+}
+
+func (l *changeLog) append(changes []*pbnetkv.ReplicationChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	size := 0
+	for _, change := range changes {
+		size += len(change.Key) + len(change.Value) + len(change.ExclusiveEnd)
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.sequence += uint64(len(changes))
+	l.batches = append(l.batches, &pbnetkv.ReplicationBatch{LogId: l.id, Sequence: l.sequence, Changes: changes})
+	l.sizes = append(l.sizes, size)
+	l.size += size
+
+	// The last batch is always kept so that a follower is never lost right after a write
+	for l.size > l.maxBytes && len(l.batches) > 1 {
+		l.first = l.batches[0].Sequence
+		l.size -= l.sizes[0]
+		l.batches, l.sizes = l.batches[1:], l.sizes[1:]
+	}
+
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// since returns the batches following the position and a channel closed on the next
+// append, `found` is false when the position is not in the log.
+func (l *changeLog) since(logID string, sequence uint64) (batches []*pbnetkv.ReplicationBatch, changed <-chan struct{}, found bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if logID != l.id || sequence < l.first || sequence > l.sequence {
+		return nil, nil, false
+	}
+
+	index := sort.Search(len(l.batches), func(i int) bool { return l.batches[i].Sequence > sequence })
+	return append([]*pbnetkv.ReplicationBatch(nil), l.batches[index:]...), l.changed, true
+}
+
+func (l *changeLog) position() uint64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.sequence
+}
+
+// record appends the changes to the store's change log, if it keeps one.
+func (h *hostedStore) record(changes ...*pbnetkv.ReplicationChange) {
+	if h.log != nil {
+		h.log.append(changes)
+	}
+}
+
+func (h *hostedStore) recordPuts(kvs []*pbnetkv.KeyValue) {
+	if h.log == nil {
+		return
+	}
+
+	changes := make([]*pbnetkv.ReplicationChange, len(kvs))
+	for i, kv := range kvs {
+		changes[i] = &pbnetkv.ReplicationChange{Kind: pbnetkv.ReplicationChange_PUT, Key: kv.Key, Value: kv.Value}
+	}
+	h.log.append(changes)
+}
+
+func (s *Server) Replicate(req *pbnetkv.ReplicateRequest, stream pbnetkv.NetKV_ReplicateServer) error {
+	ctx := stream.Context()
+	hosted, err := s.hostedStoreFor(ctx, PermissionRead)
+	if err != nil {
+		return err
+	}
+
+	log := hosted.log
+	if log == nil {
+		return status.Error(codes.FailedPrecondition, "server does not keep a replication log")
+	}
+
+	position := req.FromSequence
+	batches, changed, found := log.since(req.LogId, position)
+	if !found {
+		if position, err = sendSnapshot(hosted, stream); err != nil {
+			return err
+		}
+
+		if batches, changed, found = log.since(log.id, position); !found {
+			return status.Error(codes.Aborted, "replication log moved past the snapshot while it was sent")
+		}
+	}
+
+	for {
+		for _, batch := range batches {
+			if err := stream.Send(batch); err != nil {
+				return err
+			}
+			position = batch.Sequence
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.calls.drained:
+			return status.Error(codes.Unavailable, "server is draining")
+		case <-changed:
+		}
+
+		if batches, changed, found = log.since(log.id, position); !found {
+			return status.Error(codes.Aborted, "follower fell behind the replication log")
+		}
+	}
+}
+
+// sendSnapshot streams all the keys of the store, returning the log position the
+// snapshot is at. Changes appended while the snapshot is sent might already be part of
+// it, replaying them is harmless as they are applied in order.
+func sendSnapshot(hosted *hostedStore, stream pbnetkv.NetKV_ReplicateServer) (uint64, error) {
+	position := hosted.log.position()
+
+	batch := &pbnetkv.ReplicationBatch{LogId: hosted.log.id, Sequence: position, Clear: true, Snapshot: true}
+	batchSize := 0
+
+	it := hosted.Prefix(stream.Context(), nil, store.Unlimited)
+	for it.Next() {
+		item := it.Item()
+		batch.Changes = append(batch.Changes, &pbnetkv.ReplicationChange{Kind: pbnetkv.ReplicationChange_PUT, Key: item.Key, Value: item.Value})
+		batchSize += len(item.Key) + len(item.Value)
+
+		if batchSize >= snapshotBatchBytes {
+			if err := stream.Send(batch); err != nil {
+				return 0, err
+			}
+
+			batch = &pbnetkv.ReplicationBatch{LogId: hosted.log.id, Sequence: position, Snapshot: true}
+			batchSize = 0
+		}
+	}
+	if err := it.Err(); err != nil {
+		return 0, err
+	}
+
+	if err := stream.Send(batch); err != nil {
+		return 0, err
+	}
+
+	return position, stream.Send(&pbnetkv.ReplicationBatch{LogId: hosted.log.id, Sequence: position})
+}
@@ -0,0 +1,47 @@
+package netkvserver
+
+import (
+	"testing"
+
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeLog(t *testing.T) {
+	log := newChangeLog(10)
+	put := func(key string) *pbnetkv.ReplicationChange {
+		return &pbnetkv.ReplicationChange{Kind: pbnetkv.ReplicationChange_PUT, Key: []byte(key), Value: []byte("1234")}
+	}
+
+	_, _, found := log.since("", 0)
+	assert.False(t, found, "unknown log")
+
+	batches, changed, found := log.since(log.id, 0)
+	require.True(t, found)
+	assert.Empty(t, batches)
+
+	log.append([]*pbnetkv.ReplicationChange{put("a"), put("b")})
+	select {
+	case <-changed:
+	default:
+		t.Fatal("append should close the changed channel")
+	}
+
+	batches, _, found = log.since(log.id, 0)
+	require.True(t, found)
+	require.Len(t, batches, 1)
+	assert.Equal(t, uint64(2), batches[0].Sequence)
+
+	log.append([]*pbnetkv.ReplicationChange{put("c")})
+	_, _, found = log.since(log.id, 0)
+	assert.False(t, found, "first batch evicted")
+
+	batches, _, found = log.since(log.id, 2)
+	require.True(t, found)
+	require.Len(t, batches, 1)
+	assert.Equal(t, uint64(3), batches[0].Sequence)
+
+	_, _, found = log.since(log.id, 4)
+	assert.False(t, found, "position ahead of the log")
+}
@@ -0,0 +1,242 @@
+package netkvserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/streamingfast/kvdb/store"
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+	"go.uber.org/multierr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// StoreMetadataKey is the gRPC metadata holding the name of the store a call targets.
+	StoreMetadataKey = "netkv-store"
+	// DefaultStoreName is the store targeted by calls not selecting one.
+	DefaultStoreName = "default"
+)
+
+var storeNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// StoreConfig is a store hosted by the server, reachable by clients under `Name`.
+type StoreConfig struct {
+	Name string `json:"name"`
+	DSN  string `json:"dsn"`
+}
+
+// LoadStoresFile reads the stores to host from a JSON file holding a list of entries of
+// the form `{"name": "blocks", "dsn": "badger:///data/blocks.db"}`.
+func LoadStoresFile(path string) ([]*StoreConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read stores file: %w", err)
+	}
+
+	var configs []*StoreConfig
+	if err := json.Unmarshal(content, &configs); err != nil {
+		return nil, fmt.Errorf("parse stores file %q: %w", path, err)
+	}
+
+	return configs, nil
+}
+
+type hostedStore struct {
+	store.KVStore
+
+	driver     string
+	readCalls  atomic.Uint64
+	writeCalls atomic.Uint64
+	// log is nil when the server does not keep a replication log
+	log *changeLog
+	// writeLock is held across each write and the recording of its changes, so that the
+	// change log has the order in which the writes were applied
+	writeLock sync.Mutex
+}
+
+// writePuts writes the puts to the store and flushes them, recording them in the change log.
+func (h *hostedStore) writePuts(ctx context.Context, kvs []*pbnetkv.KeyValue) error {
+	h.writeLock.Lock()
+	defer h.writeLock.Unlock()
+
+	for _, kv := range kvs {
+		if err := h.Put(ctx, kv.Key, kv.Value); err != nil {
+			return err
+		}
+	}
+	if err := h.FlushPuts(ctx); err != nil {
+		return err
+	}
+
+	h.recordPuts(kvs)
+	return nil
+}
+
+// writeChanges applies the changes with `write`, recording them in the change log once it succeeds.
+func (h *hostedStore) writeChanges(changes []*pbnetkv.ReplicationChange, write func() error) error {
+	h.writeLock.Lock()
+	defer h.writeLock.Unlock()
+
+	if err := write(); err != nil {
+		return err
+	}
+
+	h.record(changes...)
+	return nil
+}
+
+func openStores(configs []*StoreConfig, replicationLogBytes int) (map[string]*hostedStore, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("at least one store required")
+	}
+
+	stores := map[string]*hostedStore{}
+	for _, config := range configs {
+		if !storeNameRegex.MatchString(config.Name) {
+			closeStores(stores)
+			return nil, fmt.Errorf("invalid store name %q, only letters, digits, '_', '.' and '-' are accepted", config.Name)
+		}
+		if _, found := stores[config.Name]; found {
+			closeStores(stores)
+			return nil, fmt.Errorf("store %q configured more than once", config.Name)
+		}
+
+		reg, _, err := store.ExplainDSN(config.DSN)
+		if err != nil {
+			closeStores(stores)
+			return nil, fmt.Errorf("setting up kvdb store %q: %w", config.Name, err)
+		}
+
+		kvStore, err := store.New(config.DSN)
+		if err != nil {
+			closeStores(stores)
+			return nil, fmt.Errorf("setting up kvdb store %q: %w", config.Name, err)
+		}
+
+		hosted := &hostedStore{KVStore: kvStore, driver: reg.Name}
+		if replicationLogBytes > 0 {
+			hosted.log = newChangeLog(replicationLogBytes)
+		}
+		stores[config.Name] = hosted
+	}
+
+	return stores, nil
+}
+
+func closeStores(stores map[string]*hostedStore) (err error) {
+	for _, hosted := range stores {
+		err = multierr.Append(err, hosted.Close())
+	}
+	return err
+}
+
+// storeFor returns the store targeted by the call, see `hostedStoreFor`.
+func (s *Server) storeFor(ctx context.Context, permission Permission) (store.KVStore, error) {
+	hosted, err := s.hostedStoreFor(ctx, permission)
+	if err != nil {
+		return nil, err
+	}
+
+	return hosted.KVStore, nil
+}
+
+// hostedStoreFor returns the store targeted by the call, checking the caller is allowed
+// to access it, and counts the call as a read or a write, admin calls are not counted.
+func (s *Server) hostedStoreFor(ctx context.Context, permission Permission) (*hostedStore, error) {
+	if permission == PermissionWrite && s.readOnly {
+		return nil, status.Error(codes.PermissionDenied, "server is read only")
+	}
+
+	name := DefaultStoreName
+	if md, found := metadata.FromIncomingContext(ctx); found {
+		if values := md.Get(StoreMetadataKey); len(values) > 0 && values[0] != "" {
+			name = values[0]
+		}
+	}
+
+	hosted, found := s.stores[name]
+	if !found {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown store %q", name)
+	}
+
+	if acl := ACLFromContext(ctx); acl != nil && !acl.AllowsStore(name) {
+		return nil, status.Errorf(codes.PermissionDenied, "token %q is not allowed to access store %q", acl.Name, name)
+	}
+
+	switch permission {
+	case PermissionRead:
+		hosted.readCalls.Add(1)
+	case PermissionWrite:
+		hosted.writeCalls.Add(1)
+	}
+
+	return hosted, nil
+}
+
+// Store returns the store hosted under the name, nil if there is none. Writes made
+// directly to it are not replicated to followers.
+func (s *Server) Store(name string) store.KVStore {
+	if hosted, found := s.stores[name]; found {
+		return hosted.KVStore
+	}
+	return nil
+}
+
+func (s *Server) ListStores(ctx context.Context, req *pbnetkv.ListStoresRequest) (*pbnetkv.ListStoresResponse, error) {
+	resp := &pbnetkv.ListStoresResponse{}
+	for _, name := range s.accessibleStores(ctx) {
+		info, err := s.storeInfo(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Stores = append(resp.Stores, info)
+	}
+
+	return resp, nil
+}
+
+// accessibleStores returns the sorted names of the stores the caller can access.
+func (s *Server) accessibleStores(ctx context.Context) []string {
+	acl := ACLFromContext(ctx)
+
+	names := make([]string, 0, len(s.stores))
+	for name := range s.stores {
+		if acl == nil || acl.AllowsStore(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (s *Server) storeInfo(ctx context.Context, name string) (*pbnetkv.StoreInfo, error) {
+	hosted := s.stores[name]
+	info := &pbnetkv.StoreInfo{
+		Name:         name,
+		Driver:       hosted.driver,
+		ReadCalls:    hosted.readCalls.Load(),
+		WriteCalls:   hosted.writeCalls.Load(),
+		Capabilities: store.Capabilities(hosted.KVStore).Names(),
+	}
+
+	size, err := store.ApproximateSize(ctx, hosted.KVStore, nil, nil)
+	switch {
+	case err == nil:
+		info.ApproximateSizeBytes, info.SizeKnown = size, true
+	case !errors.Is(err, store.ErrNotSupported):
+		return nil, fmt.Errorf("approximate size of store %q: %w", name, err)
+	}
+
+	return info, nil
+}
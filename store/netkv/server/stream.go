@@ -0,0 +1,55 @@
+package netkvserver
+
+import (
+	"io"
+
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+)
+
+// DefaultStreamFlushBytes is the bytes of keys and values received through `StreamPut`
+// above which the server flushes them without waiting for the client to request it.
+const DefaultStreamFlushBytes = 4 * 1024 * 1024
+
+func (s *Server) StreamPut(stream pbnetkv.NetKV_StreamPutServer) error {
+	ctx := stream.Context()
+	hosted, err := s.hostedStoreFor(ctx, PermissionWrite)
+	if err != nil {
+		return err
+	}
+
+	// pending are the puts received since the last flush, they are only written to the store
+	// on flush so that the puts of an aborted stream are dropped and never committed by
+	// another call flushing the store
+	var pending []*pbnetkv.KeyValue
+	received, pendingBytes := uint64(0), 0
+	flush := func() error {
+		if err := hosted.writePuts(ctx, pending); err != nil {
+			return err
+		}
+
+		pending, pendingBytes = nil, 0
+		return stream.Send(&pbnetkv.StreamPutResponse{CommittedCount: received})
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return flush()
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, kv := range req.Kvs {
+			pending = append(pending, kv)
+			received++
+			pendingBytes += len(kv.Key) + len(kv.Value)
+		}
+
+		if req.Flush || pendingBytes >= s.streamFlushBytes {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
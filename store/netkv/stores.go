@@ -0,0 +1,83 @@
+package netkv
+
+import (
+	"context"
+	"time"
+
+	"github.com/streamingfast/kvdb/store"
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// storeMetadataKey selects the store hosted by the server a call targets, it must
+// match `netkvserver.StoreMetadataKey`.
+const storeMetadataKey = "netkv-store"
+
+// defaultStoreName is the store targeted when the DSN does not select one, it must
+// match `netkvserver.DefaultStoreName`.
+const defaultStoreName = "default"
+
+// capabilitiesTimeout bounds the call fetching the capabilities of the served store,
+// `Capabilities` not taking a context.
+const capabilitiesTimeout = 10 * time.Second
+
+// storeSelectionDialOptions make all calls target the store named `storeName`, the
+// server targets its `default` store otherwise.
+func storeSelectionDialOptions(storeName string) []grpc.DialOption {
+	withStore := func(ctx context.Context) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, storeMetadataKey, storeName)
+	}
+
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(withStore(ctx), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(withStore(ctx), desc, cc, method, opts...)
+		}),
+	}
+}
+
+// ListStores returns the stores hosted by the server, along their statistics, that
+// the client can access.
+func (s *Store) ListStores(ctx context.Context) ([]*pbnetkv.StoreInfo, error) {
+	resp, err := pbnetkv.NewNetKVAdminClient(s.conn).ListStores(ctx, &pbnetkv.ListStoresRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Stores, nil
+}
+
+// Capabilities implements `store.CapabilitiesReporter`, reporting the capabilities of
+// the store served by the server. They are fetched on first use, none are reported
+// while the server cannot be reached.
+func (s *Store) Capabilities() store.DriverCapabilities {
+	s.capabilitiesLock.Lock()
+	defer s.capabilitiesLock.Unlock()
+
+	if s.capabilities != nil {
+		return *s.capabilities
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), capabilitiesTimeout)
+	defer cancel()
+
+	stores, err := s.ListStores(ctx)
+	if err != nil {
+		zlog.Warn("unable to fetch the capabilities of the served store", zap.Error(err))
+		return store.DriverCapabilities{}
+	}
+
+	capabilities := store.DriverCapabilities{}
+	for _, info := range stores {
+		if info.Name == s.storeName {
+			capabilities = store.CapabilitiesFromNames(info.Capabilities)
+		}
+	}
+	s.capabilities = &capabilities
+
+	return capabilities
+}
@@ -0,0 +1,91 @@
+package netkv
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/streamingfast/kvdb/store"
+	netkvserver "github.com/streamingfast/kvdb/store/netkv/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMultipleStores(t *testing.T) {
+	dir := t.TempDir()
+	server, err := netkvserver.LaunchStores("127.0.0.1:0", []*netkvserver.StoreConfig{
+		{Name: "default", DSN: fmt.Sprintf("badger://%s", filepath.Join(dir, "default"))},
+		{Name: "blocks", DSN: fmt.Sprintf("badger://%s", filepath.Join(dir, "blocks"))},
+	}, netkvserver.WithTokenVerifier(netkvserver.StaticTokens{
+		"admin":  {Name: "admin", Permissions: netkvserver.PermissionRead | netkvserver.PermissionWrite},
+		"blocks": {Name: "blocks", Permissions: netkvserver.PermissionRead, Stores: []string{"blocks"}},
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	open := func(path string, token string) store.KVStore {
+		query := "insecure=true&token=" + token
+		if path != "" {
+			query = path + "?" + query
+		}
+		return openClient(t, server, query)
+	}
+
+	ctx := context.Background()
+	defaultStore, blocksStore := open("", "admin"), open("/blocks", "admin")
+
+	require.NoError(t, blocksStore.Put(ctx, []byte("a"), []byte("block")))
+	require.NoError(t, blocksStore.FlushPuts(ctx))
+
+	value, err := blocksStore.Get(ctx, []byte("a"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("block"), value)
+
+	_, err = defaultStore.Get(ctx, []byte("a"))
+	assert.Equal(t, store.ErrNotFound, err, "stores are isolated")
+
+	_, err = open("/unknown", "admin").Get(ctx, []byte("a"))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "unknown store")
+
+	stores, err := defaultStore.(*Store).ListStores(ctx)
+	require.NoError(t, err)
+	require.Len(t, stores, 2)
+	assert.Equal(t, "blocks", stores[0].Name)
+	assert.Equal(t, "badger", stores[0].Driver)
+	assert.True(t, stores[0].SizeKnown)
+	assert.Equal(t, uint64(1), stores[0].WriteCalls)
+	assert.Equal(t, uint64(1), stores[0].ReadCalls)
+	assert.Equal(t, []string{"empty_values"}, stores[0].Capabilities)
+	assert.Equal(t, "default", stores[1].Name)
+
+	assert.Equal(t, store.DriverCapabilities{EmptyValues: true}, store.Capabilities(blocksStore), "capabilities of the served store are forwarded")
+
+	restricted := open("/blocks", "blocks")
+	_, err = restricted.Get(ctx, []byte("a"))
+	require.NoError(t, err)
+
+	_, err = open("", "blocks").Get(ctx, []byte("a"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "token restricted to other store")
+
+	stores, err = restricted.(*Store).ListStores(ctx)
+	require.NoError(t, err)
+	require.Len(t, stores, 1, "only accessible stores are listed")
+	assert.Equal(t, "blocks", stores[0].Name)
+}
+
+func TestLaunchStores_Invalid(t *testing.T) {
+	dsn := fmt.Sprintf("badger://%s", filepath.Join(t.TempDir(), "db"))
+
+	for _, configs := range [][]*netkvserver.StoreConfig{
+		nil,
+		{{Name: "with/slash", DSN: dsn}},
+		{{Name: "a", DSN: dsn}, {Name: "a", DSN: dsn + "2"}},
+		{{Name: "a", DSN: "unknown://"}},
+	} {
+		_, err := netkvserver.LaunchStores("127.0.0.1:0", configs)
+		assert.Error(t, err)
+	}
+}
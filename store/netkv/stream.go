@@ -0,0 +1,183 @@
+package netkv
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/streamingfast/kvdb/store"
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultStreamChunkBytes  = 1024 * 1024
+	defaultStreamWindowBytes = 16 * 1024 * 1024
+)
+
+var streamOptionSchemas = store.OptionSchemas{
+	{Name: "stream_chunk_bytes", Type: store.OptionTypeInt, Default: fmt.Sprint(defaultStreamChunkBytes), Description: "Bytes of puts (keys and values) sent per message, a single put larger than that is sent alone"},
+	{Name: "stream_window_bytes", Type: store.OptionTypeInt, Default: fmt.Sprint(defaultStreamWindowBytes), Description: "Bytes of puts sent but not yet committed by the server above which writes wait for it"},
+}
+
+// batchPut writes the puts with `StreamPut`, falling back to `BatchPut` calls of at
+// most `stream_chunk_bytes` each against servers not supporting it.
+func (s *Store) batchPut(ctx context.Context, kvs []store.KV) error {
+	if !s.streamUnsupported.Load() {
+		err := s.streamPut(ctx, kvs)
+		if status.Code(err) != codes.Unimplemented {
+			return err
+		}
+
+		zlog.Info("server does not support streaming puts, falling back to batch puts")
+		s.streamUnsupported.Store(true)
+	}
+
+	for _, chunk := range chunkKVs(kvs, s.streamChunkBytes) {
+		if _, err := s.client.BatchPut(ctx, &pbnetkv.KeyValues{Kvs: toPBKeyValues(chunk)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type sentChunk struct {
+	end  uint64
+	size int
+}
+
+// streamPut sends the puts in chunks, waiting for the server to commit some of them
+// when more than `stream_window_bytes` are uncommitted. A flush is requested each
+// time half the window was sent so the server always has something to acknowledge.
+func (s *Store) streamPut(ctx context.Context, kvs []store.KV) error {
+	if len(kvs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := s.client.StreamPut(ctx)
+	if err != nil {
+		return err
+	}
+
+	acks := make(chan uint64)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+
+			select {
+			case acks <- resp.CommittedCount:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var sent, committed, flushRequested uint64
+	var inFlight []sentChunk
+	uncommitted, sinceFlush := 0, 0
+
+	waitAck := func() error {
+		select {
+		case count := <-acks:
+			committed = count
+			for len(inFlight) > 0 && inFlight[0].end <= committed {
+				uncommitted -= inFlight[0].size
+				inFlight = inFlight[1:]
+			}
+			return nil
+		case err := <-recvErr:
+			if err == io.EOF {
+				return fmt.Errorf("stream put: server ended the stream with %d of %d puts committed", committed, sent)
+			}
+			return err
+		}
+	}
+
+	chunks := chunkKVs(kvs, s.streamChunkBytes)
+	for i, chunk := range chunks {
+		size := kvsSize(chunk)
+		for uncommitted > 0 && uncommitted+size > s.streamWindowBytes && committed < flushRequested {
+			if err := waitAck(); err != nil {
+				return err
+			}
+		}
+
+		sent += uint64(len(chunk))
+		sinceFlush += size
+
+		flush := i == len(chunks)-1 || sinceFlush >= s.streamWindowBytes/2
+		if flush {
+			flushRequested, sinceFlush = sent, 0
+		}
+
+		if err := stream.Send(&pbnetkv.StreamPutRequest{Kvs: toPBKeyValues(chunk), Flush: flush}); err != nil {
+			if err != io.EOF {
+				return err
+			}
+
+			// The server ended the stream, its status is received by the acknowledgements loop
+			for {
+				if err := waitAck(); err != nil {
+					return err
+				}
+			}
+		}
+
+		inFlight = append(inFlight, sentChunk{end: sent, size: size})
+		uncommitted += size
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	for committed < sent {
+		if err := waitAck(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkKVs splits the puts in chunks of at most `maxBytes` of keys and values, a put
+// larger than that being alone in its chunk.
+func chunkKVs(kvs []store.KV, maxBytes int) (chunks [][]store.KV) {
+	start, size := 0, 0
+	for i, kv := range kvs {
+		kvSize := len(kv.Key) + len(kv.Value)
+		if i > start && size+kvSize > maxBytes {
+			chunks = append(chunks, kvs[start:i])
+			start, size = i, 0
+		}
+		size += kvSize
+	}
+
+	if start < len(kvs) {
+		chunks = append(chunks, kvs[start:])
+	}
+	return chunks
+}
+
+func kvsSize(kvs []store.KV) (size int) {
+	for _, kv := range kvs {
+		size += len(kv.Key) + len(kv.Value)
+	}
+	return size
+}
+
+func toPBKeyValues(kvs []store.KV) []*pbnetkv.KeyValue {
+	out := make([]*pbnetkv.KeyValue, len(kvs))
+	for i, kv := range kvs {
+		out[i] = &pbnetkv.KeyValue{Key: kv.Key, Value: kv.Value}
+	}
+	return out
+}
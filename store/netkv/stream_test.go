@@ -0,0 +1,116 @@
+package netkv
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/streamingfast/kvdb/store"
+	pbnetkv "github.com/streamingfast/kvdb/store/netkv/pb"
+	netkvserver "github.com/streamingfast/kvdb/store/netkv/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func testPutKeys(t *testing.T, kvStore store.KVStore, count int) {
+	t.Helper()
+
+	for i := 0; i < count; i++ {
+		require.NoError(t, kvStore.Put(context.Background(), []byte(fmt.Sprintf("key%03d", i)), []byte("0123456789")))
+	}
+	require.NoError(t, kvStore.FlushPuts(context.Background()))
+}
+
+func TestStreamPut(t *testing.T) {
+	server := launchTestServer(t, netkvserver.WithStreamFlushBytes(50))
+	kvStore := openClient(t, server, "insecure=true&stream_chunk_bytes=20&stream_window_bytes=60")
+
+	testPutKeys(t, kvStore, 100)
+	assert.False(t, kvStore.(*Store).streamUnsupported.Load())
+
+	count, err := store.CountPrefix(context.Background(), kvStore, []byte("key"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), count)
+}
+
+func TestStreamPut_PendingPutsIsolated(t *testing.T) {
+	server := launchTestServer(t)
+	kvStore := openClient(t, server, "insecure=true")
+
+	conn, err := grpc.Dial(server.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := pbnetkv.NewNetKVClient(conn).StreamPut(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pbnetkv.StreamPutRequest{Kvs: []*pbnetkv.KeyValue{{Key: []byte("a"), Value: []byte("1")}}}))
+
+	// Leaves the server time to receive the put, nothing acknowledges it before a flush
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(t, kvStore.Put(context.Background(), []byte("b"), []byte("2")))
+	require.NoError(t, kvStore.FlushPuts(context.Background()))
+	_, err = kvStore.Get(context.Background(), []byte("a"))
+	assert.Equal(t, store.ErrNotFound, err, "another call does not commit the puts of the stream")
+
+	cancel()
+	require.NoError(t, kvStore.Put(context.Background(), []byte("c"), []byte("3")))
+	require.NoError(t, kvStore.FlushPuts(context.Background()))
+	_, err = kvStore.Get(context.Background(), []byte("a"))
+	assert.Equal(t, store.ErrNotFound, err, "puts of an aborted stream are dropped")
+}
+
+type testBatchPutOnlyServer struct {
+	pbnetkv.UnimplementedNetKVServer
+
+	lock  sync.Mutex
+	calls [][]*pbnetkv.KeyValue
+}
+
+func (s *testBatchPutOnlyServer) BatchPut(ctx context.Context, kvs *pbnetkv.KeyValues) (*pbnetkv.EmptyResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.calls = append(s.calls, kvs.Kvs)
+	return &pbnetkv.EmptyResponse{}, nil
+}
+
+func TestBatchPut_StreamUnsupported(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &testBatchPutOnlyServer{}
+	grpcServer := grpc.NewServer()
+	pbnetkv.RegisterNetKVServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	kvStore, err := store.New(fmt.Sprintf("netkv://%s?insecure=true&stream_chunk_bytes=40", listener.Addr()))
+	require.NoError(t, err)
+	defer kvStore.Close()
+
+	testPutKeys(t, kvStore, 5)
+	assert.True(t, kvStore.(*Store).streamUnsupported.Load())
+
+	require.Len(t, server.calls, 3, "puts are chunked by size")
+	assert.Len(t, server.calls[0], 2)
+	assert.Len(t, server.calls[2], 1)
+}
+
+func TestChunkKVs(t *testing.T) {
+	kv := func(size int) store.KV { return store.KV{Key: []byte("k"), Value: make([]byte, size-1)} }
+
+	chunks := chunkKVs([]store.KV{kv(4), kv(4), kv(10), kv(1), kv(5)}, 8)
+	require.Len(t, chunks, 3)
+	assert.Len(t, chunks[0], 2)
+	assert.Len(t, chunks[1], 1, "larger put is alone")
+	assert.Len(t, chunks[2], 2)
+
+	assert.Nil(t, chunkKVs(nil, 8))
+}
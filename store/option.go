@@ -6,6 +6,12 @@ type EmtpyValueEnabler interface {
 	EnableEmpty()
 }
 
+// FlushErrorHandlerSetter is implemented by drivers able to write puts in the background,
+// the handler is called with the error of each failed background write.
+type FlushErrorHandlerSetter interface {
+	SetFlushErrorHandler(onError func(err error))
+}
+
 type Option interface {
 	apply(s KVStore)
 }
@@ -23,6 +29,23 @@ func (e emptyValueOpt) apply(s KVStore) {
 	}
 }
 
+type flushErrorHandlerOpt struct {
+	onError func(err error)
+}
+
+// WithFlushErrorHandler sets the function called with the error of each failed background
+// write of puts (`batch_async=true`), the error is also returned by the next `Put` or
+// `FlushPuts` call. The function must not call the store back.
+func WithFlushErrorHandler(onError func(err error)) Option {
+	return flushErrorHandlerOpt{onError: onError}
+}
+
+func (o flushErrorHandlerOpt) apply(s KVStore) {
+	if f, ok := s.(FlushErrorHandlerSetter); ok {
+		f.SetFlushErrorHandler(o.onError)
+	}
+}
+
 func NewReadOptions(opts ...ReadOption) (out *ReadOptions) {
 	if len(opts) == 0 {
 		return nil
@@ -37,7 +60,28 @@ func NewReadOptions(opts ...ReadOption) (out *ReadOptions) {
 }
 
 type ReadOptions struct {
-	KeyOnly bool
+	KeyOnly bool `json:"key_only,omitempty"`
+
+	// BufferSize is the maximum number of items an iterator holds ahead of its consumer,
+	// 0 means the default of 100 items.
+	BufferSize int `json:"buffer_size,omitempty"`
+	// BufferBytes is the maximum number of bytes (keys and values) an iterator holds ahead
+	// of its consumer, 0 means no bytes limit. A single item bigger than the limit is
+	// still buffered when the buffer is empty.
+	BufferBytes int `json:"buffer_bytes,omitempty"`
+	// PrefetchSize is the number of items drivers fetch from their backend in a single
+	// page, 0 means the driver's default.
+	PrefetchSize int `json:"prefetch_size,omitempty"`
+
+	// KeyRegex, when set, keeps only the items whose key matches entirely this RE2 regular
+	// expression.
+	KeyRegex string `json:"key_regex,omitempty"`
+	// ValuePrefix, when set, keeps only the items whose value starts with it.
+	ValuePrefix []byte `json:"value_prefix,omitempty"`
+	// MinValueSize and MaxValueSize, when set, keep only the items whose value size in
+	// bytes is within this inclusive range, a MaxValueSize of 0 means no upper bound.
+	MinValueSize int `json:"min_value_size,omitempty"`
+	MaxValueSize int `json:"max_value_size,omitempty"`
 }
 
 func (o *ReadOptions) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
@@ -47,6 +91,27 @@ func (o *ReadOptions) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
 	}
 
 	encoder.AddBool("key_only", o.KeyOnly)
+	if o.BufferSize > 0 {
+		encoder.AddInt("buffer_size", o.BufferSize)
+	}
+	if o.BufferBytes > 0 {
+		encoder.AddInt("buffer_bytes", o.BufferBytes)
+	}
+	if o.PrefetchSize > 0 {
+		encoder.AddInt("prefetch_size", o.PrefetchSize)
+	}
+	if o.KeyRegex != "" {
+		encoder.AddString("key_regex", o.KeyRegex)
+	}
+	if len(o.ValuePrefix) > 0 {
+		encoder.AddString("value_prefix", Key(o.ValuePrefix).String())
+	}
+	if o.MinValueSize > 0 {
+		encoder.AddInt("min_value_size", o.MinValueSize)
+	}
+	if o.MaxValueSize > 0 {
+		encoder.AddInt("max_value_size", o.MaxValueSize)
+	}
 	return nil
 }
 
@@ -63,3 +128,78 @@ type keyOnlyReadOption struct{}
 func (o keyOnlyReadOption) Apply(opts *ReadOptions) {
 	opts.KeyOnly = true
 }
+
+// WithBufferSize limits to `items` the number of items the iterator holds ahead of
+// its consumer.
+func WithBufferSize(items int) ReadOption {
+	return bufferSizeReadOption(items)
+}
+
+type bufferSizeReadOption int
+
+func (o bufferSizeReadOption) Apply(opts *ReadOptions) {
+	opts.BufferSize = int(o)
+}
+
+// WithBufferBytes limits to `bytes` the total size of the items the iterator holds
+// ahead of its consumer, useful to bound the memory used when reading large values.
+func WithBufferBytes(bytes int) ReadOption {
+	return bufferBytesReadOption(bytes)
+}
+
+type bufferBytesReadOption int
+
+func (o bufferBytesReadOption) Apply(opts *ReadOptions) {
+	opts.BufferBytes = int(o)
+}
+
+// WithPrefetchSize sets the number of items the driver fetches from its backend in a
+// single page.
+func WithPrefetchSize(items int) ReadOption {
+	return prefetchSizeReadOption(items)
+}
+
+type prefetchSizeReadOption int
+
+func (o prefetchSizeReadOption) Apply(opts *ReadOptions) {
+	opts.PrefetchSize = int(o)
+}
+
+// WithKeyRegex keeps only the items whose key matches entirely the RE2 regular
+// expression `expr`, drivers push the filtering down to their backend when possible.
+func WithKeyRegex(expr string) ReadOption {
+	return keyRegexReadOption(expr)
+}
+
+type keyRegexReadOption string
+
+func (o keyRegexReadOption) Apply(opts *ReadOptions) {
+	opts.KeyRegex = string(o)
+}
+
+// WithValuePrefix keeps only the items whose value starts with `prefix`, drivers push
+// the filtering down to their backend when possible.
+func WithValuePrefix(prefix []byte) ReadOption {
+	return valuePrefixReadOption(prefix)
+}
+
+type valuePrefixReadOption []byte
+
+func (o valuePrefixReadOption) Apply(opts *ReadOptions) {
+	opts.ValuePrefix = []byte(o)
+}
+
+// WithValueSizeRange keeps only the items whose value size in bytes is between `min`
+// and `max` inclusively, a `max` of 0 means no upper bound.
+func WithValueSizeRange(min, max int) ReadOption {
+	return valueSizeRangeReadOption{min, max}
+}
+
+type valueSizeRangeReadOption struct {
+	min, max int
+}
+
+func (o valueSizeRangeReadOption) Apply(opts *ReadOptions) {
+	opts.MinValueSize = o.min
+	opts.MaxValueSize = o.max
+}
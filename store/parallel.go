@@ -0,0 +1,167 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const defaultParallelScanWorkers = 4
+
+// ParallelScanOptions configures `ParallelScan`.
+type ParallelScanOptions struct {
+	// Workers is the number of chunks scanned concurrently, 0 means 4.
+	Workers int
+	// Chunks is the approximate number of chunks the range is split in, see `SplitRange`,
+	// 0 means 4 chunks per worker.
+	Chunks int
+	// Unordered emits the items as soon as any worker reads them instead of in key order,
+	// which keeps all workers busy even when the consumer is slow on a single chunk.
+	Unordered bool
+}
+
+// ParallelScan reads all the keys in [start, exclusiveEnd) like `Scan` does, but splits the
+// range with `SplitRange` and scans the chunks concurrently. Items are emitted in key order
+// unless `ParallelScanOptions.Unordered` is set, ordered reads only run ahead on the chunks
+// following the one being consumed, as far as their iterator buffer goes.
+//
+// Like `Scan`, an empty `exclusiveEnd` reads nothing. The read stops at the first error of
+// any chunk.
+func ParallelScan(ctx context.Context, s KVStore, start, exclusiveEnd []byte, parallel ParallelScanOptions, options ...ReadOption) *Iterator {
+	out := NewIterator(ctx, options...)
+
+	workers := parallel.Workers
+	if workers <= 0 {
+		workers = defaultParallelScanWorkers
+	}
+
+	chunks := parallel.Chunks
+	if chunks <= 0 {
+		chunks = 4 * workers
+	}
+
+	go func() {
+		if len(exclusiveEnd) == 0 {
+			out.PushFinished()
+			return
+		}
+
+		splits, err := SplitRange(ctx, s, start, exclusiveEnd, chunks)
+		if err != nil {
+			out.PushError(fmt.Errorf("split range: %w", err))
+			return
+		}
+
+		bounds := make([][]byte, 0, len(splits)+2)
+		bounds = append(bounds, start)
+		bounds = append(bounds, splits...)
+		bounds = append(bounds, exclusiveEnd)
+
+		// Stops the chunks still reading when the merge ends early, on error or on consumer cancellation
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		scan := func(i int) *Iterator {
+			return s.Scan(ctx, bounds[i], bounds[i+1], Unlimited, options...)
+		}
+
+		if parallel.Unordered {
+			err = parallelScanUnordered(ctx, out, len(bounds)-1, workers, scan)
+		} else {
+			err = parallelScanOrdered(ctx, out, len(bounds)-1, workers, scan)
+		}
+
+		if err != nil {
+			out.PushError(err)
+			return
+		}
+		out.PushFinished()
+	}()
+
+	return out
+}
+
+func parallelScanOrdered(ctx context.Context, out *Iterator, chunks, workers int, scan func(i int) *Iterator) error {
+	slots := make(chan struct{}, workers)
+	pending := make(chan *Iterator, workers)
+
+	go func() {
+		defer close(pending)
+
+		for i := 0; i < chunks; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case slots <- struct{}{}:
+			}
+
+			pending <- scan(i)
+		}
+	}()
+
+	for it := range pending {
+		for it.Next() {
+			if !out.PushItem(it.Item()) {
+				return nil
+			}
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+
+		<-slots
+	}
+
+	return ctx.Err()
+}
+
+func parallelScanUnordered(ctx context.Context, out *Iterator, chunks, workers int, scan func(i int) *Iterator) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	indexes := make(chan int, chunks)
+	for i := 0; i < chunks; i++ {
+		indexes <- i
+	}
+	close(indexes)
+
+	var lock sync.Mutex
+	var firstErr error
+	stop := func(err error) {
+		lock.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		lock.Unlock()
+		cancel()
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				it := scan(i)
+				for it.Next() {
+					lock.Lock()
+					stopped := firstErr != nil || !out.PushItem(it.Item())
+					lock.Unlock()
+
+					if stopped {
+						cancel()
+						return
+					}
+				}
+				if err := it.Err(); err != nil {
+					stop(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
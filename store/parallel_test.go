@@ -0,0 +1,100 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestParallelStore(count int) (*memoryKVStore, []KV) {
+	s := newMemoryKVStore()
+	for i := 0; i < count; i++ {
+		s.set([]byte(fmt.Sprintf("k%03d", i)), []byte(fmt.Sprintf("%d", i)))
+	}
+
+	return s, append([]KV{}, s.kvs...)
+}
+
+func TestParallelScan(t *testing.T) {
+	s, all := newTestParallelStore(200)
+
+	tests := []struct {
+		name     string
+		parallel ParallelScanOptions
+	}{
+		{"ordered default", ParallelScanOptions{}},
+		{"ordered single worker", ParallelScanOptions{Workers: 1, Chunks: 8}},
+		{"ordered many chunks", ParallelScanOptions{Workers: 3, Chunks: 50}},
+		{"unordered", ParallelScanOptions{Workers: 4, Chunks: 16, Unordered: true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := Collect(ParallelScan(context.Background(), s, []byte("k"), []byte("l"), test.parallel, WithBufferSize(2)))
+			require.NoError(t, err)
+
+			if test.parallel.Unordered {
+				sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i].Key, out[j].Key) < 0 })
+			}
+			assert.Equal(t, all, out)
+		})
+	}
+}
+
+func TestParallelScan_EmptyEnd(t *testing.T) {
+	s, _ := newTestParallelStore(10)
+
+	out, err := Collect(ParallelScan(context.Background(), s, []byte("k"), nil, ParallelScanOptions{}))
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+type failingScanStore struct {
+	*memoryKVStore
+	failAt []byte
+}
+
+func (s *failingScanStore) Scan(ctx context.Context, start, exclusiveEnd []byte, limit int, options ...ReadOption) *Iterator {
+	if bytes.Compare(start, s.failAt) <= 0 && bytes.Compare(s.failAt, exclusiveEnd) < 0 {
+		it := NewIterator(ctx)
+		it.PushError(errors.New("chunk failed"))
+		return it
+	}
+
+	return s.memoryKVStore.Scan(ctx, start, exclusiveEnd, limit, options...)
+}
+
+func TestParallelScan_Error(t *testing.T) {
+	for _, unordered := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unordered %t", unordered), func(t *testing.T) {
+			memory, _ := newTestParallelStore(200)
+			s := &failingScanStore{memoryKVStore: memory, failAt: []byte("k100")}
+
+			_, err := Collect(ParallelScan(context.Background(), s, []byte("k"), []byte("l"), ParallelScanOptions{Workers: 2, Unordered: unordered}, WithBufferSize(1)))
+			assert.EqualError(t, err, "chunk failed")
+		})
+	}
+}
+
+func TestParallelScan_StopEarly(t *testing.T) {
+	for _, unordered := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unordered %t", unordered), func(t *testing.T) {
+			s, _ := newTestParallelStore(200)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			it := ParallelScan(ctx, s, []byte("k"), []byte("l"), ParallelScanOptions{Workers: 2, Unordered: unordered}, WithBufferSize(1))
+			require.True(t, it.Next())
+			cancel()
+
+			for it.Next() {
+			}
+			assert.ErrorIs(t, it.Err(), context.Canceled)
+		})
+	}
+}
@@ -26,6 +26,22 @@ func NewPurgeableStore(tablePrefix []byte, store KVStore, ttlInBlocks uint64) *P
 	}
 }
 
+func (s *PurgeableKVStore) Capabilities() DriverCapabilities {
+	return Capabilities(s.KVStore)
+}
+
+func (s *PurgeableKVStore) Exists(ctx context.Context, key []byte) (bool, error) {
+	return Exists(ctx, s.KVStore, key)
+}
+
+func (s *PurgeableKVStore) BatchExists(ctx context.Context, keys [][]byte) ([]bool, error) {
+	return BatchExists(ctx, s.KVStore, keys)
+}
+
+func (s *PurgeableKVStore) Delete(ctx context.Context, key []byte) error {
+	return Delete(ctx, s.KVStore, key)
+}
+
 func (s *PurgeableKVStore) Put(ctx context.Context, key, value []byte) error {
 	if !s.heightSet {
 		return fmt.Errorf("ephemeral kv store height not set")
@@ -65,25 +81,34 @@ func (s *PurgeableKVStore) PurgeKeys(ctx context.Context) error {
 	startKey := s.deletionKey(lowBlockNum, []byte{})
 	endKey := s.deletionKey(highBlockNum, []byte{})
 
-	itr := s.Scan(ctx, startKey, endKey, Unlimited)
-	deletionKey := [][]byte{}
+	// Original keys are spread all over, they are deleted in batches, their deletion keys are deleted
+	// last, all at once since they are contiguous, so a purge interrupted midway can be done again
+	itr := s.KVStore.Scan(ctx, startKey, endKey, Unlimited, KeyOnly())
+	originalKeys := [][]byte{}
 	for itr.Next() {
-		if len(deletionKey) >= PurgeableMaxBatchSize {
-			err := s.KVStore.BatchDelete(ctx, deletionKey)
+		if len(originalKeys) >= PurgeableMaxBatchSize {
+			err := s.KVStore.BatchDelete(ctx, originalKeys)
 			if err != nil {
 				return fmt.Errorf("unable to delete batch: %w", err)
 			}
-			deletionKey = [][]byte{}
+			originalKeys = [][]byte{}
 		}
-		deletionKey = append(deletionKey, itr.Item().Key)
-		deletionKey = append(deletionKey, s.originalKey(itr.Item().Key))
+		originalKeys = append(originalKeys, s.originalKey(itr.Item().Key))
+	}
+	if err := itr.Err(); err != nil {
+		return fmt.Errorf("unable to scan deletion keys: %w", err)
 	}
-	if len(deletionKey) >= 0 {
-		err := s.KVStore.BatchDelete(ctx, deletionKey)
+
+	if len(originalKeys) > 0 {
+		err := s.KVStore.BatchDelete(ctx, originalKeys)
 		if err != nil {
 			return fmt.Errorf("unable to delete batch: %w", err)
 		}
 	}
+
+	if err := DeleteRange(ctx, s.KVStore, startKey, endKey); err != nil {
+		return fmt.Errorf("unable to delete deletion keys: %w", err)
+	}
 	return nil
 }
 
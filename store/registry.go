@@ -2,6 +2,8 @@ package store
 
 import (
 	"fmt"
+	"net/url"
+	"sort"
 	"strings"
 
 	"go.uber.org/zap"
@@ -11,9 +13,18 @@ import (
 type NewStoreFunc func(path string) (KVStore, error)
 
 type Registration struct {
-	Name        string // unique name
-	Title       string // human-readable name
+	Name        string   // unique name
+	Aliases     []string // other schemes accepted for the driver, DSNs are rewritten to use `Name`
+	Title       string   // human-readable name
 	FactoryFunc NewStoreFunc
+
+	// Capabilities are the ones of the driver's backend, stores report them through
+	// `store.Capabilities` (which may refine them per instance).
+	Capabilities DriverCapabilities
+
+	// Options declares the DSN query parameters of the driver, `New` rejects DSNs using
+	// unknown ones. Left nil, the DSN is passed as-is to the driver.
+	Options OptionSchemas
 }
 
 var registry = make(map[string]*Registration)
@@ -21,10 +32,18 @@ var registry = make(map[string]*Registration)
 func Register(reg *Registration) {
 	if reg.Name == "" {
 		zlog.Fatal("name cannot be blank")
-	} else if _, ok := registry[reg.Name]; ok {
-		zlog.Fatal("already registered", zap.String("name", reg.Name))
 	}
+
+	for _, scheme := range append([]string{reg.Name}, reg.Aliases...) {
+		if _, ok := registry[scheme]; ok {
+			zlog.Fatal("already registered", zap.String("name", reg.Name), zap.String("scheme", scheme))
+		}
+	}
+
 	registry[reg.Name] = reg
+	for _, alias := range reg.Aliases {
+		registry[alias] = reg
+	}
 }
 
 func isRegistered(schemeName string) bool {
@@ -32,12 +51,61 @@ func isRegistered(schemeName string) bool {
 	return isRegistered
 }
 
-func New(dsn string, opts ...Option) (KVStore, error) {
-	chunks := strings.Split(dsn, ":")
-	reg, found := registry[chunks[0]]
+// schemeOf returns the scheme of the DSN, parsed as an URL when possible and as the part
+// before the first colon otherwise.
+func schemeOf(dsn string) string {
+	if dsnURL, err := url.Parse(dsn); err == nil && dsnURL.Scheme != "" {
+		return dsnURL.Scheme
+	}
+
+	scheme, _, _ := strings.Cut(dsn, ":")
+	return scheme
+}
+
+func registrationOf(dsn string) (*Registration, error) {
+	scheme := schemeOf(dsn)
+	reg, found := registry[scheme]
 	if !found {
-		return nil, fmt.Errorf("no such kv store registered %q", chunks[0])
+		return nil, fmt.Errorf("no such kv store registered %q", scheme)
+	}
+	return reg, nil
+}
+
+// canonicalDSN returns the DSN using the name of the driver as scheme when it uses one of
+// its aliases.
+func canonicalDSN(reg *Registration, dsn string) string {
+	scheme := schemeOf(dsn)
+	if scheme == reg.Name || len(dsn) < len(scheme) || !strings.EqualFold(dsn[:len(scheme)], scheme) {
+		return dsn
 	}
+
+	return reg.Name + dsn[len(scheme):]
+}
+
+// New creates the store registered for the DSN's scheme.
+//
+// The DSN can contain the `retry_max=<count>` and `retry_backoff=<duration>` query
+// parameters, supported by all drivers, in which case the store is wrapped in a
+// RetryableKVStore retrying idempotent operations on transient errors.
+//
+// The DSN query parameters are validated against the options declared by the driver,
+// see `Registration.ResolveDSN`.
+func New(dsn string, opts ...Option) (KVStore, error) {
+	reg, err := registrationOf(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn, _, err = reg.ResolveDSN(canonicalDSN(reg, dsn))
+	if err != nil {
+		return nil, fmt.Errorf("invalid dsn: %w", err)
+	}
+
+	retryPolicy, dsn, err := retryPolicyFromDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dsn: %w", err)
+	}
+
 	store, err := reg.FactoryFunc(dsn)
 	if err != nil {
 		return nil, err
@@ -45,10 +113,29 @@ func New(dsn string, opts ...Option) (KVStore, error) {
 	for _, opt := range opts {
 		opt.apply(store)
 	}
+
+	if retryPolicy != nil {
+		zlog.Info("wrapping store with retry policy", zap.String("driver", reg.Name), zap.Object("policy", retryPolicy))
+		store = NewRetryableStore(store, retryPolicy)
+	}
+
 	return store, nil
 }
 
-// ByName returns a registered store driver
+// Registrations returns the registered store drivers, sorted by name.
+func Registrations() []*Registration {
+	out := make([]*Registration, 0, len(registry))
+	for scheme, reg := range registry {
+		if scheme == reg.Name {
+			out = append(out, reg)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ByName returns a registered store driver, by its name or one of its aliases
 func ByName(name string) *Registration {
 	r, ok := registry[name]
 	if !ok {
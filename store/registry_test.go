@@ -0,0 +1,83 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCapableStore struct {
+	*memoryKVStore
+	dsn string
+}
+
+func (s *testCapableStore) Capabilities() DriverCapabilities {
+	return DriverCapabilities{ReverseScan: true, EmptyValues: true}
+}
+
+func init() {
+	Register(&Registration{
+		Name:    "capable",
+		Aliases: []string{"capable-alias"},
+		FactoryFunc: func(dsn string) (KVStore, error) {
+			return &testCapableStore{memoryKVStore: newMemoryKVStore(), dsn: dsn}, nil
+		},
+		Capabilities: DriverCapabilities{ReverseScan: true, EmptyValues: true},
+		Options:      OptionSchemas{{Name: "flag", Type: OptionTypeBool, Aliases: []string{"oldFlag"}}},
+	})
+}
+
+func TestSchemeOf(t *testing.T) {
+	tests := []struct {
+		dsn    string
+		expect string
+	}{
+		{"badger:///tmp/db", "badger"},
+		{"BigKV://project.instance/table", "bigkv"},
+		{"tikv://pd0,pd1:2379/prefix", "tikv"},
+		{"test", "test"},
+		{"test:%zz", "test"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.dsn, func(t *testing.T) {
+			assert.Equal(t, test.expect, schemeOf(test.dsn))
+		})
+	}
+}
+
+func TestNew_Alias(t *testing.T) {
+	kvStore, err := New("capable-alias://host/path?oldFlag=true")
+	require.NoError(t, err)
+	assert.Equal(t, "capable://host/path?flag=true", kvStore.(*testCapableStore).dsn)
+
+	_, err = New("unknown://host")
+	assert.EqualError(t, err, `no such kv store registered "unknown"`)
+
+	assert.Equal(t, ByName("capable"), ByName("capable-alias"))
+
+	count := 0
+	for _, reg := range Registrations() {
+		if reg.Name == "capable" {
+			count++
+		}
+	}
+	assert.Equal(t, 1, count, "aliases are not listed as drivers")
+}
+
+func TestCapabilities(t *testing.T) {
+	kvStore, err := New("capable://host?retry_max=2")
+	require.NoError(t, err)
+	require.IsType(t, &RetryableKVStore{}, kvStore)
+
+	assert.Equal(t, DriverCapabilities{ReverseScan: true, EmptyValues: true}, Capabilities(kvStore), "wrappers report the wrapped store capabilities")
+	assert.Equal(t, []string{"reverse_scan", "empty_values"}, Capabilities(kvStore).Names())
+	assert.Equal(t, DriverCapabilities{}, Capabilities(newMemoryKVStore()))
+}
+
+func TestCapabilitiesFromNames(t *testing.T) {
+	capabilities := DriverCapabilities{TTL: true, AtomicOps: true}
+	assert.Equal(t, capabilities, CapabilitiesFromNames(capabilities.Names()))
+	assert.Equal(t, DriverCapabilities{EmptyValues: true}, CapabilitiesFromNames([]string{"empty_values", "unknown"}))
+}
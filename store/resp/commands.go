@@ -0,0 +1,305 @@
+package resp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/streamingfast/kvdb/store"
+)
+
+const (
+	defaultScanCount = 10
+	// maxSessionCursors bounds the SCAN cursors kept per connection, the oldest ones
+	// being forgotten past it
+	maxSessionCursors = 1024
+)
+
+// session is the state of a client connection
+type session struct {
+	server *Server
+
+	// cursors continue the SCAN reads of the connection, Redis clients expect numeric
+	// cursors so store cursors are kept server side under an increasing id
+	cursors      map[uint64]*store.Cursor
+	cursorIDs    []uint64
+	lastCursorID uint64
+}
+
+type command struct {
+	// arity is the number of arguments, including the command name, negative meaning
+	// at least that many
+	arity   int
+	execute func(s *session, ctx context.Context, w *replyWriter, args [][]byte)
+}
+
+var commands = map[string]command{
+	"PING":    {-1, (*session).ping},
+	"ECHO":    {2, (*session).echo},
+	"SELECT":  {2, (*session).selectDB},
+	"COMMAND": {-1, func(_ *session, _ context.Context, w *replyWriter, _ [][]byte) { w.array(0) }},
+	"CLIENT":  {-2, func(_ *session, _ context.Context, w *replyWriter, _ [][]byte) { w.simple("OK") }},
+	"GET":     {2, (*session).get},
+	"SET":     {3, (*session).set},
+	"DEL":     {-2, (*session).del},
+	"EXISTS":  {-2, (*session).exists},
+	"MGET":    {-2, (*session).mget},
+	"SCAN":    {-2, (*session).scan},
+	"DBSIZE":  {1, (*session).dbsize},
+}
+
+// execute runs the command, returning true when the client asked to close the connection.
+func (s *session) execute(ctx context.Context, w *replyWriter, args [][]byte) (quit bool) {
+	name := strings.ToUpper(string(args[0]))
+	if name == "QUIT" {
+		w.simple("OK")
+		return true
+	}
+
+	cmd, found := commands[name]
+	if !found {
+		w.error("ERR unknown command '" + string(args[0]) + "'")
+		return false
+	}
+
+	if (cmd.arity > 0 && len(args) != cmd.arity) || (cmd.arity < 0 && len(args) < -cmd.arity) {
+		w.error("ERR wrong number of arguments for '" + strings.ToLower(name) + "' command")
+		return false
+	}
+
+	cmd.execute(s, ctx, w, args)
+	return false
+}
+
+func (s *session) ping(_ context.Context, w *replyWriter, args [][]byte) {
+	if len(args) > 1 {
+		w.bulk(args[1])
+		return
+	}
+	w.simple("PONG")
+}
+
+func (s *session) echo(_ context.Context, w *replyWriter, args [][]byte) {
+	w.bulk(args[1])
+}
+
+func (s *session) selectDB(_ context.Context, w *replyWriter, args [][]byte) {
+	if string(args[1]) != "0" {
+		w.error("ERR only database 0 is served")
+		return
+	}
+	w.simple("OK")
+}
+
+func (s *session) get(ctx context.Context, w *replyWriter, args [][]byte) {
+	value, err := s.server.kvStore.Get(ctx, args[1])
+	if errors.Is(err, store.ErrNotFound) {
+		w.bulk(nil)
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.bulk(nonNil(value))
+}
+
+// set only accepts a key and a value, options like expiration are not supported.
+func (s *session) set(ctx context.Context, w *replyWriter, args [][]byte) {
+	if err := s.server.kvStore.Put(ctx, args[1], args[2]); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if err := s.server.kvStore.FlushPuts(ctx); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.simple("OK")
+}
+
+// del replies with the number of keys that existed, a best-effort count: the stores
+// cannot delete and report what they deleted in one operation, so the keys are checked
+// before being deleted. A concurrent write between both steps is not accounted for, and
+// whether puts not flushed yet by another writer of the store count depends on the driver.
+func (s *session) del(ctx context.Context, w *replyWriter, args [][]byte) {
+	keys := args[1:]
+	exists, err := store.BatchExists(ctx, s.server.kvStore, keys)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	if err := s.server.kvStore.BatchDelete(ctx, keys); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.integer(countTrue(exists))
+}
+
+func (s *session) exists(ctx context.Context, w *replyWriter, args [][]byte) {
+	exists, err := store.BatchExists(ctx, s.server.kvStore, args[1:])
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.integer(countTrue(exists))
+}
+
+// mget reads keys one by one, so that missing keys are reported in place whatever the
+// driver's `BatchGet` does with them.
+func (s *session) mget(ctx context.Context, w *replyWriter, args [][]byte) {
+	values := make([][]byte, len(args)-1)
+	for i, key := range args[1:] {
+		value, err := s.server.kvStore.Get(ctx, key)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			writeStoreError(w, err)
+			return
+		}
+		if err == nil {
+			values[i] = nonNil(value)
+		}
+	}
+
+	w.array(len(values))
+	for _, value := range values {
+		w.bulk(value)
+	}
+}
+
+// scan reads the keys starting with the MATCH prefix, all keys without it, a page of
+// COUNT keys at a time. MATCH is only honored when starting a new scan, the cursor
+// carrying it afterwards.
+func (s *session) scan(ctx context.Context, w *replyWriter, args [][]byte) {
+	cursorID, err := strconv.ParseUint(string(args[1]), 10, 64)
+	if err != nil {
+		w.error("ERR invalid cursor")
+		return
+	}
+
+	var prefix []byte
+	count := defaultScanCount
+	for i := 2; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			w.error("ERR syntax error")
+			return
+		}
+
+		switch strings.ToUpper(string(args[i])) {
+		case "MATCH":
+			var ok bool
+			if prefix, ok = matchPrefix(args[i+1]); !ok {
+				w.error("ERR only prefix patterns, like 'prefix*', are supported by MATCH")
+				return
+			}
+		case "COUNT":
+			if count, err = strconv.Atoi(string(args[i+1])); err != nil || count <= 0 {
+				w.error("ERR value is not an integer or out of range")
+				return
+			}
+		default:
+			w.error("ERR syntax error")
+			return
+		}
+	}
+
+	var it *store.Iterator
+	if cursorID == 0 {
+		it = s.server.kvStore.Prefix(ctx, prefix, count, store.KeyOnly())
+	} else {
+		cursor, found := s.cursors[cursorID]
+		if !found {
+			w.error("ERR invalid cursor")
+			return
+		}
+		delete(s.cursors, cursorID)
+		it = store.ReadFromCursor(ctx, s.server.kvStore, cursor, count)
+	}
+
+	var keys [][]byte
+	for it.Next() {
+		keys = append(keys, it.Item().Key)
+	}
+	if err := it.Err(); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	next := uint64(0)
+	if cursor := it.Cursor(); cursor != nil {
+		next = s.saveCursor(cursor)
+	}
+
+	w.array(2)
+	w.bulk([]byte(strconv.FormatUint(next, 10)))
+	w.array(len(keys))
+	for _, key := range keys {
+		w.bulk(key)
+	}
+}
+
+func (s *session) saveCursor(cursor *store.Cursor) uint64 {
+	s.lastCursorID++
+	s.cursors[s.lastCursorID] = cursor
+	s.cursorIDs = append(s.cursorIDs, s.lastCursorID)
+
+	for len(s.cursorIDs) > maxSessionCursors {
+		delete(s.cursors, s.cursorIDs[0])
+		s.cursorIDs = s.cursorIDs[1:]
+	}
+
+	return s.lastCursorID
+}
+
+func (s *session) dbsize(ctx context.Context, w *replyWriter, _ [][]byte) {
+	count, err := store.CountPrefix(ctx, s.server.kvStore, nil)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.integer(int64(count))
+}
+
+// matchPrefix returns the prefix matched by a glob pattern made of literal characters
+// followed by a single `*`, false for other patterns.
+func matchPrefix(pattern []byte) ([]byte, bool) {
+	literal := bytes.TrimSuffix(pattern, []byte("*"))
+	if bytes.ContainsAny(literal, `*?[]\`) {
+		return nil, false
+	}
+	if len(literal) == len(pattern) {
+		// A literal pattern only matches the key itself, a prefix read of it returns
+		// the keys it prefixes too
+		return nil, false
+	}
+
+	return literal, true
+}
+
+func writeStoreError(w *replyWriter, err error) {
+	w.error("ERR " + strings.ReplaceAll(err.Error(), "\n", " "))
+}
+
+func countTrue(values []bool) (count int64) {
+	for _, value := range values {
+		if value {
+			count++
+		}
+	}
+	return
+}
+
+// nonNil turns nil values, which some drivers return for empty values, into empty ones
+// as nil is the RESP null reply.
+func nonNil(value []byte) []byte {
+	if value == nil {
+		return []byte{}
+	}
+	return value
+}
@@ -0,0 +1,159 @@
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	// maxCommandBytes is the largest total size of the bulk strings of a command, far below
+	// the 512MiB of Redis since a command is held in memory before it runs
+	maxCommandBytes = 32 * 1024 * 1024
+	// maxArgs is the largest count of arguments accepted in a command
+	maxArgs = 64 * 1024
+	// maxInlineBytes is the longest inline command accepted
+	maxInlineBytes = 64 * 1024
+)
+
+// protocolError is a malformed request, the connection is closed after reporting it
+type protocolError struct {
+	msg string
+}
+
+func (e *protocolError) Error() string {
+	return "Protocol error: " + e.msg
+}
+
+// readCommand reads the next command, either a RESP array of bulk strings as sent by
+// client libraries, or an inline command made of space separated arguments as typed
+// in a telnet session. An empty inline command, or an empty array, returns no argument.
+func readCommand(r *bufio.Reader) ([][]byte, error) {
+	prefix, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if prefix[0] != '*' {
+		line, err := readLine(r, maxInlineBytes)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.Fields(line), nil
+	}
+
+	line, err := readLine(r, maxInlineBytes)
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(string(line[1:]))
+	if err != nil || count > maxArgs {
+		return nil, &protocolError{"invalid multibulk length"}
+	}
+	if count <= 0 {
+		// Like Redis, an empty or null array is an empty command
+		return nil, nil
+	}
+
+	// Arguments and their bytes are allocated as they are received, so that a client
+	// announcing large sizes does not get the memory without sending the data
+	var args [][]byte
+	budget := maxCommandBytes
+	for i := 0; i < count; i++ {
+		line, err := readLine(r, maxInlineBytes)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, &protocolError{fmt.Sprintf("expected '$', got '%c'", firstByte(line))}
+		}
+
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil || size < 0 {
+			return nil, &protocolError{"invalid bulk length"}
+		}
+		if size > budget {
+			return nil, &protocolError{fmt.Sprintf("command larger than %d bytes", maxCommandBytes)}
+		}
+		budget -= size
+
+		var arg bytes.Buffer
+		if _, err := io.CopyN(&arg, r, int64(size)+2); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		if !bytes.HasSuffix(arg.Bytes(), []byte("\r\n")) {
+			return nil, &protocolError{"bulk string not terminated by CRLF"}
+		}
+		args = append(args, arg.Bytes()[:size])
+	}
+
+	return args, nil
+}
+
+// readLine reads a line terminated by LF, or CRLF, without its terminator.
+func readLine(r *bufio.Reader, maxBytes int) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := r.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+
+		line = append(line, chunk...)
+		if len(line) > maxBytes {
+			return nil, &protocolError{"too big inline request"}
+		}
+		if !isPrefix {
+			return line, nil
+		}
+	}
+}
+
+func firstByte(line []byte) byte {
+	if len(line) == 0 {
+		return ' '
+	}
+	return line[0]
+}
+
+// replyWriter writes RESP2 replies, errors are sticky and reported by `Flush`.
+type replyWriter struct {
+	w *bufio.Writer
+}
+
+func (w *replyWriter) simple(s string) {
+	w.w.WriteString("+" + s + "\r\n")
+}
+
+func (w *replyWriter) error(msg string) {
+	w.w.WriteString("-" + msg + "\r\n")
+}
+
+func (w *replyWriter) integer(n int64) {
+	w.w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+// bulk writes the bytes as a bulk string, nil being written as the null bulk string.
+func (w *replyWriter) bulk(b []byte) {
+	if b == nil {
+		w.w.WriteString("$-1\r\n")
+		return
+	}
+
+	w.w.WriteString("$" + strconv.Itoa(len(b)) + "\r\n")
+	w.w.Write(b)
+	w.w.WriteString("\r\n")
+}
+
+func (w *replyWriter) array(size int) {
+	w.w.WriteString("*" + strconv.Itoa(size) + "\r\n")
+}
+
+func (w *replyWriter) Flush() error {
+	return w.w.Flush()
+}
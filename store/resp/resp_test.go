@@ -0,0 +1,160 @@
+package resp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/streamingfast/kvdb/store"
+	_ "github.com/streamingfast/kvdb/store/badger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func newTestClient(t *testing.T) *testClient {
+	kvStore, err := store.New(fmt.Sprintf("badger://%s", filepath.Join(t.TempDir(), "db")))
+	require.NoError(t, err)
+	t.Cleanup(func() { kvStore.Close() })
+
+	server, err := Launch("127.0.0.1:0", kvStore)
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	conn, err := net.Dial("tcp", server.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return &testClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// do sends the command as a RESP array and returns the reply, arrays being returned as
+// []interface{}, bulk strings as string and null bulk strings as nil.
+func (c *testClient) do(args ...string) interface{} {
+	request := fmt.Sprintf("*%d\r\n", len(args))
+	for _, arg := range args {
+		request += fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := c.conn.Write([]byte(request))
+	require.NoError(c.t, err)
+
+	return c.read()
+}
+
+func (c *testClient) read() interface{} {
+	line, err := c.reader.ReadString('\n')
+	require.NoError(c.t, err)
+	line = strings.TrimSuffix(line, "\r\n")
+
+	switch line[0] {
+	case '+', '-':
+		return line
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		require.NoError(c.t, err)
+		return n
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		require.NoError(c.t, err)
+		if size < 0 {
+			return nil
+		}
+		out := make([]byte, size+2)
+		_, err = io.ReadFull(c.reader, out)
+		require.NoError(c.t, err)
+		return string(out[:size])
+	case '*':
+		size, err := strconv.Atoi(line[1:])
+		require.NoError(c.t, err)
+		out := []interface{}{}
+		for i := 0; i < size; i++ {
+			out = append(out, c.read())
+		}
+		return out
+	}
+
+	c.t.Fatalf("unexpected reply %q", line)
+	return nil
+}
+
+func TestCommands(t *testing.T) {
+	client := newTestClient(t)
+
+	assert.Equal(t, "+PONG", client.do("PING"))
+	assert.Equal(t, "+OK", client.do("SELECT", "0"))
+	assert.Equal(t, "+OK", client.do("set", "a", "1"))
+	assert.Equal(t, "+OK", client.do("SET", "b", "2"))
+	assert.Equal(t, "1", client.do("GET", "a"))
+	assert.Nil(t, client.do("GET", "missing"))
+	assert.Equal(t, []interface{}{"1", nil, "2"}, client.do("MGET", "a", "missing", "b"))
+	assert.Equal(t, int64(2), client.do("EXISTS", "a", "b", "missing"))
+	assert.Equal(t, int64(2), client.do("DBSIZE"))
+	assert.Equal(t, int64(1), client.do("DEL", "a", "missing"))
+	assert.Equal(t, int64(1), client.do("DBSIZE"))
+
+	assert.Equal(t, "-ERR unknown command 'FLUSHALL'", client.do("FLUSHALL"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'get' command", client.do("GET"))
+
+	_, err := client.conn.Write([]byte("GET b\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "2", client.read(), "inline command")
+
+	_, err = client.conn.Write([]byte("*-1\r\n*0\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "2", client.do("GET", "b"), "empty and null arrays are ignored")
+
+	assert.Equal(t, "+OK", client.do("QUIT"))
+}
+
+func TestProtocolErrors(t *testing.T) {
+	client := newTestClient(t)
+
+	_, err := client.conn.Write([]byte("*2\r\n$3\r\nGET\r\n$1000000000\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "-ERR Protocol error: command larger than 33554432 bytes", client.read(), "announced size is checked before reading")
+
+	_, err = client.reader.ReadString('\n')
+	assert.Equal(t, io.EOF, err, "connection is closed")
+}
+
+func TestScan(t *testing.T) {
+	client := newTestClient(t)
+
+	for i := 0; i < 25; i++ {
+		require.Equal(t, "+OK", client.do("SET", fmt.Sprintf("blk:%02d", i), "v"))
+	}
+	require.Equal(t, "+OK", client.do("SET", "other", "v"))
+
+	var keys []interface{}
+	cursor := "0"
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10)
+
+		reply := client.do("SCAN", cursor, "MATCH", "blk:*", "COUNT", "10").([]interface{})
+		cursor = reply[0].(string)
+		keys = append(keys, reply[1].([]interface{})...)
+		if cursor == "0" {
+			break
+		}
+	}
+
+	require.Len(t, keys, 25)
+	assert.Equal(t, "blk:00", keys[0])
+	assert.Equal(t, "blk:24", keys[24])
+
+	reply := client.do("SCAN", "0").([]interface{})
+	assert.Len(t, reply[1], 10, "default count")
+
+	assert.Equal(t, "-ERR only prefix patterns, like 'prefix*', are supported by MATCH", client.do("SCAN", "0", "MATCH", "b?k*"))
+	assert.Equal(t, "-ERR invalid cursor", client.do("SCAN", "42"))
+}
@@ -0,0 +1,136 @@
+// Package resp serves any `store.KVStore` over a subset of the Redis protocol (RESP2),
+// so that redis-cli and Redis client libraries can be used against kvdb stores for
+// debugging and lightweight integrations.
+//
+// Supported commands are GET, SET, DEL, EXISTS, MGET, SCAN (MATCH limited to prefix
+// patterns like `blk:*`) and DBSIZE, along PING, ECHO, SELECT 0, QUIT and the
+// connection setup commands sent by client libraries.
+package resp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/streamingfast/kvdb/store"
+)
+
+type Server struct {
+	kvStore  store.KVStore
+	listener net.Listener
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	lock  sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// Launch serves the store on the listen address until `Close` is called, the store is
+// not closed by the server.
+func Launch(listenAddr string, kvStore store.KVStore) (*Server, error) {
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed listening: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		kvStore:  kvStore,
+		listener: listener,
+		ctx:      ctx,
+		cancel:   cancel,
+		conns:    map[net.Conn]struct{}{},
+	}
+
+	s.wg.Add(1)
+	go s.accept()
+
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Close stops accepting connections and closes the open ones, commands in progress
+// are canceled.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.cancel()
+
+	s.lock.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.lock.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.lock.Lock()
+		if s.ctx.Err() != nil {
+			s.lock.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.lock.Unlock()
+
+		go s.serve(conn)
+	}
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.lock.Lock()
+		delete(s.conns, conn)
+		s.lock.Unlock()
+		conn.Close()
+	}()
+
+	session := &session{server: s, cursors: map[uint64]*store.Cursor{}}
+	reader := bufio.NewReader(conn)
+	writer := &replyWriter{w: bufio.NewWriter(conn)}
+
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			var protocolErr *protocolError
+			if errors.As(err, &protocolErr) {
+				writer.error("ERR " + protocolErr.Error())
+				writer.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		quit := session.execute(s.ctx, writer, args)
+
+		// Pipelined commands are answered together
+		if reader.Buffered() == 0 || quit {
+			if err := writer.Flush(); err != nil || quit {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/streamingfast/logging"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RetryableErrorClassifier is implemented by store drivers that are able to tell
+// if an error returned by their backend is transient and if the operation that
+// produced it can be safely tried again.
+type RetryableErrorClassifier interface {
+	IsRetryableError(err error) bool
+}
+
+// RetryPolicy defines how many times and how fast an operation failing with a
+// retryable error is tried again.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of consecutive retries performed before giving
+	// up, a value of 0 disables retries.
+	MaxRetries int
+	// Backoff is the delay before the first retry, it doubles on each subsequent
+	// consecutive retry up to MaxBackoff.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between two retries.
+	MaxBackoff time.Duration
+}
+
+const (
+	retryMaxDSNOption     = "retry_max"
+	retryBackoffDSNOption = "retry_backoff"
+
+	defaultRetryBackoff    = 250 * time.Millisecond
+	defaultRetryMaxBackoff = 10 * time.Second
+)
+
+// retryPolicyFromDSN extracts the retry policy from the DSN query parameters
+// `retry_max` and `retry_backoff`. It returns a nil policy when retries are not
+// configured, the DSN returned has the retry parameters removed from it.
+func retryPolicyFromDSN(dsn string) (*RetryPolicy, string, error) {
+	dsnURL, err := url.Parse(dsn)
+	if err != nil {
+		// Not all drivers use a DSN that can be parsed as an URL, in which case retry parameters are not supported
+		return nil, dsn, nil
+	}
+
+	query := DSNQuery(dsnURL.Query())
+	if _, found := query[retryMaxDSNOption]; !found {
+		if _, found := query[retryBackoffDSNOption]; !found {
+			return nil, dsn, nil
+		}
+	}
+
+	maxRetries, rawValue, err := query.IntOption(retryMaxDSNOption, 0)
+	if err != nil || maxRetries < 0 {
+		return nil, "", fmt.Errorf("retry max option %q is not a valid positive number", rawValue)
+	}
+
+	backoff, rawValue, err := query.DurationOption(retryBackoffDSNOption, defaultRetryBackoff)
+	if err != nil || backoff < 0 {
+		return nil, "", fmt.Errorf("retry backoff option %q is not a valid positive duration", rawValue)
+	}
+
+	cleanedDSN := RemoveDSNOptionsFromURL(dsnURL, retryMaxDSNOption, retryBackoffDSNOption).String()
+	if maxRetries == 0 {
+		return nil, cleanedDSN, nil
+	}
+
+	return &RetryPolicy{MaxRetries: maxRetries, Backoff: backoff, MaxBackoff: defaultRetryMaxBackoff}, cleanedDSN, nil
+}
+
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 0; i < attempt; i++ {
+		delay *= 2
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+
+	return delay
+}
+
+func (p *RetryPolicy) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt("max_retries", p.MaxRetries)
+	enc.AddDuration("backoff", p.Backoff)
+	enc.AddDuration("max_backoff", p.MaxBackoff)
+	return nil
+}
+
+// RetryableKVStore wraps a KVStore and transparently retries idempotent operations
+// failing with an error the wrapped store classifies as retryable (see
+// RetryableErrorClassifier). Iterators are resumed right after the last key they
+// emitted instead of being restarted from the beginning.
+//
+// Only stores implementing RetryableErrorClassifier are ever retried, errors of
+// other stores are always returned as-is.
+type RetryableKVStore struct {
+	KVStore
+
+	policy     *RetryPolicy
+	classifier RetryableErrorClassifier
+}
+
+func NewRetryableStore(store KVStore, policy *RetryPolicy) *RetryableKVStore {
+	classifier, _ := store.(RetryableErrorClassifier)
+
+	return &RetryableKVStore{
+		KVStore:    store,
+		policy:     policy,
+		classifier: classifier,
+	}
+}
+
+func (s *RetryableKVStore) EnableEmpty() {
+	if f, ok := s.KVStore.(EmtpyValueEnabler); ok {
+		f.EnableEmpty()
+	}
+}
+
+func (s *RetryableKVStore) Capabilities() DriverCapabilities {
+	return Capabilities(s.KVStore)
+}
+
+func (s *RetryableKVStore) SetFlushErrorHandler(onError func(err error)) {
+	if f, ok := s.KVStore.(FlushErrorHandlerSetter); ok {
+		f.SetFlushErrorHandler(onError)
+	}
+}
+
+func (s *RetryableKVStore) Put(ctx context.Context, key, value []byte) error {
+	return s.retry(ctx, "put", func() error {
+		return s.KVStore.Put(ctx, key, value)
+	})
+}
+
+func (s *RetryableKVStore) FlushPuts(ctx context.Context) error {
+	return s.retry(ctx, "flush puts", func() error {
+		return s.KVStore.FlushPuts(ctx)
+	})
+}
+
+func (s *RetryableKVStore) Get(ctx context.Context, key []byte) (value []byte, err error) {
+	err = s.retry(ctx, "get", func() (err error) {
+		value, err = s.KVStore.Get(ctx, key)
+		return err
+	})
+
+	return
+}
+
+func (s *RetryableKVStore) Exists(ctx context.Context, key []byte) (exists bool, err error) {
+	err = s.retry(ctx, "exists", func() (err error) {
+		exists, err = Exists(ctx, s.KVStore, key)
+		return err
+	})
+
+	return
+}
+
+func (s *RetryableKVStore) BatchExists(ctx context.Context, keys [][]byte) (exists []bool, err error) {
+	err = s.retry(ctx, "batch exists", func() (err error) {
+		exists, err = BatchExists(ctx, s.KVStore, keys)
+		return err
+	})
+
+	return
+}
+
+func (s *RetryableKVStore) Delete(ctx context.Context, key []byte) error {
+	return s.retry(ctx, "delete", func() error {
+		return Delete(ctx, s.KVStore, key)
+	})
+}
+
+func (s *RetryableKVStore) BatchDelete(ctx context.Context, keys [][]byte) error {
+	return s.retry(ctx, "batch delete", func() error {
+		return s.KVStore.BatchDelete(ctx, keys)
+	})
+}
+
+func (s *RetryableKVStore) BatchGet(ctx context.Context, keys [][]byte) *Iterator {
+	return s.iterate(ctx, NewIterator(ctx), Limit(Unlimited), readSegment{kind: batchGetSegment, keys: keys})
+}
+
+func (s *RetryableKVStore) Scan(ctx context.Context, start, exclusiveEnd []byte, limit int, options ...ReadOption) *Iterator {
+	return s.iterate(ctx, NewScanIterator(ctx, start, exclusiveEnd, limit, options), Limit(limit), readSegment{kind: scanSegment, start: start, end: exclusiveEnd, options: options})
+}
+
+func (s *RetryableKVStore) Prefix(ctx context.Context, prefix []byte, limit int, options ...ReadOption) *Iterator {
+	return s.iterate(ctx, NewPrefixIterator(ctx, prefix, limit, options), Limit(limit), readSegment{kind: prefixSegment, prefixes: [][]byte{prefix}, options: options})
+}
+
+func (s *RetryableKVStore) BatchPrefix(ctx context.Context, prefixes [][]byte, limit int, options ...ReadOption) *Iterator {
+	return s.iterate(ctx, NewBatchPrefixIterator(ctx, prefixes, limit, options), Limit(limit), readSegment{kind: batchPrefixSegment, prefixes: prefixes, options: options})
+}
+
+func (s *RetryableKVStore) Count(ctx context.Context, start, exclusiveEnd []byte) (count uint64, err error) {
+	err = s.retry(ctx, "count", func() (err error) {
+		count, err = Count(ctx, s.KVStore, start, exclusiveEnd)
+		return err
+	})
+
+	return
+}
+
+func (s *RetryableKVStore) CountPrefix(ctx context.Context, prefix []byte) (count uint64, err error) {
+	err = s.retry(ctx, "count prefix", func() (err error) {
+		count, err = CountPrefix(ctx, s.KVStore, prefix)
+		return err
+	})
+
+	return
+}
+
+func (s *RetryableKVStore) ApproximateSize(ctx context.Context, start, exclusiveEnd []byte) (size uint64, err error) {
+	err = s.retry(ctx, "approximate size", func() (err error) {
+		size, err = ApproximateSize(ctx, s.KVStore, start, exclusiveEnd)
+		return err
+	})
+
+	return
+}
+
+func (s *RetryableKVStore) SplitRange(ctx context.Context, start, exclusiveEnd []byte, approxChunks int) (splits [][]byte, err error) {
+	err = s.retry(ctx, "split range", func() (err error) {
+		splits, err = SplitRange(ctx, s.KVStore, start, exclusiveEnd, approxChunks)
+		return err
+	})
+
+	return
+}
+
+func (s *RetryableKVStore) DeleteRange(ctx context.Context, start, exclusiveEnd []byte) error {
+	return s.retry(ctx, "delete range", func() error {
+		return DeleteRange(ctx, s.KVStore, start, exclusiveEnd)
+	})
+}
+
+func (s *RetryableKVStore) DeletePrefix(ctx context.Context, prefix []byte) error {
+	return s.retry(ctx, "delete prefix", func() error {
+		return DeletePrefix(ctx, s.KVStore, prefix)
+	})
+}
+
+func (s *RetryableKVStore) shouldRetry(ctx context.Context, err error, attempt int) bool {
+	if s.classifier == nil || attempt >= s.policy.MaxRetries {
+		return false
+	}
+
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, ErrNotFound) {
+		return false
+	}
+
+	return s.classifier.IsRetryableError(err)
+}
+
+// wait sleeps for the backoff delay of the given attempt, returning false if the
+// context completes before.
+func (s *RetryableKVStore) wait(ctx context.Context, attempt int) bool {
+	timer := time.NewTimer(s.policy.delay(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (s *RetryableKVStore) retry(ctx context.Context, operation string, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !s.shouldRetry(ctx, err, attempt) {
+			return err
+		}
+
+		logging.Logger(ctx, zlog).Debug("retrying operation after retryable error",
+			zap.String("operation", operation),
+			zap.Int("attempt", attempt+1),
+			zap.Error(err),
+		)
+
+		if !s.wait(ctx, attempt) {
+			return err
+		}
+	}
+}
+
+// iterate reads the segment into the returned iterator, resuming it right after the
+// last emitted key on retryable errors. The consecutive attempts counter is reset as
+// soon as progress is made.
+func (s *RetryableKVStore) iterate(ctx context.Context, out *Iterator, limit Limit, segment readSegment) *Iterator {
+	go func() {
+		zlogger := logging.Logger(ctx, zlog)
+		attempt := 0
+
+		readSegments(ctx, s.KVStore, out, limit, []readSegment{segment}, func(err error, lastKey []byte, progressed bool) bool {
+			if progressed {
+				attempt = 0
+			}
+
+			if !s.shouldRetry(ctx, err, attempt) {
+				return false
+			}
+
+			zlogger.Debug("resuming iteration after retryable error",
+				zap.Int("attempt", attempt+1),
+				zap.Stringer("last_key", Key(lastKey)),
+				zap.Error(err),
+			)
+
+			if !s.wait(ctx, attempt) {
+				return false
+			}
+
+			attempt++
+			return true
+		})
+	}()
+
+	return out
+}
+
+func (s *RetryableKVStore) Compact(ctx context.Context) error {
+	return s.retry(ctx, "compact", func() error {
+		return Compact(ctx, s.KVStore)
+	})
+}
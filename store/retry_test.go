@@ -0,0 +1,211 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTransient = errors.New("transient")
+
+// flakyKVStore emits at most `failAfter` items per read call before failing with
+// a transient error, for the first `failures` read calls.
+type flakyKVStore struct {
+	*memoryKVStore
+
+	failures  int
+	failAfter int
+	calls     []string
+}
+
+func (s *flakyKVStore) IsRetryableError(err error) bool {
+	return errors.Is(err, errTransient)
+}
+
+func (s *flakyKVStore) Get(ctx context.Context, key []byte) ([]byte, error) {
+	s.calls = append(s.calls, "get")
+	if s.failures > 0 {
+		s.failures--
+		return nil, errTransient
+	}
+	return s.memoryKVStore.Get(ctx, key)
+}
+
+func (s *flakyKVStore) Scan(ctx context.Context, start, exclusiveEnd []byte, limit int, options ...ReadOption) *Iterator {
+	s.calls = append(s.calls, "scan:"+string(start)+":"+string(exclusiveEnd))
+	return s.flaky(ctx, s.memoryKVStore.Scan(ctx, start, exclusiveEnd, limit, options...))
+}
+
+func (s *flakyKVStore) Prefix(ctx context.Context, prefix []byte, limit int, options ...ReadOption) *Iterator {
+	s.calls = append(s.calls, "prefix:"+string(prefix))
+	return s.flaky(ctx, s.memoryKVStore.Prefix(ctx, prefix, limit, options...))
+}
+
+func (s *flakyKVStore) BatchPrefix(ctx context.Context, prefixes [][]byte, limit int, options ...ReadOption) *Iterator {
+	s.calls = append(s.calls, "batch_prefix:"+string(prefixes[0]))
+	return s.flaky(ctx, s.memoryKVStore.BatchPrefix(ctx, prefixes, limit, options...))
+}
+
+func (s *flakyKVStore) BatchGet(ctx context.Context, keys [][]byte) *Iterator {
+	s.calls = append(s.calls, "batch_get:"+string(keys[0]))
+	return s.flaky(ctx, s.memoryKVStore.BatchGet(ctx, keys))
+}
+
+func (s *flakyKVStore) flaky(ctx context.Context, source *Iterator) *Iterator {
+	if s.failures <= 0 {
+		return source
+	}
+	s.failures--
+
+	it := NewIterator(ctx)
+	go func() {
+		for i := 0; i < s.failAfter && source.Next(); i++ {
+			it.PushItem(source.Item())
+		}
+
+		// Ensures the consumer received all items before the error, which could otherwise win the race
+		for len(it.items) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		it.PushError(errTransient)
+	}()
+	return it
+}
+
+func newTestFlakyStore(failures, failAfter int) *flakyKVStore {
+	return &flakyKVStore{
+		memoryKVStore: newMemoryKVStore(
+			KV{Key: []byte("a"), Value: []byte("1")},
+			KV{Key: []byte("ba"), Value: []byte("2")},
+			KV{Key: []byte("ba1"), Value: []byte("3")},
+			KV{Key: []byte("ba2"), Value: []byte("4")},
+			KV{Key: []byte("bb"), Value: []byte("5")},
+			KV{Key: []byte("c"), Value: []byte("6")},
+		),
+		failures:  failures,
+		failAfter: failAfter,
+	}
+}
+
+func testRetryPolicy(maxRetries int) *RetryPolicy {
+	return &RetryPolicy{MaxRetries: maxRetries, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+}
+
+func testCollectKeys(t *testing.T, it *Iterator) (out []string, err error) {
+	t.Helper()
+
+	for it.Next() {
+		out = append(out, string(it.Item().Key))
+	}
+	return out, it.Err()
+}
+
+func TestRetryableKVStore_Get(t *testing.T) {
+	flaky := newTestFlakyStore(2, 0)
+	value, err := NewRetryableStore(flaky, testRetryPolicy(2)).Get(context.Background(), []byte("a"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+	assert.Len(t, flaky.calls, 3)
+
+	flaky = newTestFlakyStore(3, 0)
+	_, err = NewRetryableStore(flaky, testRetryPolicy(2)).Get(context.Background(), []byte("a"))
+	assert.Equal(t, errTransient, err)
+
+	flaky = newTestFlakyStore(0, 0)
+	_, err = NewRetryableStore(flaky, testRetryPolicy(2)).Get(context.Background(), []byte("z"))
+	assert.Equal(t, ErrNotFound, err)
+	assert.Len(t, flaky.calls, 1)
+}
+
+func TestRetryableKVStore_Iterators(t *testing.T) {
+	tests := []struct {
+		name          string
+		failures      int
+		failAfter     int
+		read          func(s KVStore) *Iterator
+		expectedKeys  []string
+		expectedCalls []string
+		expectedErr   error
+	}{
+		{
+			name: "scan resumes after last key", failures: 2, failAfter: 2,
+			read:          func(s KVStore) *Iterator { return s.Scan(context.Background(), []byte("a"), []byte("c"), Unlimited) },
+			expectedKeys:  []string{"a", "ba", "ba1", "ba2", "bb"},
+			expectedCalls: []string{"scan:a:c", "scan:ba\x00:c", "scan:ba2\x00:c"},
+		},
+		{
+			name: "scan respects limit across attempts", failures: 1, failAfter: 2,
+			read:          func(s KVStore) *Iterator { return s.Scan(context.Background(), []byte("a"), []byte("c"), 3) },
+			expectedKeys:  []string{"a", "ba", "ba1"},
+			expectedCalls: []string{"scan:a:c", "scan:ba\x00:c"},
+		},
+		{
+			name: "prefix resumes as scan", failures: 1, failAfter: 1,
+			read:          func(s KVStore) *Iterator { return s.Prefix(context.Background(), []byte("b"), Unlimited) },
+			expectedKeys:  []string{"ba", "ba1", "ba2", "bb"},
+			expectedCalls: []string{"prefix:b", "scan:ba\x00:c"},
+		},
+		{
+			name: "empty prefix resumes by skipping", failures: 1, failAfter: 2,
+			read:          func(s KVStore) *Iterator { return s.Prefix(context.Background(), nil, Unlimited) },
+			expectedKeys:  []string{"a", "ba", "ba1", "ba2", "bb", "c"},
+			expectedCalls: []string{"prefix:", "prefix:"},
+		},
+		{
+			name: "batch prefix resumes current prefix then next ones", failures: 1, failAfter: 2,
+			read: func(s KVStore) *Iterator {
+				return s.BatchPrefix(context.Background(), [][]byte{[]byte("ba"), []byte("c")}, Unlimited)
+			},
+			expectedKeys:  []string{"ba", "ba1", "ba2", "c"},
+			expectedCalls: []string{"batch_prefix:ba", "scan:ba1\x00:bb", "batch_prefix:c"},
+		},
+		{
+			name: "batch get resumes remaining keys", failures: 1, failAfter: 1,
+			read: func(s KVStore) *Iterator {
+				return s.BatchGet(context.Background(), [][]byte{[]byte("c"), []byte("a")})
+			},
+			expectedKeys:  []string{"c", "a"},
+			expectedCalls: []string{"batch_get:c", "batch_get:a"},
+		},
+		{
+			name: "gives up after max consecutive retries", failures: 4, failAfter: 0,
+			read:          func(s KVStore) *Iterator { return s.Scan(context.Background(), []byte("a"), []byte("c"), Unlimited) },
+			expectedCalls: []string{"scan:a:c", "scan:a:c", "scan:a:c", "scan:a:c"},
+			expectedErr:   errTransient,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flaky := newTestFlakyStore(test.failures, test.failAfter)
+			keys, err := testCollectKeys(t, test.read(NewRetryableStore(flaky, testRetryPolicy(3))))
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expectedKeys, keys)
+			}
+			assert.Equal(t, test.expectedCalls, flaky.calls)
+		})
+	}
+}
+
+func TestRetryPolicyFromDSN(t *testing.T) {
+	policy, dsn, err := retryPolicyFromDSN("test:///path?retry_max=3&retry_backoff=1s&other=1")
+	require.NoError(t, err)
+	assert.Equal(t, &RetryPolicy{MaxRetries: 3, Backoff: time.Second, MaxBackoff: defaultRetryMaxBackoff}, policy)
+	assert.Equal(t, "test:///path?other=1", dsn)
+
+	policy, dsn, err = retryPolicyFromDSN("test:///path?other=1")
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+	assert.Equal(t, "test:///path?other=1", dsn)
+
+	_, _, err = retryPolicyFromDSN("test:///path?retry_max=abc")
+	require.Error(t, err)
+}
@@ -0,0 +1,237 @@
+package store
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type OptionType string
+
+const (
+	OptionTypeString   OptionType = "string"
+	OptionTypeInt      OptionType = "int"
+	OptionTypeBool     OptionType = "bool"
+	OptionTypeDuration OptionType = "duration"
+	OptionTypeHex      OptionType = "hex"
+)
+
+// OptionSchema declares a DSN query parameter accepted by a driver.
+type OptionSchema struct {
+	Name        string
+	Type        OptionType
+	Default     string
+	Description string
+	// Aliases are other names accepted for the option, usually former names kept for
+	// backward compatibility, the driver always receives the option under `Name`.
+	Aliases []string
+	// Values restricts the accepted values, any value of `Type` is accepted when empty.
+	Values []string
+}
+
+// Validate checks that the raw value is a valid value for the option, an empty value is
+// always valid and means the option is not set.
+func (o *OptionSchema) Validate(rawValue string) error {
+	if rawValue == "" {
+		return nil
+	}
+
+	if len(o.Values) > 0 {
+		for _, value := range o.Values {
+			if rawValue == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid value %q for option %q, accepted values are %s", rawValue, o.Name, strings.Join(quoteAll(o.Values), ", "))
+	}
+
+	var err error
+	switch o.Type {
+	case OptionTypeInt:
+		_, err = strconv.Atoi(rawValue)
+	case OptionTypeBool:
+		_, err = parseBool(rawValue)
+	case OptionTypeDuration:
+		_, err = time.ParseDuration(rawValue)
+	case OptionTypeHex:
+		_, err = hex.DecodeString(rawValue)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s value %q for option %q: %w", o.Type, rawValue, o.Name, err)
+	}
+	return nil
+}
+
+type OptionSchemas []*OptionSchema
+
+// Find returns the option having the name, or the alias, received, nil if none does.
+func (s OptionSchemas) Find(name string) *OptionSchema {
+	for _, option := range s {
+		if option.Name == name {
+			return option
+		}
+
+		for _, alias := range option.Aliases {
+			if alias == name {
+				return option
+			}
+		}
+	}
+	return nil
+}
+
+// globalOptionSchemas are the options handled by `New` itself, accepted by all drivers.
+var globalOptionSchemas = OptionSchemas{
+	{Name: retryMaxDSNOption, Type: OptionTypeInt, Default: "0", Description: "Maximum consecutive retries of idempotent operations failing with a transient error, 0 disables retries"},
+	{Name: retryBackoffDSNOption, Type: OptionTypeDuration, Default: defaultRetryBackoff.String(), Description: "Delay before the first retry, doubled on each consecutive retry"},
+}
+
+// WriteBufferOptionSchemas returns the schemas of the options read by `NewWriteBufferOptions`,
+// with the driver's defaults.
+func WriteBufferOptionSchemas(defaults WriteBufferOptions) OptionSchemas {
+	return OptionSchemas{
+		{Name: BatchSizeThresholdOption, Type: OptionTypeInt, Default: strconv.Itoa(defaults.SizeThreshold), Description: "Bytes of pending puts (keys and values) above which they are flushed, 0 for no limit"},
+		{Name: BatchOpsThresholdOption, Type: OptionTypeInt, Default: strconv.Itoa(defaults.OpsThreshold), Description: "Count of pending puts at which they are flushed, 0 for no limit"},
+		{Name: BatchTimeThresholdOption, Type: OptionTypeDuration, Default: defaults.TimeThreshold.String(), Description: "Time since the last flush after which pending puts are flushed, 0s for no limit"},
+		{Name: BatchAsyncOption, Type: OptionTypeBool, Default: strconv.FormatBool(defaults.Async), Description: "Write pending puts from a background goroutine"},
+		{Name: BatchMaxInFlightOption, Type: OptionTypeInt, Default: strconv.Itoa(defaults.MaxInFlightBytes), Description: "Bytes being written in the background above which puts block, 0 for a single batch"},
+		{Name: ReadYourWritesOption, Type: OptionTypeBool, Default: strconv.FormatBool(defaults.ReadYourWrites), Description: "Make pending puts visible to reads"},
+	}
+}
+
+type OptionSource string
+
+const (
+	OptionSourceDefault OptionSource = "default"
+	OptionSourceDSN     OptionSource = "dsn"
+	OptionSourceAlias   OptionSource = "alias"
+)
+
+// CompressionValues are the values accepted by `NewCompressor`, for drivers declaring a
+// compression option.
+var CompressionValues = []string{"zstd", "zst", "none", "false", "no"}
+
+// ResolvedOption is the value an option takes for a given DSN.
+type ResolvedOption struct {
+	*OptionSchema
+	Value  string
+	Source OptionSource
+	// SetAs is the name the option was set with in the DSN, differs from `Name` when set
+	// through an alias.
+	SetAs string
+}
+
+// AllOptions returns the options accepted by the driver, the global ones first, nil if the
+// driver does not declare its options.
+func (r *Registration) AllOptions() OptionSchemas {
+	if r.Options == nil {
+		return nil
+	}
+
+	return append(append(OptionSchemas{}, globalOptionSchemas...), r.Options...)
+}
+
+// ResolveDSN validates the DSN query parameters against the options declared by the
+// driver, rejecting unknown ones. The DSN returned has the options set through an alias
+// renamed to their actual name. Drivers not declaring their options accept any DSN.
+func (r *Registration) ResolveDSN(dsn string) (string, []*ResolvedOption, error) {
+	options := r.AllOptions()
+	if options == nil {
+		return dsn, nil, nil
+	}
+
+	dsnURL, err := url.Parse(dsn)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid dsn: %w", err)
+	}
+
+	query := dsnURL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	set := map[string]*ResolvedOption{}
+	renamed := false
+	for _, name := range names {
+		option := options.Find(name)
+		if option == nil {
+			return "", nil, fmt.Errorf("unknown option %q for driver %q, supported options are %s", name, r.Name, strings.Join(quoteAll(options.names()), ", "))
+		}
+
+		if previous, found := set[option.Name]; found {
+			return "", nil, fmt.Errorf("option %q set both as %q and %q", option.Name, previous.SetAs, name)
+		}
+
+		rawValue := query.Get(name)
+		if err := option.Validate(rawValue); err != nil {
+			return "", nil, err
+		}
+
+		resolved := &ResolvedOption{OptionSchema: option, Value: rawValue, Source: OptionSourceDSN, SetAs: name}
+		if rawValue == "" {
+			resolved.Value, resolved.Source = option.Default, OptionSourceDefault
+		}
+
+		if name != option.Name {
+			resolved.Source = OptionSourceAlias
+			query[option.Name] = query[name]
+			delete(query, name)
+			renamed = true
+		}
+		set[option.Name] = resolved
+	}
+
+	resolved := make([]*ResolvedOption, len(options))
+	for i, option := range options {
+		if option, found := set[option.Name]; found {
+			resolved[i] = option
+			continue
+		}
+		resolved[i] = &ResolvedOption{OptionSchema: option, Value: option.Default, Source: OptionSourceDefault}
+	}
+
+	if renamed {
+		dsnURL.RawQuery = query.Encode()
+		dsn = dsnURL.String()
+	}
+
+	return dsn, resolved, nil
+}
+
+func (s OptionSchemas) names() []string {
+	out := make([]string, len(s))
+	for i, option := range s {
+		out[i] = option.Name
+	}
+	return out
+}
+
+// ExplainDSN returns the driver of the DSN and the value each of its options takes.
+func ExplainDSN(dsn string) (*Registration, []*ResolvedOption, error) {
+	reg, err := registrationOf(dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, options, err := reg.ResolveDSN(canonicalDSN(reg, dsn))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reg, options, nil
+}
+
+func quoteAll(values []string) []string {
+	out := make([]string, len(values))
+	for i, value := range values {
+		out[i] = strconv.Quote(value)
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSchemaRegistration = &Registration{
+	Name: "schema",
+	Options: OptionSchemas{
+		{Name: "create_table", Type: OptionTypeBool, Default: "false", Aliases: []string{"createTable"}},
+		{Name: "key_prefix", Type: OptionTypeHex},
+		{Name: "compression", Type: OptionTypeString, Values: CompressionValues},
+		{Name: "flush_after", Type: OptionTypeDuration, Default: "10s"},
+	},
+}
+
+func TestRegistration_ResolveDSN(t *testing.T) {
+	tests := []struct {
+		name          string
+		dsn           string
+		expectDSN     string
+		expectValues  map[string]string
+		expectSources map[string]OptionSource
+		expectError   string
+	}{
+		{
+			name:          "defaults",
+			dsn:           "schema://host/path",
+			expectDSN:     "schema://host/path",
+			expectValues:  map[string]string{"create_table": "false", "key_prefix": "", "flush_after": "10s", "retry_max": "0"},
+			expectSources: map[string]OptionSource{"create_table": OptionSourceDefault, "retry_max": OptionSourceDefault},
+		},
+		{
+			name:          "set",
+			dsn:           "schema://host/path?key_prefix=0aff&compression=zstd&retry_max=3",
+			expectDSN:     "schema://host/path?key_prefix=0aff&compression=zstd&retry_max=3",
+			expectValues:  map[string]string{"key_prefix": "0aff", "compression": "zstd", "retry_max": "3"},
+			expectSources: map[string]OptionSource{"key_prefix": OptionSourceDSN, "retry_max": OptionSourceDSN, "flush_after": OptionSourceDefault},
+		},
+		{
+			name:          "alias renamed",
+			dsn:           "schema://host/path?createTable=true&flush_after=1s",
+			expectDSN:     "schema://host/path?create_table=true&flush_after=1s",
+			expectValues:  map[string]string{"create_table": "true", "flush_after": "1s"},
+			expectSources: map[string]OptionSource{"create_table": OptionSourceAlias, "flush_after": OptionSourceDSN},
+		},
+		{
+			name:          "empty value is unset",
+			dsn:           "schema://host/path?flush_after=",
+			expectDSN:     "schema://host/path?flush_after=",
+			expectValues:  map[string]string{"flush_after": "10s"},
+			expectSources: map[string]OptionSource{"flush_after": OptionSourceDefault},
+		},
+		{name: "unknown", dsn: "schema://host/path?createTables=true", expectError: `unknown option "createTables" for driver "schema"`},
+		{name: "invalid bool", dsn: "schema://host/path?create_table=maybe", expectError: `invalid bool value "maybe" for option "create_table"`},
+		{name: "invalid hex", dsn: "schema://host/path?key_prefix=zz", expectError: `invalid hex value "zz" for option "key_prefix"`},
+		{name: "invalid duration", dsn: "schema://host/path?flush_after=10", expectError: `invalid duration value "10" for option "flush_after"`},
+		{name: "invalid global", dsn: "schema://host/path?retry_max=many", expectError: `invalid int value "many" for option "retry_max"`},
+		{name: "not accepted value", dsn: "schema://host/path?compression=lz4", expectError: `invalid value "lz4" for option "compression"`},
+		{name: "set twice", dsn: "schema://host/path?create_table=true&createTable=false", expectError: `option "create_table" set both as "createTable" and "create_table"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dsn, options, err := testSchemaRegistration.ResolveDSN(test.dsn)
+			if test.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectDSN, dsn)
+			require.Len(t, options, len(globalOptionSchemas)+len(testSchemaRegistration.Options))
+
+			byName := map[string]*ResolvedOption{}
+			for _, option := range options {
+				byName[option.Name] = option
+			}
+
+			for name, value := range test.expectValues {
+				assert.Equal(t, value, byName[name].Value, name)
+			}
+			for name, source := range test.expectSources {
+				assert.Equal(t, source, byName[name].Source, name)
+			}
+		})
+	}
+}
+
+func TestRegistration_ResolveDSN_NotDeclared(t *testing.T) {
+	dsn, options, err := (&Registration{Name: "free"}).ResolveDSN("free://host?anything=goes")
+	require.NoError(t, err)
+	assert.Equal(t, "free://host?anything=goes", dsn)
+	assert.Nil(t, options)
+}
+
+func TestWriteBufferOptionSchemas(t *testing.T) {
+	options := WriteBufferOptionSchemas(WriteBufferOptions{SizeThreshold: 1024, TimeThreshold: 5 * time.Second})
+
+	assert.Equal(t, "1024", options.Find(BatchSizeThresholdOption).Default)
+	assert.Equal(t, "5s", options.Find(BatchTimeThresholdOption).Default)
+	assert.Equal(t, "false", options.Find(ReadYourWritesOption).Default)
+	assert.Nil(t, options.Find("unknown"))
+}
@@ -0,0 +1,184 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+type readSegmentKind uint8
+
+const (
+	batchGetSegment readSegmentKind = iota
+	scanSegment
+	prefixSegment
+	batchPrefixSegment
+)
+
+// readSegment is a single read request against a KVStore, resuming a read that
+// failed mid-way is done by replacing its segment by one or more segments covering
+// only the keys that were not yet emitted.
+type readSegment struct {
+	kind     readSegmentKind
+	keys     [][]byte
+	start    []byte
+	end      []byte
+	prefixes [][]byte
+	options  []ReadOption
+
+	// after, when set, means that all keys lower or equal to it must be skipped, it's
+	// used to resume unbounded prefixes that cannot be expressed as a scan.
+	after []byte
+}
+
+func (r readSegment) open(ctx context.Context, s KVStore, limit Limit) *Iterator {
+	if r.after != nil {
+		limit = Unlimited
+	}
+
+	switch r.kind {
+	case batchGetSegment:
+		return s.BatchGet(ctx, r.keys)
+	case scanSegment:
+		return s.Scan(ctx, r.start, r.end, int(limit), r.options...)
+	case prefixSegment:
+		return s.Prefix(ctx, r.prefixes[0], int(limit), r.options...)
+	case batchPrefixSegment:
+		return s.BatchPrefix(ctx, r.prefixes, int(limit), r.options...)
+	}
+
+	panic(fmt.Errorf("unknown read segment kind %d", r.kind))
+}
+
+func (r readSegment) skip(key []byte) bool {
+	return r.after != nil && bytes.Compare(key, r.after) <= 0
+}
+
+// resumeAfter returns the segments that must be read to continue this segment
+// right after `lastKey`, `count` being the number of items the segment emitted so far.
+func (r readSegment) resumeAfter(lastKey []byte, count uint64) []readSegment {
+	switch r.kind {
+	case batchGetSegment:
+		if count >= uint64(len(r.keys)) {
+			return nil
+		}
+		return []readSegment{{kind: batchGetSegment, keys: r.keys[count:]}}
+
+	case scanSegment:
+		return []readSegment{{kind: scanSegment, start: Key(lastKey).Next(), end: r.end, options: r.options}}
+
+	case prefixSegment:
+		return []readSegment{resumePrefix(r.prefixes[0], lastKey, r.options)}
+
+	case batchPrefixSegment:
+		for i, prefix := range r.prefixes {
+			if !bytes.HasPrefix(lastKey, prefix) {
+				continue
+			}
+
+			out := []readSegment{resumePrefix(prefix, lastKey, r.options)}
+			if i+1 < len(r.prefixes) {
+				out = append(out, readSegment{kind: batchPrefixSegment, prefixes: r.prefixes[i+1:], options: r.options})
+			}
+			return out
+		}
+
+		// Should not happen, the last key always belongs to one of the prefixes, restart while skipping what was seen
+		return []readSegment{{kind: batchPrefixSegment, prefixes: r.prefixes, options: r.options, after: lastKey}}
+	}
+
+	panic(fmt.Errorf("unknown read segment kind %d", r.kind))
+}
+
+func resumePrefix(prefix []byte, lastKey []byte, options []ReadOption) readSegment {
+	end := prefixExclusiveEnd(prefix)
+	if end == nil {
+		return readSegment{kind: prefixSegment, prefixes: [][]byte{prefix}, options: options, after: lastKey}
+	}
+
+	return readSegment{kind: scanSegment, start: Key(lastKey).Next(), end: end, options: options}
+}
+
+// prefixExclusiveEnd returns the smallest key greater than all keys starting with
+// prefix, or nil if there is no such key (empty prefix or prefix made only of 0xFF bytes).
+func prefixExclusiveEnd(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xFF {
+			end := make([]byte, i+1)
+			copy(end, prefix)
+			end[i]++
+			return end
+		}
+	}
+
+	return nil
+}
+
+// readSegments pushes the items of the segments, read one after the other, to the `out`
+// iterator, enforcing the global limit across segments.
+//
+// When reading a segment fails, `onError` is called with the last key emitted and if any
+// progress was made since the previous error. If it returns true, the failing segment is
+// resumed right after its last emitted key, otherwise the error is pushed to `out`.
+func readSegments(ctx context.Context, s KVStore, out *Iterator, limit Limit, segments []readSegment, onError func(err error, lastKey []byte, progressed bool) bool) {
+	count := uint64(0)
+
+	for len(segments) > 0 {
+		current := segments[0]
+		segmentLimit := limit
+		if limit.Bounded() {
+			segmentLimit = Limit(uint64(limit) - count)
+		}
+
+		lastKey, segmentCount, done, err := pushSegment(ctx, s, out, current, segmentLimit, limit, &count)
+		if done {
+			return
+		}
+		if err == nil {
+			segments = segments[1:]
+			continue
+		}
+
+		if onError == nil || !onError(err, lastKey, segmentCount > 0) {
+			out.PushError(err)
+			return
+		}
+
+		if segmentCount > 0 {
+			segments = append(current.resumeAfter(lastKey, segmentCount), segments[1:]...)
+		}
+	}
+
+	out.PushFinished()
+}
+
+// pushSegment pushes the items of the segment to `out`, returning the last key pushed and
+// their count, `done` is true once `out` is finished or closed. The segment is read with
+// its own context, cancelled on return so that its source stops reading.
+func pushSegment(ctx context.Context, s KVStore, out *Iterator, current readSegment, segmentLimit, limit Limit, count *uint64) (lastKey []byte, segmentCount uint64, done bool, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	source := current.open(ctx, s, segmentLimit)
+	for source.Next() {
+		item := source.Item()
+		if current.skip(item.Key) {
+			continue
+		}
+
+		if !out.PushItem(item) {
+			return lastKey, segmentCount, true, nil
+		}
+
+		lastKey = item.Key
+		segmentCount++
+		*count++
+
+		if limit.Reached(*count) {
+			out.PushFinished()
+			return lastKey, segmentCount, true, nil
+		}
+	}
+
+	return lastKey, segmentCount, false, source.Err()
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ctxRecordingStore records the contexts of the reads made to the store
+type ctxRecordingStore struct {
+	*memoryKVStore
+	contexts chan context.Context
+}
+
+func (s *ctxRecordingStore) Scan(ctx context.Context, start, exclusiveEnd []byte, limit int, options ...ReadOption) *Iterator {
+	s.contexts <- ctx
+	return s.memoryKVStore.Scan(ctx, start, exclusiveEnd, limit, options...)
+}
+
+func TestReadSegments_CancelsSource(t *testing.T) {
+	s := &ctxRecordingStore{memoryKVStore: newTestCursorStore(), contexts: make(chan context.Context, 1)}
+
+	it := NewScanIterator(context.Background(), []byte("a"), []byte("c"), 1, nil)
+	it.PushItem(KV{Key: []byte("a"), Value: []byte("1")})
+	it.PushFinished()
+	_, err := testCollectKeys(t, it)
+	require.NoError(t, err)
+	require.NotNil(t, it.Cursor())
+
+	keys, err := testCollectKeys(t, ReadFromCursor(context.Background(), s, it.Cursor(), 1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ba"}, keys)
+
+	source := <-s.contexts
+	assert.Eventually(t, func() bool { return source.Err() != nil }, time.Second, 5*time.Millisecond, "source read is cancelled once done")
+}
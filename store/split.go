@@ -0,0 +1,116 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+)
+
+// Splitter is implemented by store drivers able to split a range of keys in chunks of
+// roughly the same size out of their backend statistics. Use `SplitRange` which falls back
+// to evenly spaced keys for the other drivers.
+type Splitter interface {
+	// SplitRange returns at most `approxChunks - 1` sorted keys, all strictly within
+	// (start, exclusiveEnd), splitting the range in chunks of roughly the same size. An
+	// empty `exclusiveEnd` means no upper bound.
+	SplitRange(ctx context.Context, start, exclusiveEnd []byte, approxChunks int) ([][]byte, error)
+}
+
+// SplitRange returns the keys splitting [start, exclusiveEnd) in about `approxChunks`
+// chunks, using the store's `Splitter` implementation when available and keys evenly
+// spaced in the byte space otherwise. An empty `exclusiveEnd` means no upper bound.
+func SplitRange(ctx context.Context, s KVStore, start, exclusiveEnd []byte, approxChunks int) ([][]byte, error) {
+	if splitter, ok := s.(Splitter); ok {
+		return splitter.SplitRange(ctx, start, exclusiveEnd, approxChunks)
+	}
+
+	return UniformSplits(start, exclusiveEnd, approxChunks), nil
+}
+
+// BalancedSplits picks at most `approxChunks - 1` keys evenly among the sorted `candidates`
+// keys within (start, exclusiveEnd), drivers use it to reduce the boundaries known by their
+// backend (regions, tables, samples) to the requested amount. When no candidate falls
+// within the range, it falls back to `UniformSplits`.
+func BalancedSplits(candidates [][]byte, start, exclusiveEnd []byte, approxChunks int) [][]byte {
+	var inRange [][]byte
+	for _, candidate := range candidates {
+		if bytes.Compare(candidate, start) <= 0 || (len(exclusiveEnd) > 0 && bytes.Compare(candidate, exclusiveEnd) >= 0) {
+			continue
+		}
+
+		if len(inRange) > 0 && bytes.Equal(inRange[len(inRange)-1], candidate) {
+			continue
+		}
+		inRange = append(inRange, candidate)
+	}
+
+	if len(inRange) == 0 {
+		return UniformSplits(start, exclusiveEnd, approxChunks)
+	}
+
+	if approxChunks <= 1 {
+		return nil
+	}
+
+	if len(inRange) < approxChunks {
+		return inRange
+	}
+
+	// Candidates delimit `len(inRange) + 1` chunks, split `i` ends the `i * (len(inRange) + 1) / approxChunks` first ones
+	out := make([][]byte, approxChunks-1)
+	for i := range out {
+		out[i] = inRange[(i+1)*(len(inRange)+1)/approxChunks-1]
+	}
+	return out
+}
+
+// UniformSplits returns at most `approxChunks - 1` keys splitting [start, exclusiveEnd) in
+// chunks evenly spaced in the byte space, which is balanced only when keys are uniformly
+// distributed (hashes, random identifiers). An empty `exclusiveEnd` means no upper bound.
+func UniformSplits(start, exclusiveEnd []byte, approxChunks int) (out [][]byte) {
+	if approxChunks <= 1 {
+		return nil
+	}
+
+	// Keys are seen as fractions of the byte space, one extra byte gives resolution between close keys
+	width := len(start)
+	if len(exclusiveEnd) > width {
+		width = len(exclusiveEnd)
+	}
+	width++
+
+	low := keyToInt(start, width)
+	high := new(big.Int).Lsh(big.NewInt(1), uint(8*width))
+	if len(exclusiveEnd) > 0 {
+		high = keyToInt(exclusiveEnd, width)
+	}
+
+	span := new(big.Int).Sub(high, low)
+	if span.Sign() <= 0 {
+		return nil
+	}
+
+	for i := 1; i < approxChunks; i++ {
+		offset := new(big.Int).Mul(span, big.NewInt(int64(i)))
+		offset.Quo(offset, big.NewInt(int64(approxChunks)))
+
+		key := offset.Add(offset, low).FillBytes(make([]byte, width))
+		if bytes.Compare(key, start) <= 0 || (len(out) > 0 && bytes.Equal(out[len(out)-1], key)) {
+			continue
+		}
+		if len(exclusiveEnd) > 0 && bytes.Compare(key, exclusiveEnd) >= 0 {
+			continue
+		}
+
+		out = append(out, key)
+	}
+
+	return out
+}
+
+func keyToInt(key []byte, width int) *big.Int {
+	padded := make([]byte, width)
+	copy(padded, key)
+
+	return new(big.Int).SetBytes(padded)
+}
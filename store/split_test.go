@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalancedSplits(t *testing.T) {
+	keys := func(in ...string) (out [][]byte) {
+		for _, key := range in {
+			out = append(out, []byte(key))
+		}
+		return
+	}
+
+	candidates := keys("a", "b", "c", "d", "e", "f", "g")
+
+	tests := []struct {
+		name         string
+		start        string
+		exclusiveEnd string
+		chunks       int
+		expected     [][]byte
+	}{
+		{"evenly picked", "", "", 4, keys("b", "d", "f")},
+		{"two chunks", "", "", 2, keys("d")},
+		{"more chunks than candidates", "c", "f", 8, keys("d", "e")},
+		{"strictly within range", "a", "g", 8, keys("b", "c", "d", "e", "f")},
+		{"single chunk", "", "", 1, nil},
+		{"no candidate falls back to uniform", "x", "z", 2, keys("y\x00")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, BalancedSplits(candidates, []byte(test.start), []byte(test.exclusiveEnd), test.chunks))
+		})
+	}
+}
+
+func TestUniformSplits(t *testing.T) {
+	assert.Equal(t, [][]byte{{0x40}, {0x80}, {0xC0}}, UniformSplits(nil, nil, 4))
+	assert.Equal(t, [][]byte{{0x10, 0x80}}, UniformSplits([]byte{0x10}, []byte{0x11}, 2))
+	assert.Equal(t, [][]byte{{'a', 0x80}}, UniformSplits([]byte("a"), []byte("b"), 2))
+	assert.Nil(t, UniformSplits([]byte("b"), []byte("a"), 4))
+	assert.Nil(t, UniformSplits([]byte("a"), []byte("b"), 1))
+
+	// There is no key between adjacent keys
+	assert.Nil(t, UniformSplits([]byte("a"), []byte("a\x00"), 4))
+}
+
+func TestSplitRange_Fallback(t *testing.T) {
+	splits, err := SplitRange(context.Background(), newTestCursorStore(), []byte("a"), []byte("b"), 2)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{{'a', 0x80}}, splits)
+}
@@ -1,8 +1,10 @@
 package storetest
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -38,6 +40,46 @@ var kvstoreTests = []struct {
 			enableEmptyValue: true,
 		},
 	},
+	{
+		name: "cursor",
+		test: testCursor,
+	},
+	{
+		name: "buffering",
+		test: testBuffering,
+	},
+	{
+		name: "filtering",
+		test: testFiltering,
+	},
+	{
+		name: "count",
+		test: testCount,
+	},
+	{
+		name: "split",
+		test: testSplit,
+	},
+	{
+		name: "delete range",
+		test: testDeleteRange,
+	},
+	{
+		name: "exists",
+		test: testExists,
+	},
+	{
+		name: "batch get",
+		test: testBatchGet,
+	},
+	{
+		name: "compact",
+		test: testCompact,
+	},
+	{
+		name: "pending puts",
+		test: testPendingPuts,
+	},
 	{
 		name: "purgeable",
 		test: testPurgeable,
@@ -295,6 +337,127 @@ func testBasic(t *testing.T, driver store.KVStore, _ *DriverCapabilities, _ kvSt
 	}
 }
 
+func testCursor(t *testing.T, driver store.KVStore, _ *DriverCapabilities, _ kvStoreOptions) {
+	all := []store.KV{
+		{Key: []byte("a"), Value: []byte("1")},
+		{Key: []byte("ba"), Value: []byte("2")},
+		{Key: []byte("ba1"), Value: []byte("3")},
+		{Key: []byte("ba2"), Value: []byte("4")},
+		{Key: []byte("bb"), Value: []byte("5")},
+		{Key: []byte("c"), Value: []byte("6")},
+	}
+
+	for _, kv := range all {
+		require.NoError(t, driver.Put(context.Background(), kv.Key, kv.Value))
+	}
+	require.NoError(t, driver.FlushPuts(context.Background()))
+
+	testPaginate(t, "scan", driver, func(limit int) *store.Iterator {
+		return driver.Scan(context.Background(), []byte("a"), []byte("c"), limit)
+	}, all[:5])
+	testPaginate(t, "prefix", driver, func(limit int) *store.Iterator {
+		return driver.Prefix(context.Background(), []byte("b"), limit)
+	}, all[1:5])
+	testPaginate(t, "empty prefix", driver, func(limit int) *store.Iterator {
+		return driver.Prefix(context.Background(), nil, limit)
+	}, all)
+	testPaginate(t, "batch prefix", driver, func(limit int) *store.Iterator {
+		return driver.BatchPrefix(context.Background(), [][]byte{[]byte("a"), []byte("ba"), []byte("c")}, limit)
+	}, []store.KV{all[0], all[1], all[2], all[3], all[5]})
+	testPaginate(t, "key only", driver, func(limit int) *store.Iterator {
+		return driver.Prefix(context.Background(), []byte("ba"), limit, store.KeyOnly())
+	}, []store.KV{{Key: all[1].Key}, {Key: all[2].Key}, {Key: all[3].Key}})
+}
+
+func testBuffering(t *testing.T, driver store.KVStore, _ *DriverCapabilities, _ kvStoreOptions) {
+	var all []store.KV
+	for i := 0; i < 25; i++ {
+		all = append(all, store.KV{Key: []byte(fmt.Sprintf("k%02d", i)), Value: []byte(strings.Repeat("v", i+1))})
+	}
+
+	for _, kv := range all {
+		require.NoError(t, driver.Put(context.Background(), kv.Key, kv.Value))
+	}
+	require.NoError(t, driver.FlushPuts(context.Background()))
+
+	small := []store.ReadOption{store.WithBufferSize(1), store.WithBufferBytes(16), store.WithPrefetchSize(3)}
+
+	testPrefix(t, "prefix, small pages", driver, []byte("k"), store.Unlimited, all, small...)
+	testPrefix(t, "prefix, limit within page", driver, []byte("k"), 2, all[:2], small...)
+	testPrefix(t, "prefix, limit across pages", driver, []byte("k"), 7, all[:7], small...)
+	testBatchPrefix(t, "batch prefix, small pages", driver, [][]byte{[]byte("k0"), []byte("k2")}, store.Unlimited, append(append([]store.KV{}, all[:10]...), all[20:]...), small...)
+	testScan(t, driver, []byte("k03"), []byte("k19"), store.Unlimited, all[3:19], small...)
+	testScan(t, driver, []byte("k03"), []byte("k19"), 10, all[3:13], small...)
+}
+
+func testFiltering(t *testing.T, driver store.KVStore, _ *DriverCapabilities, _ kvStoreOptions) {
+	all := []store.KV{
+		{Key: []byte("a1"), Value: []byte("x1")},
+		{Key: []byte("a2"), Value: []byte("y22")},
+		{Key: []byte("a3"), Value: []byte("x333")},
+		{Key: []byte("b1"), Value: []byte("x4444")},
+		{Key: []byte("b2"), Value: []byte("y5")},
+		{Key: []byte("bb"), Value: []byte("x6")},
+	}
+
+	for _, kv := range all {
+		require.NoError(t, driver.Put(context.Background(), kv.Key, kv.Value))
+	}
+	require.NoError(t, driver.FlushPuts(context.Background()))
+
+	keyOnly := func(kvs ...store.KV) (out []store.KV) {
+		for _, kv := range kvs {
+			out = append(out, store.KV{Key: kv.Key})
+		}
+		return
+	}
+
+	testPrefix(t, "key regex", driver, nil, store.Unlimited, []store.KV{all[0], all[1], all[2], all[3], all[4]}, store.WithKeyRegex("[ab][0-9]"))
+	testPrefix(t, "key regex anchored", driver, nil, store.Unlimited, []store.KV{all[5]}, store.WithKeyRegex("b[a-z]"))
+	testPrefix(t, "value prefix", driver, nil, store.Unlimited, []store.KV{all[0], all[2], all[3], all[5]}, store.WithValuePrefix([]byte("x")))
+	testPrefix(t, "value size range", driver, nil, store.Unlimited, []store.KV{all[1], all[2]}, store.WithValueSizeRange(3, 4))
+	testPrefix(t, "value size min", driver, nil, store.Unlimited, []store.KV{all[2], all[3]}, store.WithValueSizeRange(4, 0))
+	testPrefix(t, "combined", driver, []byte("a"), store.Unlimited, []store.KV{all[2]}, store.WithValuePrefix([]byte("x")), store.WithValueSizeRange(3, 0))
+	testPrefix(t, "limit counts matching items", driver, nil, 2, []store.KV{all[0], all[2]}, store.WithValuePrefix([]byte("x")))
+	testPrefix(t, "key only", driver, nil, store.Unlimited, keyOnly(all[2], all[3]), store.KeyOnly(), store.WithValueSizeRange(4, 0))
+	testPrefix(t, "small pages", driver, nil, 3, []store.KV{all[0], all[2], all[3]}, store.WithValuePrefix([]byte("x")), store.WithPrefetchSize(1))
+
+	testBatchPrefix(t, "batch prefix filtered", driver, [][]byte{[]byte("a"), []byte("b")}, store.Unlimited, []store.KV{all[1], all[4]}, store.WithValuePrefix([]byte("y")))
+	testScan(t, driver, []byte("a2"), []byte("bb"), store.Unlimited, []store.KV{all[2], all[3]}, store.WithKeyRegex(".*[0-9]"), store.WithValuePrefix([]byte("x")))
+
+	it := driver.Prefix(context.Background(), nil, store.Unlimited, store.WithKeyRegex("a("))
+	for it.Next() {
+	}
+	require.Error(t, it.Err())
+}
+
+// testPaginate reads 2 items at a time, continuing each page from the serialized cursor of the previous one
+func testPaginate(t *testing.T, name string, driver store.KVStore, read func(limit int) *store.Iterator, exp []store.KV) {
+	t.Run(name, func(t *testing.T) {
+		var out []store.KV
+		it := read(2)
+		for {
+			for it.Next() {
+				out = append(out, it.Item())
+			}
+			require.NoError(t, it.Err())
+
+			cursor := it.Cursor()
+			if cursor == nil {
+				break
+			}
+
+			cursor, err := store.ParseCursor(cursor.Bytes())
+			require.NoError(t, err)
+
+			it = store.ReadFromCursor(context.Background(), driver, cursor, 2)
+		}
+
+		testPrintKVs(name, out)
+		assert.Equal(t, exp, out)
+	})
+}
+
 func testEmtpyValue(t *testing.T, driver store.KVStore, capabilities *DriverCapabilities, options kvStoreOptions) {
 	key := []byte("randomkey")
 	canAddEmptyValue := options.enableEmptyValue || capabilities.SupportsEmptyValue
@@ -322,11 +485,12 @@ func testEmtpyValue(t *testing.T, driver store.KVStore, capabilities *DriverCapa
 	for it.Next() {
 		got = append(got, it.Item())
 	}
-	require.NoError(t, it.Err())
 
 	if !canAddEmptyValue {
+		require.Equal(t, store.ErrNotFound, it.Err())
 		require.Len(t, got, 0)
 	} else {
+		require.NoError(t, it.Err())
 		require.Len(t, got, 1)
 		require.Equal(t, []byte(nil), got[0].Value)
 	}
@@ -409,3 +573,223 @@ func testDeleteKeyGenerate(t *testing.T, deletionTablePrefix []byte, height uint
 	deletionKey := append(deletionTablePrefix, buf...)
 	return append(deletionKey, key...)
 }
+
+func testCount(t *testing.T, driver store.KVStore, _ *DriverCapabilities, _ kvStoreOptions) {
+	for _, key := range []string{"a1", "a2", "a3", "b1", "b2", "c"} {
+		require.NoError(t, driver.Put(context.Background(), []byte(key), []byte("value")))
+	}
+	require.NoError(t, driver.FlushPuts(context.Background()))
+
+	count := func(start, exclusiveEnd string) uint64 {
+		out, err := store.Count(context.Background(), driver, []byte(start), []byte(exclusiveEnd))
+		require.NoError(t, err)
+		return out
+	}
+
+	countPrefix := func(prefix string) uint64 {
+		out, err := store.CountPrefix(context.Background(), driver, []byte(prefix))
+		require.NoError(t, err)
+		return out
+	}
+
+	assert.Equal(t, uint64(6), count("a", "d"), "whole range")
+	assert.Equal(t, uint64(3), count("a2", "b2"), "sub range")
+	assert.Equal(t, uint64(0), count("d", "e"), "empty range")
+	assert.Equal(t, uint64(3), countPrefix("a"), "prefix")
+	assert.Equal(t, uint64(1), countPrefix("c"), "single key prefix")
+	assert.Equal(t, uint64(0), countPrefix("z"), "no match prefix")
+
+	_, err := store.ApproximateSize(context.Background(), driver, []byte("a"), nil)
+	if err != nil {
+		assert.ErrorIs(t, err, store.ErrNotSupported)
+	}
+}
+
+func testSplit(t *testing.T, driver store.KVStore, _ *DriverCapabilities, _ kvStoreOptions) {
+	var all []store.KV
+	for i := 0; i < 100; i++ {
+		kv := store.KV{Key: []byte(fmt.Sprintf("k%03d", i)), Value: []byte("value")}
+		require.NoError(t, driver.Put(context.Background(), kv.Key, kv.Value))
+		all = append(all, kv)
+	}
+	require.NoError(t, driver.FlushPuts(context.Background()))
+
+	start, exclusiveEnd := []byte("k"), []byte("l")
+	splits, err := store.SplitRange(context.Background(), driver, start, exclusiveEnd, 8)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(splits), 7)
+
+	previous := start
+	for _, split := range splits {
+		assert.True(t, bytes.Compare(split, previous) > 0, "split %q should be after %q", split, previous)
+		previous = split
+	}
+	assert.True(t, bytes.Compare(previous, exclusiveEnd) < 0, "split %q should be before %q", previous, exclusiveEnd)
+
+	out, err := store.Collect(store.ParallelScan(context.Background(), driver, start, exclusiveEnd, store.ParallelScanOptions{Workers: 3, Chunks: 8}))
+	require.NoError(t, err)
+	assert.Equal(t, all, out)
+
+	out, err = store.Collect(store.ParallelScan(context.Background(), driver, start, exclusiveEnd, store.ParallelScanOptions{Workers: 3, Chunks: 8, Unordered: true}))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, all, out)
+}
+
+func testDeleteRange(t *testing.T, driver store.KVStore, _ *DriverCapabilities, _ kvStoreOptions) {
+	for _, key := range []string{"a", "ba", "ba1", "ba2", "bb", "c", "d"} {
+		require.NoError(t, driver.Put(context.Background(), []byte(key), []byte("value")))
+	}
+	require.NoError(t, driver.FlushPuts(context.Background()))
+
+	remainingKeys := func() (out []string) {
+		kvs, err := store.Collect(driver.Prefix(context.Background(), nil, store.Unlimited, store.KeyOnly()))
+		require.NoError(t, err)
+
+		for _, kv := range kvs {
+			out = append(out, string(kv.Key))
+		}
+		return
+	}
+
+	require.NoError(t, store.DeleteRange(context.Background(), driver, []byte("ba1"), []byte("c")))
+	assert.Equal(t, []string{"a", "ba", "c", "d"}, remainingKeys())
+
+	require.NoError(t, store.DeleteRange(context.Background(), driver, []byte("c"), nil))
+	assert.Equal(t, []string{"a", "ba", "c", "d"}, remainingKeys(), "empty end deletes nothing")
+
+	require.NoError(t, store.DeletePrefix(context.Background(), driver, []byte("b")))
+	assert.Equal(t, []string{"a", "c", "d"}, remainingKeys())
+
+	require.NoError(t, store.DeletePrefix(context.Background(), driver, []byte("z")))
+	assert.Equal(t, []string{"a", "c", "d"}, remainingKeys())
+}
+
+func testCompact(t *testing.T, driver store.KVStore, _ *DriverCapabilities, _ kvStoreOptions) {
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, driver.Put(context.Background(), []byte(key), []byte("value")))
+	}
+	require.NoError(t, driver.FlushPuts(context.Background()))
+	require.NoError(t, store.Delete(context.Background(), driver, []byte("b")))
+
+	err := store.Compact(context.Background(), driver)
+	if errors.Is(err, store.ErrNotSupported) {
+		return
+	}
+	require.NoError(t, err)
+
+	kvs, err := store.Collect(driver.Prefix(context.Background(), nil, store.Unlimited))
+	require.NoError(t, err)
+	assert.Equal(t, []store.KV{{Key: []byte("a"), Value: []byte("value")}, {Key: []byte("c"), Value: []byte("value")}}, kvs)
+}
+
+func testExists(t *testing.T, driver store.KVStore, _ *DriverCapabilities, _ kvStoreOptions) {
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, driver.Put(context.Background(), []byte(key), []byte("value")))
+	}
+	require.NoError(t, driver.FlushPuts(context.Background()))
+
+	exists, err := store.Exists(context.Background(), driver, []byte("b"))
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = store.Exists(context.Background(), driver, []byte("ba"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	batchExists, err := store.BatchExists(context.Background(), driver, [][]byte{[]byte("c"), []byte("z"), []byte("a"), []byte("c")})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true, true}, batchExists)
+
+	require.NoError(t, store.Delete(context.Background(), driver, []byte("b")))
+	require.NoError(t, store.Delete(context.Background(), driver, []byte("not-found")), "deleting a missing key is not an error")
+
+	_, err = driver.Get(context.Background(), []byte("b"))
+	assert.Equal(t, store.ErrNotFound, err)
+
+	batchExists, err = store.BatchExists(context.Background(), driver, [][]byte{[]byte("a"), []byte("b")})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, batchExists)
+}
+
+func testBatchGet(t *testing.T, driver store.KVStore, _ *DriverCapabilities, _ kvStoreOptions) {
+	// Enough keys for drivers reading them in many chunks
+	keys := make([][]byte, 1200)
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("key%04d", i))
+		require.NoError(t, driver.Put(context.Background(), keys[i], []byte(fmt.Sprintf("value%04d", i))))
+	}
+	require.NoError(t, driver.FlushPuts(context.Background()))
+
+	batchGet := func(keys ...[]byte) (got []string, err error) {
+		it := driver.BatchGet(context.Background(), keys)
+		for it.Next() {
+			got = append(got, string(it.Item().Key)+"="+string(it.Item().Value))
+		}
+		return got, it.Err()
+	}
+
+	got, err := batchGet([]byte("key0003"), []byte("key0001"), []byte("key0002"), []byte("key0001"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"key0003=value0003", "key0001=value0001", "key0002=value0002", "key0001=value0001"}, got, "results follow the order of keys")
+
+	// Reversed and interleaved, so keys are out of order within and across chunks
+	var unordered [][]byte
+	var expected []string
+	for i := len(keys) - 1; i >= 0; i -= 2 {
+		unordered = append(unordered, keys[i], keys[len(keys)-1-i])
+		expected = append(expected, fmt.Sprintf("key%04d=value%04d", i, i), fmt.Sprintf("key%04d=value%04d", len(keys)-1-i, len(keys)-1-i))
+	}
+
+	got, err = batchGet(unordered...)
+	require.NoError(t, err)
+	assert.Equal(t, expected, got)
+
+	// Items read before the error may or may not reach the consumer, but never the ones after
+	got, err = batchGet([]byte("key0001"), []byte("missing"), []byte("key0002"))
+	assert.Equal(t, store.ErrNotFound, err, "a missing key ends the read")
+	assert.Subset(t, []string{"key0001=value0001"}, got)
+
+	got, err = batchGet(append(keys[:1000:1000], []byte("missing"), []byte("key1000"))...)
+	assert.Equal(t, store.ErrNotFound, err, "a missing key in a later chunk ends the read")
+	assert.LessOrEqual(t, len(got), 1000)
+	for i, item := range got {
+		assert.Equal(t, fmt.Sprintf("key%04d=value%04d", i, i), item)
+	}
+}
+
+func testPendingPuts(t *testing.T, driver store.KVStore, _ *DriverCapabilities, _ kvStoreOptions) {
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, driver.Put(context.Background(), []byte(key), []byte("value")))
+	}
+	require.NoError(t, driver.Put(context.Background(), []byte("a"), []byte("latest")))
+
+	// Pending puts are only visible with read your writes, unless they were flushed already
+	if _, err := driver.Get(context.Background(), []byte("b")); err == nil {
+		kvs, err := store.Collect(driver.BatchGet(context.Background(), [][]byte{[]byte("c"), []byte("a"), []byte("b")}))
+		require.NoError(t, err)
+		assert.Equal(t, []store.KV{
+			{Key: []byte("c"), Value: []byte("value")},
+			{Key: []byte("a"), Value: []byte("latest")},
+			{Key: []byte("b"), Value: []byte("value")},
+		}, kvs, "batch get sees pending puts")
+
+		kvs, err = store.Collect(driver.BatchPrefix(context.Background(), [][]byte{[]byte("a"), []byte("c")}, store.Unlimited))
+		require.NoError(t, err)
+		assert.Equal(t, []store.KV{
+			{Key: []byte("a"), Value: []byte("latest")},
+			{Key: []byte("c"), Value: []byte("value")},
+		}, kvs, "batch prefix sees pending puts")
+	}
+
+	require.NoError(t, driver.BatchDelete(context.Background(), [][]byte{[]byte("b")}))
+	require.NoError(t, store.Delete(context.Background(), driver, []byte("c")))
+	require.NoError(t, driver.FlushPuts(context.Background()))
+
+	value, err := driver.Get(context.Background(), []byte("a"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("latest"), value, "last put of a key wins")
+
+	batchExists, err := store.BatchExists(context.Background(), driver, [][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, false}, batchExists, "deleting a key discards its pending put")
+}
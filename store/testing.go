@@ -63,6 +63,14 @@ func (t *TestKVDBDriver) BatchGet(ctx context.Context, keys [][]byte) *Iterator
 	panic("test driver, not callable")
 }
 
+func (t *TestKVDBDriver) Exists(ctx context.Context, key []byte) (exists bool, err error) {
+	panic("test driver, not callable")
+}
+
+func (t *TestKVDBDriver) BatchExists(ctx context.Context, keys [][]byte) (exists []bool, err error) {
+	panic("test driver, not callable")
+}
+
 func (t *TestKVDBDriver) Scan(ctx context.Context, start, exclusiveEnd []byte, limit int, options ...ReadOption) *Iterator {
 	panic("test driver, not callable")
 }
@@ -75,6 +83,10 @@ func (t *TestKVDBDriver) BatchPrefix(ctx context.Context, prefixes [][]byte, lim
 	panic("test driver, not callable")
 }
 
+func (t *TestKVDBDriver) Delete(ctx context.Context, key []byte) (err error) {
+	panic("test driver, not callable")
+}
+
 func (t *TestKVDBDriver) BatchDelete(ctx context.Context, keys [][]byte) (err error) {
 	panic("test driver, not callable")
 }
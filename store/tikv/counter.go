@@ -0,0 +1,110 @@
+package tikv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/streamingfast/kvdb/store"
+	"github.com/streamingfast/logging"
+	"github.com/tikv/client-go/v2/kv"
+)
+
+// Count implements `store.Counter`, keys are scanned without their value and counted
+// inside the driver.
+func (s *Store) Count(ctx context.Context, start, exclusiveEnd []byte) (uint64, error) {
+	return s.count(ctx, s.withPrefix(start), s.withPrefix(exclusiveEnd))
+}
+
+// CountPrefix implements `store.Counter`, keys are scanned without their value and counted
+// inside the driver.
+func (s *Store) CountPrefix(ctx context.Context, prefix []byte) (uint64, error) {
+	startKey := s.withPrefix(prefix)
+	exclusiveEnd := kv.PrefixNextKey(startKey)
+
+	// This performs a full scan, can only happen if the actual prefix is empty, which is permitted only in package's tests
+	if len(startKey) == 0 {
+		startKey = emptyStartKey
+		exclusiveEnd = nil
+	}
+
+	return s.count(ctx, startKey, exclusiveEnd)
+}
+
+func (s *Store) count(ctx context.Context, startKey, exclusiveEnd []byte) (count uint64, err error) {
+	err = s.scan(ctx, logging.Logger(ctx, zlog), startKey, exclusiveEnd, store.Unlimited, []store.ReadOption{store.KeyOnly()}, func(_ store.KV) bool {
+		count++
+		return true
+	})
+
+	return
+}
+
+// regionStats is the subset of PD `/pd/api/v1/stats/region` response we use
+type regionStats struct {
+	Count int `json:"count"`
+	// StorageSize is the approximate size, in MiB, of the regions
+	StorageSize uint64 `json:"storage_size"`
+}
+
+// ApproximateSize implements `store.Counter`, the estimate comes from PD statistics of the
+// regions overlapping the range, so it's as coarse as regions are (96MiB by default).
+func (s *Store) ApproximateSize(ctx context.Context, start, exclusiveEnd []byte) (uint64, error) {
+	endKey := s.withPrefix(exclusiveEnd)
+	if len(exclusiveEnd) == 0 {
+		endKey = kv.PrefixNextKey(s.keyPrefix)
+	}
+
+	query := url.Values{
+		"start_key": []string{string(s.withPrefix(start))},
+		"end_key":   []string{string(endKey)},
+	}
+
+	stats := &regionStats{}
+	if err := s.pdGet(ctx, "/pd/api/v1/stats/region", query, stats); err != nil {
+		return 0, fmt.Errorf("fetch region stats: %w", err)
+	}
+
+	return stats.StorageSize * 1024 * 1024, nil
+}
+
+// pdGet performs a GET request to the PD HTTP API, trying each PD address until one answers,
+// decoding the JSON response in `out`.
+func (s *Store) pdGet(ctx context.Context, path string, query url.Values, out interface{}) error {
+	var lastErr error
+	for _, address := range s.pdAddresses {
+		err := getJSON(ctx, "http://"+address+path+"?"+query.Encode(), out)
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+	}
+
+	return fmt.Errorf("pd unreachable: %w", lastErr)
+}
+
+func getJSON(ctx context.Context, getURL string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, getURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %q", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package tikv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApproximateSize(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/pd/api/v1/stats/region", r.URL.Path)
+		query = r.URL.RawQuery
+
+		fmt.Fprint(w, `{"count":2,"storage_size":3}`)
+	}))
+	defer server.Close()
+
+	address := strings.TrimPrefix(server.URL, "http://")
+	s := &Store{pdAddresses: []string{"127.0.0.1:1", address}, keyPrefix: []byte("p:")}
+
+	size, err := s.ApproximateSize(context.Background(), []byte("a"), []byte("b"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3*1024*1024), size)
+	assert.Equal(t, "end_key=p%3Ab&start_key=p%3Aa", query)
+
+	_, err = s.ApproximateSize(context.Background(), []byte("a"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "end_key=p%3B&start_key=p%3Aa", query)
+}
@@ -0,0 +1,37 @@
+package tikv
+
+import (
+	"context"
+
+	"github.com/streamingfast/kvdb/store"
+	"github.com/streamingfast/logging"
+	"github.com/tikv/client-go/v2/kv"
+	"go.uber.org/zap"
+)
+
+// DeleteRange implements `store.RangeDeleter` with TiKV raw `DeleteRange`.
+func (s *Store) DeleteRange(ctx context.Context, start, exclusiveEnd []byte) error {
+	if len(exclusiveEnd) == 0 {
+		// Act like `Scan`, an empty end is an empty range, TiKV would see it as unbounded
+		return nil
+	}
+
+	logging.Logger(ctx, zlog).Debug("range deletion", zap.Stringer("start_key", store.Key(start)), zap.Stringer("exclusive_end_key", store.Key(exclusiveEnd)))
+	return s.client.DeleteRange(ctx, s.withPrefix(start), s.withPrefix(exclusiveEnd))
+}
+
+// DeletePrefix implements `store.RangeDeleter` with TiKV raw `DeleteRange`.
+func (s *Store) DeletePrefix(ctx context.Context, prefix []byte) error {
+	logging.Logger(ctx, zlog).Debug("prefix deletion", zap.Stringer("prefix", store.Key(prefix)))
+
+	startKey := s.withPrefix(prefix)
+	exclusiveEnd := kv.PrefixNextKey(startKey)
+
+	// This deletes everything, can only happen if the actual prefix is empty, which is permitted only in package's tests
+	if len(startKey) == 0 {
+		startKey = emptyStartKey
+		exclusiveEnd = nil
+	}
+
+	return s.client.DeleteRange(ctx, startKey, exclusiveEnd)
+}
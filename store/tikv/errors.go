@@ -0,0 +1,24 @@
+package tikv
+
+import (
+	"errors"
+
+	tikverr "github.com/tikv/client-go/v2/error"
+)
+
+// IsRetryableError implements `store.RetryableErrorClassifier`, region errors
+// (region being split, moved or not yet ready) as well as TiKV/PD server
+// timeouts are retryable.
+func (s *Store) IsRetryableError(err error) bool {
+	switch {
+	case errors.Is(err, tikverr.ErrRegionUnavailable),
+		errors.Is(err, tikverr.ErrRegionDataNotReady),
+		errors.Is(err, tikverr.ErrRegionNotInitialized),
+		errors.Is(err, tikverr.ErrTiKVServerBusy),
+		errors.Is(err, tikverr.ErrTiKVServerTimeout):
+		return true
+	}
+
+	var pdTimeout *tikverr.ErrPDServerTimeout
+	return errors.As(err, &pdTimeout)
+}
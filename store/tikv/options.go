@@ -4,3 +4,7 @@ func (s *Store) EnableEmpty() {
 	zlog.Info("enabling possible empty value on store implementation")
 	s.emptyValuePossible = true
 }
+
+func (s *Store) SetFlushErrorHandler(onError func(err error)) {
+	s.writes.SetErrorHandler(onError)
+}
@@ -0,0 +1,58 @@
+package tikv
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/streamingfast/kvdb/store"
+	"github.com/tikv/client-go/v2/kv"
+)
+
+// maxSplitRegions is the maximum number of regions PD returns in a single scan
+const maxSplitRegions = 10240
+
+// regionsInfo is the subset of PD `/pd/api/v1/regions/key` response we use
+type regionsInfo struct {
+	Regions []struct {
+		// StartKey is the hexadecimal encoded first key of the region
+		StartKey string `json:"start_key"`
+	} `json:"regions"`
+}
+
+// SplitRange implements `store.Splitter`, splits are picked among the boundaries of the
+// regions overlapping the range, as known by PD.
+func (s *Store) SplitRange(ctx context.Context, start, exclusiveEnd []byte, approxChunks int) ([][]byte, error) {
+	endKey := s.withPrefix(exclusiveEnd)
+	if len(exclusiveEnd) == 0 {
+		endKey = kv.PrefixNextKey(s.keyPrefix)
+	}
+
+	query := url.Values{
+		"key":     []string{string(s.withPrefix(start))},
+		"end_key": []string{string(endKey)},
+		"limit":   []string{strconv.Itoa(maxSplitRegions)},
+	}
+
+	regions := &regionsInfo{}
+	if err := s.pdGet(ctx, "/pd/api/v1/regions/key", query, regions); err != nil {
+		return nil, fmt.Errorf("fetch regions: %w", err)
+	}
+
+	var candidates [][]byte
+	for _, region := range regions.Regions {
+		key, err := hex.DecodeString(region.StartKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid region start key %q: %w", region.StartKey, err)
+		}
+
+		if bytes.HasPrefix(key, s.keyPrefix) {
+			candidates = append(candidates, s.withoutPrefix(key))
+		}
+	}
+
+	return store.BalancedSplits(candidates, start, exclusiveEnd, approxChunks), nil
+}
@@ -0,0 +1,35 @@
+package tikv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/pd/api/v1/regions/key", r.URL.Path)
+		require.Equal(t, "p:a", r.URL.Query().Get("key"))
+		require.Equal(t, "p;", r.URL.Query().Get("end_key"))
+
+		// Regions of `p:a`, `p:c`, `p:e` and `q` (outside of the key prefix)
+		fmt.Fprint(w, `{"count":4,"regions":[{"start_key":"703A61"},{"start_key":"703A63"},{"start_key":"703A65"},{"start_key":"71"}]}`)
+	}))
+	defer server.Close()
+
+	s := &Store{pdAddresses: []string{strings.TrimPrefix(server.URL, "http://")}, keyPrefix: []byte("p:")}
+
+	splits, err := s.SplitRange(context.Background(), []byte("a"), nil, 4)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("c"), []byte("e")}, splits)
+
+	splits, err = s.SplitRange(context.Background(), []byte("a"), nil, 2)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("c")}, splits)
+}
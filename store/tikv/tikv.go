@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/streamingfast/kvdb/store"
 	"github.com/streamingfast/logging"
 	"github.com/tikv/client-go/v2/config"
 	"github.com/tikv/client-go/v2/kv"
 	"github.com/tikv/client-go/v2/rawkv"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
 
@@ -18,13 +21,23 @@ const emptyValueByte = byte(0x00)
 
 var emptyStartKey = []byte{0x00}
 
+const (
+	// ~512KiB
+	defaultCompressionSizeThreshold = 512 * 1024
+	// ~7MiB, the other write buffer thresholds are unlimited by default
+	defaultBatchSizeThreshold = 7 * 1024 * 1024
+	// batchExistsConcurrentScans is the number of key only scans of a `BatchExists` running concurrently
+	batchExistsConcurrentScans = 16
+)
+
 type Store struct {
-	dsn        string
-	client     *rawkv.Client
-	keyPrefix  []byte
-	compressor store.Compressor
+	dsn         string
+	client      *rawkv.Client
+	pdAddresses []string
+	keyPrefix   []byte
+	compressor  store.Compressor
 
-	batchPut *store.BatchOp
+	writes *store.WriteBuffer
 
 	// TIKV does not support empty values, if this flag is set
 	// tikv will prepend an empty byte on write and remove the first byte
@@ -38,12 +51,26 @@ func (s *Store) String() string {
 
 func init() {
 	store.Register(&store.Registration{
-		Name:        "tikv",
-		FactoryFunc: NewStore,
+		Name:         "tikv",
+		Title:        "TiKV",
+		FactoryFunc:  NewStore,
+		Capabilities: capabilities,
+		Options: append(store.OptionSchemas{
+			{Name: "tikv_raw_max_scan_limit", Type: store.OptionTypeInt, Default: strconv.Itoa(rawkv.MaxRawKVScanLimit), Description: "Maximum keys per TiKV raw scan call, applies to all TiKV stores of the process"},
+			{Name: "compression", Type: store.OptionTypeString, Description: "Compression of values above the size threshold, none when unset", Values: store.CompressionValues},
+			{Name: "compression_size_threshold", Type: store.OptionTypeInt, Default: strconv.Itoa(defaultCompressionSizeThreshold), Description: "Size in bytes above which values are compressed"},
+		}, store.WriteBufferOptionSchemas(store.WriteBufferOptions{SizeThreshold: defaultBatchSizeThreshold})...),
 	})
 }
 
-// NewStore supports tikv://pd0,pd1,pd2:2379?prefix=hexkeyprefix
+// Empty values are supported only with `store.WithEmptyValue`, which marks them with a prefix byte
+var capabilities = store.DriverCapabilities{}
+
+func (s *Store) Capabilities() store.DriverCapabilities {
+	return capabilities
+}
+
+// NewStore supports tikv://pd0,pd1,pd2:2379/keyprefix
 func NewStore(dsnString string) (store.KVStore, error) {
 	dsn, err := url.Parse(dsnString)
 	if err != nil {
@@ -71,8 +98,7 @@ func NewStore(dsnString string) (store.KVStore, error) {
 
 	compression, _ := dsnQuery.StringOption("compression", "")
 
-	// Use compression size threshold (in bytes) if present, otherwise use ~512KiB
-	compressionThreshold, rawValue, err := dsnQuery.IntOption("compression_size_threshold", 512*1024)
+	compressionThreshold, rawValue, err := dsnQuery.IntOption("compression_size_threshold", defaultCompressionSizeThreshold)
 	if err != nil {
 		return nil, fmt.Errorf("compression size threshold option %q is not a valid number: %w", rawValue, err)
 	}
@@ -82,31 +108,16 @@ func NewStore(dsnString string) (store.KVStore, error) {
 		return nil, fmt.Errorf("new compressor: %w", err)
 	}
 
-	// Use batch size threshold (in bytes) if present, otherwise use ~7MiB
-	batchSizeThreshold, rawValue, err := dsnQuery.IntOption("batch_size_threshold", 7*1024*1024)
-	if err != nil {
-		return nil, fmt.Errorf("batch size threshold option %q is not a valid number: %w", rawValue, err)
-	}
-
-	// Use batch ops threshold if present, otherwise use 0 (unlimited)
-	batchOpsThreshold, rawValue, err := dsnQuery.IntOption("batch_ops_threshold", 0)
-	if err != nil {
-		return nil, fmt.Errorf("batch ops threshold option %q is not a valid number: %w", rawValue, err)
-	}
-
-	// Use batch time threshold if present, otherwise use 0 (unlimited)
-	batchTimeThreshold, rawValue, err := dsnQuery.DurationOption("batch_time_threshold", 0)
+	writeOptions, err := store.NewWriteBufferOptions(dsn.Query(), store.WriteBufferOptions{SizeThreshold: defaultBatchSizeThreshold})
 	if err != nil {
-		return nil, fmt.Errorf("batch time threshold option %q is not a valid duration: %w", rawValue, err)
+		return nil, err
 	}
 
-	batcher := store.NewBatchOp(batchSizeThreshold, batchOpsThreshold, batchTimeThreshold)
-
 	zlog.Info("creating store instance",
 		zap.String("dsn", dsnString),
 		zap.String("key_prefix", keyPrefix),
 		zap.Object("compressor", compressor),
-		zap.Object("batcher", batcher),
+		zap.Object("write_buffer", writeOptions),
 	)
 
 	client, err := rawkv.NewClient(context.Background(), hosts, config.DefaultConfig().Security)
@@ -115,18 +126,19 @@ func NewStore(dsnString string) (store.KVStore, error) {
 	}
 
 	s := &Store{
-		dsn:        dsnString,
-		client:     client,
-		batchPut:   batcher,
-		compressor: compressor,
-		keyPrefix:  []byte(keyPrefix),
+		dsn:         dsnString,
+		client:      client,
+		pdAddresses: hosts,
+		compressor:  compressor,
+		keyPrefix:   []byte(keyPrefix),
 	}
+	s.writes = store.NewWriteBuffer(writeOptions, s.batchPut)
 
 	return s, nil
 }
 
 func (s *Store) Close() error {
-	return s.client.Close()
+	return multierr.Append(s.writes.Close(), s.client.Close())
 }
 
 func (s *Store) Put(ctx context.Context, key, value []byte) (err error) {
@@ -134,48 +146,33 @@ func (s *Store) Put(ctx context.Context, key, value []byte) (err error) {
 		return fmt.Errorf("empty value not supported by this store, if you expect to need to store empty value, please use `store.WithEmptyValue()` when creating the store to enable them")
 	}
 
-	formattedKey := s.withPrefix(key)
-	formattedValue := s.formatValue(value)
-
-	if s.batchPut.WouldFlushNext(formattedKey, formattedValue) {
-		err := s.FlushPuts(ctx)
-		if err != nil {
-			return err
-		}
-	}
-
-	s.batchPut.Op(formattedKey, formattedValue)
-	return nil
+	return s.writes.Put(ctx, key, value)
 }
 
 func (s *Store) FlushPuts(ctx context.Context) error {
 	if tracer.Enabled() {
-		logging.Debug(ctx, zlog, "flushing batch", zap.Object("batch", s.batchPut))
+		logging.Debug(ctx, zlog, "flushing batch", zap.Object("batch", s.writes))
 	}
 
-	kvs := s.batchPut.GetBatch()
-	if len(kvs) == 0 {
-		return nil
-	}
+	return s.writes.Flush(ctx)
+}
 
+func (s *Store) batchPut(ctx context.Context, kvs []store.KV) error {
 	keys := make([][]byte, len(kvs))
 	values := make([][]byte, len(kvs))
 	for idx, kv := range kvs {
-		// The key & value must not be prefixed/formatted here as they already been processed when added to the batch directly
-		keys[idx] = kv.Key
-		values[idx] = kv.Value
-	}
-
-	err := s.client.BatchPut(ctx, keys, values)
-	if err != nil {
-		return err
+		keys[idx] = s.withPrefix(kv.Key)
+		values[idx] = s.formatValue(kv.Value)
 	}
 
-	s.batchPut.Reset()
-	return nil
+	return s.client.BatchPut(ctx, keys, values)
 }
 
 func (s *Store) Get(ctx context.Context, key []byte) ([]byte, error) {
+	if value, found := s.writes.Get(key); found {
+		return value, nil
+	}
+
 	val, err := s.client.Get(ctx, s.withPrefix(key))
 	if err != nil {
 		return nil, err
@@ -204,6 +201,10 @@ func (s *Store) Get(ctx context.Context, key []byte) ([]byte, error) {
 }
 
 func (s *Store) BatchGet(ctx context.Context, keys [][]byte) *store.Iterator {
+	return s.writes.OverlayBatchGet(ctx, keys, s.batchGet)
+}
+
+func (s *Store) batchGet(ctx context.Context, keys [][]byte) *store.Iterator {
 	if tracer.Enabled() {
 		logging.Debug(ctx, zlog, "batch get", zap.Int("key_count", len(keys)))
 	}
@@ -223,11 +224,19 @@ func (s *Store) BatchGet(ctx context.Context, keys [][]byte) *store.Iterator {
 
 		if len(rawValues) != len(keys) {
 			kr.PushError(fmt.Errorf("no enough values received from cluster, have %d keys but got only %d values", len(keys), len(rawValues)))
+			return
 		}
 
 		for i, rawValue := range rawValues {
 			// The key must **not** be unprefixed here because it's the one from the loop which is already unprefixed
 			key := keys[i]
+
+			// Like in `Get`, a stored value is never `nil` so a `nil` value is a missing key
+			if rawValue == nil {
+				kr.PushError(store.ErrNotFound)
+				return
+			}
+
 			value, err := s.unformatValue(rawValue)
 			if err != nil {
 				kr.PushError(fmt.Errorf("unformat value of %x: %w", key, err))
@@ -243,7 +252,80 @@ func (s *Store) BatchGet(ctx context.Context, keys [][]byte) *store.Iterator {
 	return kr
 }
 
+// Exists checks the key with a key-only scan of the single key range, so the value is never transferred.
+func (s *Store) Exists(ctx context.Context, key []byte) (bool, error) {
+	if _, found := s.writes.Get(key); found {
+		return true, nil
+	}
+
+	formattedKey := s.withPrefix(key)
+
+	keys, _, err := s.client.Scan(ctx, formattedKey, store.Key(formattedKey).Next(), 1, rawkv.ScanKeyOnly())
+	if err != nil {
+		return false, err
+	}
+
+	return len(keys) > 0, nil
+}
+
+// BatchExists checks each key with its own key only scan, running up to
+// `batchExistsConcurrentScans` of them concurrently, the first failing one cancels the others.
+func (s *Store) BatchExists(ctx context.Context, keys [][]byte) ([]bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	out := make([]bool, len(keys))
+	slots := make(chan struct{}, batchExistsConcurrentScans)
+
+	var wg sync.WaitGroup
+	var failOnce sync.Once
+	var failure error
+
+checks:
+	for i, key := range keys {
+		select {
+		case <-ctx.Done():
+			break checks
+		case slots <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int, key []byte) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			exists, err := s.Exists(ctx, key)
+			if err != nil {
+				failOnce.Do(func() {
+					failure = err
+					cancel()
+				})
+				return
+			}
+			out[i] = exists
+		}(i, key)
+	}
+	wg.Wait()
+
+	if failure != nil {
+		return nil, failure
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *Store) Delete(ctx context.Context, key []byte) error {
+	s.writes.Discard(key)
+	return s.client.Delete(ctx, s.withPrefix(key))
+}
+
 func (s *Store) BatchDelete(ctx context.Context, keys [][]byte) error {
+	s.writes.Discard(keys...)
+
 	prefixedKeys := make([][]byte, len(keys))
 	for i, key := range keys {
 		prefixedKeys[i] = s.withPrefix(key)
@@ -261,7 +343,10 @@ func (s *Store) Scan(ctx context.Context, start, exclusiveEnd []byte, limit int,
 		)
 	}
 
-	return s.scanIterator(ctx, zlogger, s.withPrefix(start), s.withPrefix(exclusiveEnd), store.Limit(limit), options)
+	it := store.NewScanIterator(ctx, start, exclusiveEnd, limit, options)
+	it = s.scanIterator(ctx, it, zlogger, s.withPrefix(start), s.withPrefix(exclusiveEnd), store.Limit(limit), options)
+
+	return s.writes.OverlayScan(it, start, exclusiveEnd, limit, options)
 }
 
 func (s *Store) Prefix(ctx context.Context, prefix []byte, limit int, options ...store.ReadOption) *store.Iterator {
@@ -277,7 +362,10 @@ func (s *Store) Prefix(ctx context.Context, prefix []byte, limit int, options ..
 		exclusiveEnd = nil
 	}
 
-	return s.scanIterator(ctx, zlogger, startKey, exclusiveEnd, store.Limit(limit), options)
+	it := store.NewPrefixIterator(ctx, prefix, limit, options)
+	it = s.scanIterator(ctx, it, zlogger, startKey, exclusiveEnd, store.Limit(limit), options)
+
+	return s.writes.OverlayPrefix(it, prefix, limit, options)
 }
 
 func (s *Store) BatchPrefix(ctx context.Context, prefixes [][]byte, limit int, options ...store.ReadOption) *store.Iterator {
@@ -298,7 +386,7 @@ func (s *Store) BatchPrefix(ctx context.Context, prefixes [][]byte, limit int, o
 	//       Another possibility would be to accept an option that would tell us that order
 	//       does not matter and that caller is ok receiving keys in any order. It think this
 	//       would be the best option for TiKV.
-	it := store.NewIterator(ctx)
+	it := store.NewBatchPrefixIterator(ctx, prefixes, limit, options)
 	go func() {
 		count := uint64(0)
 		limit := store.Limit(limit)
@@ -346,11 +434,10 @@ func (s *Store) BatchPrefix(ctx context.Context, prefixes [][]byte, limit int, o
 		it.PushFinished()
 	}()
 
-	return it
+	return s.writes.OverlayBatchPrefix(it, prefixes, limit, options)
 }
 
-func (s *Store) scanIterator(ctx context.Context, zlogger *zap.Logger, startKey, exclusiveEnd []byte, limit store.Limit, options []store.ReadOption) *store.Iterator {
-	it := store.NewIterator(ctx)
+func (s *Store) scanIterator(ctx context.Context, it *store.Iterator, zlogger *zap.Logger, startKey, exclusiveEnd []byte, limit store.Limit, options []store.ReadOption) *store.Iterator {
 	go func() {
 		err := s.scan(ctx, zlogger, startKey, exclusiveEnd, limit, options, func(kv store.KV) bool {
 			if !it.PushItem(kv) {
@@ -372,7 +459,13 @@ func (s *Store) scanIterator(ctx context.Context, zlogger *zap.Logger, startKey,
 
 func (s *Store) scan(ctx context.Context, zlogger *zap.Logger, startKey, exclusiveEnd []byte, limit store.Limit, options []store.ReadOption, onKV func(kv store.KV) bool) (err error) {
 	readOptions := store.NewReadOptions(options...)
-	scanOptions := tikvScanOption(readOptions)
+	filter, err := store.NewReadFilter(readOptions)
+	if err != nil {
+		return err
+	}
+
+	scanOptions := tikvScanOption(readOptions, filter)
+	stripValues := readOptions != nil && readOptions.KeyOnly && filter.NeedsValue()
 
 	if tracer.Enabled() {
 		zlogger.Debug("scanning",
@@ -383,11 +476,17 @@ func (s *Store) scan(ctx context.Context, zlogger *zap.Logger, startKey, exclusi
 		)
 	}
 
+	pageSize := uint64(rawkv.MaxRawKVScanLimit)
+	if readOptions != nil && readOptions.PrefetchSize > 0 && uint64(readOptions.PrefetchSize) < pageSize {
+		pageSize = uint64(readOptions.PrefetchSize)
+	}
+
 	count := uint64(0)
 
 	for {
-		sliceSize := uint64(rawkv.MaxRawKVScanLimit)
-		if limit.Bounded() {
+		// With a filter, the items still missing cannot be used to size the page as some of them will be filtered out
+		sliceSize := pageSize
+		if limit.Bounded() && filter == nil {
 			missingCount := uint64(limit) - count
 			if missingCount < sliceSize {
 				sliceSize = missingCount
@@ -400,13 +499,22 @@ func (s *Store) scan(ctx context.Context, zlogger *zap.Logger, startKey, exclusi
 		}
 
 		for i, key := range keys {
-			count++
 			value, err := s.unformatValue(values[i])
 			if err != nil {
 				return fmt.Errorf("unformat value for key %x: %w", key, err)
 			}
 
-			shouldContinue := onKV(store.KV{Key: s.withoutPrefix(key), Value: value})
+			key = s.withoutPrefix(key)
+			if !filter.Match(key, value) {
+				continue
+			}
+
+			if stripValues {
+				value = nil
+			}
+
+			count++
+			shouldContinue := onKV(store.KV{Key: key, Value: value})
 			if !shouldContinue {
 				return nil
 			}
@@ -471,12 +579,13 @@ func (s *Store) unformatValue(v []byte) (out []byte, err error) {
 	return v, nil
 }
 
-func tikvScanOption(readOptions *store.ReadOptions) (out []rawkv.ScanOption) {
+func tikvScanOption(readOptions *store.ReadOptions, filter *store.ReadFilter) (out []rawkv.ScanOption) {
 	if readOptions == nil {
 		return nil
 	}
 
-	if readOptions.KeyOnly {
+	// Values are required to apply the filter, they are stripped afterward
+	if readOptions.KeyOnly && !filter.NeedsValue() {
 		out = append(out, rawkv.ScanKeyOnly())
 	}
 
@@ -77,6 +77,29 @@ func (q DSNQuery) IntOption(name string, defaultValue int) (int, string, error)
 	return value, rawValue, err
 }
 
+// BoolOption reads a boolean option, accepting the values of `strconv.ParseBool` as well as
+// `yes` and `no`.
+func (q DSNQuery) BoolOption(name string, defaultValue bool) (bool, string, error) {
+	rawValue := url.Values(q).Get(name)
+	if rawValue == "" {
+		return defaultValue, rawValue, nil
+	}
+
+	value, err := parseBool(rawValue)
+	return value, rawValue, err
+}
+
+func parseBool(rawValue string) (bool, error) {
+	switch rawValue {
+	case "yes":
+		return true, nil
+	case "no":
+		return false, nil
+	}
+
+	return strconv.ParseBool(rawValue)
+}
+
 func (q DSNQuery) DurationOption(name string, defaultValue time.Duration) (time.Duration, string, error) {
 	rawValue := url.Values(q).Get(name)
 	if rawValue == "" {
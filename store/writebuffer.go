@@ -0,0 +1,828 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DSN query parameters configuring the write buffer of drivers, accepted by all drivers.
+const (
+	BatchSizeThresholdOption = "batch_size_threshold"
+	BatchOpsThresholdOption  = "batch_ops_threshold"
+	BatchTimeThresholdOption = "batch_time_threshold"
+	BatchAsyncOption         = "batch_async"
+	BatchMaxInFlightOption   = "batch_max_in_flight_bytes"
+	ReadYourWritesOption     = "read_your_writes"
+)
+
+// WriteBufferOptions configures a `WriteBuffer`, a zero threshold means no limit.
+type WriteBufferOptions struct {
+	// SizeThreshold is the number of bytes (keys and values) above which pending puts are flushed.
+	SizeThreshold int
+	// OpsThreshold is the number of pending puts at which they are flushed.
+	OpsThreshold int
+	// TimeThreshold is the time since the last flush after which pending puts are flushed on the next put.
+	TimeThreshold time.Duration
+	// ReadYourWrites makes pending puts visible to the reads of the driver supporting it.
+	ReadYourWrites bool
+	// Async writes the pending puts from a background goroutine, on the time threshold even
+	// when no put comes in, instead of from the `Put` call reaching a threshold.
+	Async bool
+	// MaxInFlightBytes bounds the bytes handed to the background goroutine but not yet
+	// written, `Put` blocks until enough are written. A zero value allows a single batch.
+	MaxInFlightBytes int
+}
+
+// NewWriteBufferOptions reads the `batch_size_threshold` (bytes), `batch_ops_threshold`
+// (count), `batch_time_threshold` (duration), `batch_async` (bool), `batch_max_in_flight_bytes`
+// (bytes) and `read_your_writes` (bool) DSN query parameters, using `defaults` for the ones
+// not present.
+func NewWriteBufferOptions(query url.Values, defaults WriteBufferOptions) (out WriteBufferOptions, err error) {
+	dsnQuery := DSNQuery(query)
+	var rawValue string
+
+	if out.SizeThreshold, rawValue, err = dsnQuery.IntOption(BatchSizeThresholdOption, defaults.SizeThreshold); err != nil {
+		return out, fmt.Errorf("batch size threshold option %q is not a valid number: %w", rawValue, err)
+	}
+
+	if out.OpsThreshold, rawValue, err = dsnQuery.IntOption(BatchOpsThresholdOption, defaults.OpsThreshold); err != nil {
+		return out, fmt.Errorf("batch ops threshold option %q is not a valid number: %w", rawValue, err)
+	}
+
+	if out.TimeThreshold, rawValue, err = dsnQuery.DurationOption(BatchTimeThresholdOption, defaults.TimeThreshold); err != nil {
+		return out, fmt.Errorf("batch time threshold option %q is not a valid duration: %w", rawValue, err)
+	}
+
+	if out.MaxInFlightBytes, rawValue, err = dsnQuery.IntOption(BatchMaxInFlightOption, defaults.MaxInFlightBytes); err != nil {
+		return out, fmt.Errorf("batch max in flight bytes option %q is not a valid number: %w", rawValue, err)
+	}
+
+	if out.Async, rawValue, err = dsnQuery.BoolOption(BatchAsyncOption, defaults.Async); err != nil {
+		return out, fmt.Errorf("batch async option %q is not a valid boolean: %w", rawValue, err)
+	}
+
+	if out.ReadYourWrites, rawValue, err = dsnQuery.BoolOption(ReadYourWritesOption, defaults.ReadYourWrites); err != nil {
+		return out, fmt.Errorf("read your writes option %q is not a valid boolean: %w", rawValue, err)
+	}
+
+	return out, nil
+}
+
+func (o WriteBufferOptions) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddInt("size_threshold", o.SizeThreshold)
+	encoder.AddInt("ops_threshold", o.OpsThreshold)
+	encoder.AddDuration("time_threshold", o.TimeThreshold)
+	encoder.AddBool("read_your_writes", o.ReadYourWrites)
+	encoder.AddBool("async", o.Async)
+	if o.Async {
+		encoder.AddInt("max_in_flight_bytes", o.MaxInFlightBytes)
+	}
+	return nil
+}
+
+// BatchWriter writes a batch of puts to the backend in a single operation, keys and values
+// are the ones received by `Put`, the writer formats them for the backend.
+type BatchWriter func(ctx context.Context, kvs []KV) error
+
+// WriteBuffer holds the puts of a driver until they are flushed to the backend, either
+// explicitly with `Flush` (the driver's `FlushPuts`) or automatically on `Put` when one of
+// the configured thresholds is reached.
+//
+// When `WriteBufferOptions.Async` is set, batches reaching a threshold are handed to a
+// background goroutine writing them in order, a failed batch is put back in the pending
+// puts and its error is returned by the next `Put` or `Flush` call, as well as reported to
+// the handler set with `SetErrorHandler`.
+//
+// When `WriteBufferOptions.ReadYourWrites` is set, the pending puts, including the ones
+// being written in the background, are visible to the driver's `Get`, `BatchGet`, `Exists`,
+// `Scan`, `Prefix` and `BatchPrefix` through `Get` and the `Overlay` methods.
+type WriteBuffer struct {
+	options WriteBufferOptions
+	write   BatchWriter
+
+	lock      sync.Mutex
+	pending   *writeBatch
+	lastFlush time.Time
+
+	// Only used in async mode, `flushing` holds the batches handed to the background
+	// goroutine, oldest first, `changed` is signaled each time one is written. Writes use
+	// `ctx`, cancelled by `Close` so that a stuck backend does not block it.
+	ctx           context.Context
+	cancel        context.CancelFunc
+	changed       *sync.Cond
+	flushing      []*writeBatch
+	inFlightBytes int
+	asyncErr      error
+	onError       func(err error)
+	closed        bool
+	done          chan struct{}
+}
+
+var errWriteBufferClosed = errors.New("write buffer closed")
+
+type writeBatch struct {
+	kvs   []KV
+	index map[string]int
+	size  int
+}
+
+func newWriteBatch() *writeBatch {
+	return &writeBatch{index: map[string]int{}}
+}
+
+func (b *writeBatch) put(key, value []byte) {
+	if i, found := b.index[string(key)]; found {
+		b.size += len(value) - len(b.kvs[i].Value)
+		b.kvs[i].Value = value
+		return
+	}
+
+	b.index[string(key)] = len(b.kvs)
+	b.kvs = append(b.kvs, KV{Key: key, Value: value})
+	b.size += len(key) + len(value)
+}
+
+func (b *writeBatch) get(key []byte) (value []byte, found bool) {
+	if i, found := b.index[string(key)]; found {
+		return b.kvs[i].Value, true
+	}
+	return nil, false
+}
+
+func (b *writeBatch) containsAny(keys [][]byte) bool {
+	for _, key := range keys {
+		if _, found := b.index[string(key)]; found {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *writeBatch) discard(keys [][]byte) {
+	discarded := false
+	for _, key := range keys {
+		if i, found := b.index[string(key)]; found {
+			b.size -= b.kvs[i].Size()
+			b.kvs[i].Key = nil
+			delete(b.index, string(key))
+			discarded = true
+		}
+	}
+
+	if !discarded {
+		return
+	}
+
+	kept := b.kvs[:0]
+	for _, kv := range b.kvs {
+		if kv.Key != nil {
+			b.index[string(kv.Key)] = len(kept)
+			kept = append(kept, kv)
+		}
+	}
+	b.kvs = kept
+}
+
+func NewWriteBuffer(options WriteBufferOptions, write BatchWriter) *WriteBuffer {
+	b := &WriteBuffer{
+		options:   options,
+		write:     write,
+		pending:   newWriteBatch(),
+		lastFlush: time.Now(),
+	}
+
+	if options.Async {
+		b.ctx, b.cancel = context.WithCancel(context.Background())
+		b.changed = sync.NewCond(&b.lock)
+		b.done = make(chan struct{})
+
+		go b.writeLoop()
+		if options.TimeThreshold > 0 {
+			go b.timeLoop()
+		}
+	}
+
+	return b
+}
+
+func (b *WriteBuffer) Options() WriteBufferOptions {
+	return b.options
+}
+
+// SetErrorHandler sets the function called with the error of each failed background write,
+// it is called from the background goroutine and must not call the buffer back.
+func (b *WriteBuffer) SetErrorHandler(onError func(err error)) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.onError = onError
+}
+
+// Put adds the put to the pending ones, flushing them first when adding it would reach one
+// of the thresholds. A put of a key already pending replaces it.
+//
+// In async mode, the pending puts are handed to the background goroutine instead, `Put`
+// blocks while too many bytes are in flight. It fails once the buffer is closed.
+func (b *WriteBuffer) Put(ctx context.Context, key, value []byte) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		return errWriteBufferClosed
+	}
+
+	if err := b.takeAsyncErr(); err != nil {
+		return err
+	}
+
+	if len(b.pending.kvs) > 0 && b.wouldFlushNext(len(key)+len(value)) {
+		if b.options.Async {
+			if err := b.handOff(ctx); err != nil {
+				return err
+			}
+		} else if err := b.flush(ctx); err != nil {
+			return err
+		}
+	}
+
+	b.pending.put(key, value)
+	return nil
+}
+
+func (b *WriteBuffer) wouldFlushNext(size int) bool {
+	if b.options.SizeThreshold > 0 && b.pending.size+size > b.options.SizeThreshold {
+		return true
+	}
+	if b.options.OpsThreshold > 0 && len(b.pending.kvs)+1 > b.options.OpsThreshold {
+		return true
+	}
+	if b.options.TimeThreshold > 0 && time.Since(b.lastFlush) > b.options.TimeThreshold {
+		return true
+	}
+	return false
+}
+
+// Flush writes the pending puts, they are kept if the write fails. In async mode, it waits
+// for all the batches in flight to be written.
+func (b *WriteBuffer) Flush(ctx context.Context) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if !b.options.Async {
+		return b.flush(ctx)
+	}
+
+	if len(b.pending.kvs) > 0 {
+		if err := b.handOff(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := b.waitFor(ctx, func() bool { return len(b.flushing) == 0 }); err != nil {
+		return err
+	}
+
+	return b.takeAsyncErr()
+}
+
+func (b *WriteBuffer) flush(ctx context.Context) error {
+	if len(b.pending.kvs) == 0 {
+		return nil
+	}
+
+	if err := b.write(ctx, b.pending.kvs); err != nil {
+		return err
+	}
+
+	b.pending = newWriteBatch()
+	b.lastFlush = time.Now()
+	return nil
+}
+
+// handOff moves the pending puts to the batches written in the background, waiting first
+// for enough in flight bytes to be written. Must be called with the lock held.
+func (b *WriteBuffer) handOff(ctx context.Context) error {
+	err := b.waitFor(ctx, func() bool {
+		if len(b.flushing) == 0 {
+			return true
+		}
+		return b.options.MaxInFlightBytes > 0 && b.inFlightBytes+b.pending.size <= b.options.MaxInFlightBytes
+	})
+	if err != nil {
+		return err
+	}
+
+	if b.closed {
+		return errWriteBufferClosed
+	}
+
+	b.flushing = append(b.flushing, b.pending)
+	b.inFlightBytes += b.pending.size
+	b.pending = newWriteBatch()
+	b.lastFlush = time.Now()
+	b.changed.Broadcast()
+	return nil
+}
+
+// waitFor waits for the condition to be true, or for the buffer to be closed, releasing the
+// lock meanwhile. Must be called with the lock held.
+func (b *WriteBuffer) waitFor(ctx context.Context, condition func() bool) error {
+	if condition() || b.closed {
+		return nil
+	}
+
+	// Condition variables cannot wait on a context, waiters are woken up when it is done
+	stop := context.AfterFunc(ctx, func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		b.changed.Broadcast()
+	})
+	defer stop()
+
+	for !condition() && !b.closed {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		b.changed.Wait()
+	}
+	return nil
+}
+
+func (b *WriteBuffer) takeAsyncErr() error {
+	err := b.asyncErr
+	b.asyncErr = nil
+	if err != nil {
+		return fmt.Errorf("background flush: %w", err)
+	}
+	return nil
+}
+
+func (b *WriteBuffer) writeLoop() {
+	defer close(b.done)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for {
+		for len(b.flushing) == 0 {
+			if b.closed {
+				return
+			}
+			b.changed.Wait()
+		}
+
+		batch := b.flushing[0]
+		b.lock.Unlock()
+		err := b.write(b.ctx, batch.kvs)
+		b.lock.Lock()
+
+		b.flushing = b.flushing[1:]
+		b.inFlightBytes -= batch.size
+
+		if err != nil {
+			// Puts made since the batch was handed off are more recent, they win, whether
+			// still pending or in a batch written after this one
+			for _, kv := range batch.kvs {
+				if !b.hasNewerPut(kv.Key) {
+					b.pending.put(kv.Key, kv.Value)
+				}
+			}
+
+			// Writes cancelled by `Close` are reported by it
+			if !b.closed {
+				b.asyncErr = err
+				if b.onError != nil {
+					b.onError(err)
+				}
+			}
+		}
+
+		b.changed.Broadcast()
+	}
+}
+
+// hasNewerPut tells if the key is pending or in a batch in flight, which is more recent than
+// the batch being written. Must be called with the lock held.
+func (b *WriteBuffer) hasNewerPut(key []byte) bool {
+	if _, found := b.pending.index[string(key)]; found {
+		return true
+	}
+
+	for _, batch := range b.flushing {
+		if _, found := batch.index[string(key)]; found {
+			return true
+		}
+	}
+	return false
+}
+
+// minTimeLoopTick bounds how often the time threshold is checked, a tiny threshold would
+// otherwise spin the background goroutine (or make the tick non-positive, `1ns / 2`).
+const minTimeLoopTick = time.Millisecond
+
+func (b *WriteBuffer) timeLoop() {
+	tick := b.options.TimeThreshold / 2
+	if tick < minTimeLoopTick {
+		tick = minTimeLoopTick
+	}
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+		}
+
+		b.lock.Lock()
+		if len(b.pending.kvs) > 0 && time.Since(b.lastFlush) >= b.options.TimeThreshold && !b.closed {
+			// The background context never completes, an error here can only come from closing
+			_ = b.handOff(context.Background())
+		}
+		b.lock.Unlock()
+	}
+}
+
+// Close rejects the next puts and stops the background goroutines, cancelling the batches
+// in flight. Puts are not flushed, call `Flush` first, an error is returned when some of
+// them were not written.
+func (b *WriteBuffer) Close() error {
+	b.lock.Lock()
+	if b.closed {
+		b.lock.Unlock()
+		return nil
+	}
+
+	b.closed = true
+	if b.options.Async {
+		b.cancel()
+		b.changed.Broadcast()
+		b.lock.Unlock()
+
+		<-b.done
+		b.lock.Lock()
+	}
+	defer b.lock.Unlock()
+
+	unwritten := len(b.pending.kvs)
+	for _, batch := range b.flushing {
+		unwritten += len(batch.kvs)
+	}
+	if unwritten > 0 {
+		return fmt.Errorf("write buffer closed with %d puts not written", unwritten)
+	}
+	return nil
+}
+
+// Discard drops the pending puts of the keys, drivers call it when deleting keys so that a
+// later flush does not bring them back. In async mode, it first waits for the batches in
+// flight holding one of the keys to be written.
+func (b *WriteBuffer) Discard(keys ...[]byte) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.options.Async {
+		b.waitFor(context.Background(), func() bool {
+			for _, batch := range b.flushing {
+				if batch.containsAny(keys) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+
+	b.pending.discard(keys)
+}
+
+// Len returns the number of pending puts, not counting the ones being written in the background.
+func (b *WriteBuffer) Len() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return len(b.pending.kvs)
+}
+
+// Get returns the pending value of the key, `found` is always false when read your writes
+// is not enabled.
+func (b *WriteBuffer) Get(key []byte) (value []byte, found bool) {
+	if !b.options.ReadYourWrites {
+		return nil, false
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.get(key)
+}
+
+// get is `Get` without the read your writes check. Must be called with the lock held.
+func (b *WriteBuffer) get(key []byte) (value []byte, found bool) {
+	if value, found := b.pending.get(key); found {
+		return value, true
+	}
+
+	for i := len(b.flushing) - 1; i >= 0; i-- {
+		if value, found := b.flushing[i].get(key); found {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// OverlayBatchGet returns an iterator over the values of the keys, in order, the pending
+// ones being taken from the buffer and the others read from the backend with `read`, the
+// driver's own `BatchGet`. A pending key is not read from the backend, so it does not end
+// the read with `ErrNotFound` when it is not stored yet. `read` gets all the keys when
+// read your writes is not enabled or when none of them is pending.
+func (b *WriteBuffer) OverlayBatchGet(ctx context.Context, keys [][]byte, read func(ctx context.Context, keys [][]byte) *Iterator) *Iterator {
+	if !b.options.ReadYourWrites {
+		return read(ctx, keys)
+	}
+
+	pending := map[int][]byte{}
+	var missing [][]byte
+	b.lock.Lock()
+	for i, key := range keys {
+		if value, found := b.get(key); found {
+			pending[i] = value
+			continue
+		}
+		missing = append(missing, key)
+	}
+	b.lock.Unlock()
+
+	if len(pending) == 0 {
+		return read(ctx, keys)
+	}
+
+	var it *Iterator
+	if len(missing) > 0 {
+		it = read(ctx, missing)
+	}
+
+	out := NewIterator(ctx)
+	go func() {
+		for i, key := range keys {
+			value, found := pending[i]
+			kv := KV{Key: key, Value: value}
+			if !found {
+				if !it.Next() {
+					if err := it.Err(); err != nil {
+						out.PushError(err)
+						return
+					}
+					out.PushError(fmt.Errorf("backend returned less values than the %d keys read", len(missing)))
+					return
+				}
+				kv = it.Item()
+			}
+
+			if !out.PushItem(kv) {
+				return
+			}
+		}
+		out.PushFinished()
+	}()
+
+	return out
+}
+
+// OverlayScan returns an iterator over the items of `it`, read from the backend by a `Scan`
+// call with the same arguments, merged with the pending puts of the range. Pending puts win
+// over backend items of the same key. The iterator is returned as-is when read your writes
+// is not enabled or when no pending put is in the range.
+func (b *WriteBuffer) OverlayScan(it *Iterator, start, exclusiveEnd []byte, limit int, options []ReadOption) *Iterator {
+	return b.overlay(it, Limit(limit), options, func(key []byte) bool {
+		return bytes.Compare(key, start) >= 0 && bytes.Compare(key, exclusiveEnd) < 0
+	})
+}
+
+// OverlayPrefix is like `OverlayScan` for an iterator read from the backend by a `Prefix` call.
+func (b *WriteBuffer) OverlayPrefix(it *Iterator, prefix []byte, limit int, options []ReadOption) *Iterator {
+	return b.overlay(it, Limit(limit), options, func(key []byte) bool {
+		return bytes.HasPrefix(key, prefix)
+	})
+}
+
+// OverlayBatchPrefix is like `OverlayPrefix` for an iterator read from the backend by a
+// `BatchPrefix` call, emitting the items of each prefix in turn. The pending puts of each
+// prefix are merged with the backend items read for it, `limit` applies to all the items.
+func (b *WriteBuffer) OverlayBatchPrefix(it *Iterator, prefixes [][]byte, limit int, options []ReadOption) *Iterator {
+	pending := b.pendingMatching(options, func(key []byte) bool {
+		for _, prefix := range prefixes {
+			if bytes.HasPrefix(key, prefix) {
+				return true
+			}
+		}
+		return false
+	})
+	if len(pending) == 0 {
+		return it
+	}
+
+	out := newOverlayIterator(it, options)
+	go func() {
+		// push returns false once the consumer stopped or the limit is reached
+		count := uint64(0)
+		push := func(kv KV) bool {
+			if !out.PushItem(kv) {
+				return false
+			}
+
+			count++
+			if Limit(limit).Reached(count) {
+				out.PushFinished()
+				return false
+			}
+			return true
+		}
+
+		// Backend items are attributed to the prefix `p` while they match it, follow the
+		// previous one and do not exceed the limit, the next prefixes are considered otherwise.
+		p := 0
+		var prefixPending []KV
+		var lastKey []byte
+		backendCount := uint64(0)
+		startPrefix := func() {
+			prefixPending, lastKey = nil, nil
+			if p < len(prefixes) {
+				for _, kv := range pending {
+					if bytes.HasPrefix(kv.Key, prefixes[p]) {
+						prefixPending = append(prefixPending, kv)
+					}
+				}
+			}
+		}
+		finishPrefix := func() bool {
+			for _, kv := range prefixPending {
+				if !push(kv) {
+					return false
+				}
+			}
+			p++
+			startPrefix()
+			return true
+		}
+		startPrefix()
+
+		for it.Next() {
+			kv := it.Item()
+			for p < len(prefixes) && !(bytes.HasPrefix(kv.Key, prefixes[p]) && !Limit(limit).Reached(backendCount) && (lastKey == nil || bytes.Compare(kv.Key, lastKey) > 0)) {
+				if !finishPrefix() {
+					return
+				}
+			}
+			backendCount++
+			lastKey = kv.Key
+
+			for len(prefixPending) > 0 && bytes.Compare(prefixPending[0].Key, kv.Key) <= 0 {
+				next := prefixPending[0]
+				prefixPending = prefixPending[1:]
+				if bytes.Equal(next.Key, kv.Key) {
+					kv = next
+					break
+				}
+				if !push(next) {
+					return
+				}
+			}
+
+			if !push(kv) {
+				return
+			}
+		}
+
+		if err := it.Err(); err != nil {
+			out.PushError(err)
+			return
+		}
+
+		for p < len(prefixes) {
+			if !finishPrefix() {
+				return
+			}
+		}
+		out.PushFinished()
+	}()
+
+	return out
+}
+
+func (b *WriteBuffer) overlay(it *Iterator, limit Limit, options []ReadOption, match func(key []byte) bool) *Iterator {
+	pending := b.pendingMatching(options, match)
+	if len(pending) == 0 {
+		return it
+	}
+
+	out := newOverlayIterator(it, options)
+
+	pendingIt := NewIterator(it.ctx, WithBufferSize(len(pending)))
+	for _, kv := range pending {
+		pendingIt.PushItem(kv)
+	}
+	pendingIt.PushFinished()
+
+	go func() {
+		merged := Merge(it.ctx, pendingIt, it)
+
+		count := uint64(0)
+		for merged.Next() {
+			if !out.PushItem(merged.Item()) {
+				return
+			}
+
+			count++
+			if limit.Reached(count) {
+				out.PushFinished()
+				return
+			}
+		}
+
+		if err := merged.Err(); err != nil {
+			out.PushError(err)
+			return
+		}
+		out.PushFinished()
+	}()
+
+	return out
+}
+
+// newOverlayIterator returns the iterator emitting the items of `it` merged with pending
+// puts, it provides the cursor `it` would have.
+func newOverlayIterator(it *Iterator, options []ReadOption) *Iterator {
+	out := NewIterator(it.ctx, options...)
+	if it.cursor != nil {
+		out.cursor = &iteratorCursor{request: it.cursor.request, lastKey: it.cursor.lastKey, limit: it.cursor.limit}
+	}
+	return out
+}
+
+// pendingMatching returns the pending puts of the keys matching `match` and the read
+// options, sorted by key, none when read your writes is not enabled.
+func (b *WriteBuffer) pendingMatching(options []ReadOption, match func(key []byte) bool) []KV {
+	if !b.options.ReadYourWrites {
+		return nil
+	}
+
+	readOptions := NewReadOptions(options...)
+	filter, err := NewReadFilter(readOptions)
+	if err != nil {
+		// The driver reports the invalid options through the backend iterator
+		return nil
+	}
+
+	// Batches are visited newest first, so the most recent put of a key wins
+	var pending []KV
+	seen := map[string]bool{}
+	b.lock.Lock()
+	for i := len(b.flushing); i >= 0; i-- {
+		batch := b.pending
+		if i < len(b.flushing) {
+			batch = b.flushing[i]
+		}
+
+		for _, kv := range batch.kvs {
+			if seen[string(kv.Key)] {
+				continue
+			}
+			seen[string(kv.Key)] = true
+
+			if match(kv.Key) && filter.Match(kv.Key, kv.Value) {
+				if readOptions != nil && readOptions.KeyOnly {
+					kv.Value = nil
+				}
+				pending = append(pending, kv)
+			}
+		}
+	}
+	b.lock.Unlock()
+
+	sort.Slice(pending, func(i, j int) bool { return bytes.Compare(pending[i].Key, pending[j].Key) < 0 })
+	return pending
+}
+
+func (b *WriteBuffer) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	encoder.AddInt("pending_puts", len(b.pending.kvs))
+	encoder.AddInt("pending_bytes", b.pending.size)
+	if b.options.Async {
+		encoder.AddInt("in_flight_batches", len(b.flushing))
+		encoder.AddInt("in_flight_bytes", b.inFlightBytes)
+	}
+	encoder.AddDuration("since_last_flush", time.Since(b.lastFlush))
+	return encoder.AddObject("options", b.options)
+}
@@ -0,0 +1,437 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWriteBuffer(options WriteBufferOptions, backend *memoryKVStore) (*WriteBuffer, *int) {
+	writes := 0
+	return NewWriteBuffer(options, func(ctx context.Context, kvs []KV) error {
+		writes++
+		for _, kv := range kvs {
+			backend.set(kv.Key, kv.Value)
+		}
+		return nil
+	}), &writes
+}
+
+func TestWriteBuffer_Thresholds(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       WriteBufferOptions
+		expectWrites  int
+		expectPending int
+	}{
+		{"no threshold", WriteBufferOptions{}, 0, 4},
+		{"ops threshold", WriteBufferOptions{OpsThreshold: 3}, 1, 1},
+		{"size threshold", WriteBufferOptions{SizeThreshold: 9}, 1, 1},
+		{"time threshold", WriteBufferOptions{TimeThreshold: time.Nanosecond}, 3, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend := newMemoryKVStore()
+			buffer, writes := newTestWriteBuffer(test.options, backend)
+
+			for _, kv := range testKVs("a", "b", "c", "d") {
+				time.Sleep(time.Millisecond)
+				require.NoError(t, buffer.Put(context.Background(), kv.Key, kv.Value))
+			}
+
+			assert.Equal(t, test.expectWrites, *writes)
+			assert.Equal(t, test.expectPending, buffer.Len())
+			assert.Len(t, backend.kvs, 4-test.expectPending)
+
+			require.NoError(t, buffer.Flush(context.Background()))
+			assert.Equal(t, 0, buffer.Len())
+			assert.Equal(t, testKVs("a", "b", "c", "d"), backend.kvs)
+		})
+	}
+}
+
+func TestWriteBuffer_PutReplacesPending(t *testing.T) {
+	backend := newMemoryKVStore()
+	buffer, writes := newTestWriteBuffer(WriteBufferOptions{}, backend)
+
+	require.NoError(t, buffer.Put(context.Background(), []byte("a"), []byte("1")))
+	require.NoError(t, buffer.Put(context.Background(), []byte("a"), []byte("2")))
+	assert.Equal(t, 1, buffer.Len())
+
+	require.NoError(t, buffer.Flush(context.Background()))
+	require.NoError(t, buffer.Flush(context.Background()))
+	assert.Equal(t, 1, *writes, "flushing without pending puts does not write")
+	assert.Equal(t, []KV{{Key: []byte("a"), Value: []byte("2")}}, backend.kvs)
+}
+
+func TestWriteBuffer_FlushError(t *testing.T) {
+	failure := errors.New("unavailable")
+	buffer := NewWriteBuffer(WriteBufferOptions{}, func(ctx context.Context, kvs []KV) error {
+		return failure
+	})
+
+	require.NoError(t, buffer.Put(context.Background(), []byte("a"), []byte("1")))
+	assert.Equal(t, failure, buffer.Flush(context.Background()))
+	assert.Equal(t, 1, buffer.Len(), "pending puts are kept on failed flush")
+}
+
+func TestWriteBuffer_Discard(t *testing.T) {
+	backend := newMemoryKVStore()
+	buffer, _ := newTestWriteBuffer(WriteBufferOptions{ReadYourWrites: true}, backend)
+
+	for _, kv := range testKVs("a", "b", "c") {
+		require.NoError(t, buffer.Put(context.Background(), kv.Key, kv.Value))
+	}
+
+	buffer.Discard([]byte("b"), []byte("z"))
+	assert.Equal(t, 2, buffer.Len())
+
+	_, found := buffer.Get([]byte("b"))
+	assert.False(t, found)
+
+	value, found := buffer.Get([]byte("c"))
+	assert.True(t, found)
+	assert.Equal(t, []byte("vc"), value)
+
+	require.NoError(t, buffer.Flush(context.Background()))
+	assert.Equal(t, testKVs("a", "c"), backend.kvs)
+}
+
+func TestWriteBuffer_Get(t *testing.T) {
+	buffer, _ := newTestWriteBuffer(WriteBufferOptions{}, newMemoryKVStore())
+	require.NoError(t, buffer.Put(context.Background(), []byte("a"), []byte("1")))
+
+	_, found := buffer.Get([]byte("a"))
+	assert.False(t, found, "pending puts are not visible without read your writes")
+}
+
+func TestWriteBuffer_Overlay(t *testing.T) {
+	backend := newMemoryKVStore(testKVs("a", "c", "e")...)
+	buffer, _ := newTestWriteBuffer(WriteBufferOptions{ReadYourWrites: true}, backend)
+
+	for _, kv := range []KV{{Key: []byte("b"), Value: []byte("pending")}, {Key: []byte("c"), Value: []byte("pending")}, {Key: []byte("x"), Value: []byte("pending")}} {
+		require.NoError(t, buffer.Put(context.Background(), kv.Key, kv.Value))
+	}
+
+	scan := func(start, end string, limit int, options ...ReadOption) []KV {
+		t.Helper()
+
+		it := backend.Scan(context.Background(), []byte(start), []byte(end), limit, options...)
+		kvs, err := Collect(buffer.OverlayScan(it, []byte(start), []byte(end), limit, options))
+		require.NoError(t, err)
+		return kvs
+	}
+
+	assert.Equal(t, []KV{
+		{Key: []byte("a"), Value: []byte("va")},
+		{Key: []byte("b"), Value: []byte("pending")},
+		{Key: []byte("c"), Value: []byte("pending")},
+		{Key: []byte("e"), Value: []byte("ve")},
+	}, scan("a", "f", Unlimited))
+
+	assert.Equal(t, []KV{
+		{Key: []byte("a"), Value: []byte("va")},
+		{Key: []byte("b"), Value: []byte("pending")},
+	}, scan("a", "f", 2))
+
+	assert.Equal(t, []KV{{Key: []byte("b")}, {Key: []byte("c")}}, scan("b", "d", Unlimited, KeyOnly()))
+	assert.Empty(t, scan("a", "", Unlimited), "empty end is an empty range")
+
+	kvs, err := Collect(buffer.OverlayPrefix(backend.Prefix(context.Background(), []byte("x"), Unlimited), []byte("x"), Unlimited, nil))
+	require.NoError(t, err)
+	assert.Equal(t, []KV{{Key: []byte("x"), Value: []byte("pending")}}, kvs)
+}
+
+func TestNewWriteBufferOptions(t *testing.T) {
+	defaults := WriteBufferOptions{SizeThreshold: 100, TimeThreshold: time.Second}
+
+	tests := []struct {
+		query       string
+		expect      WriteBufferOptions
+		expectError bool
+	}{
+		{"", defaults, false},
+		{"batch_size_threshold=10&batch_ops_threshold=5&batch_time_threshold=250ms&read_your_writes=true", WriteBufferOptions{SizeThreshold: 10, OpsThreshold: 5, TimeThreshold: 250 * time.Millisecond, ReadYourWrites: true}, false},
+		{"batch_ops_threshold=5", WriteBufferOptions{SizeThreshold: 100, OpsThreshold: 5, TimeThreshold: time.Second}, false},
+		{"batch_async=true&batch_max_in_flight_bytes=1000", WriteBufferOptions{SizeThreshold: 100, TimeThreshold: time.Second, Async: true, MaxInFlightBytes: 1000}, false},
+		{"batch_size_threshold=big", WriteBufferOptions{}, true},
+		{"batch_async=sometimes", WriteBufferOptions{}, true},
+		{"batch_time_threshold=10", WriteBufferOptions{}, true},
+		{"read_your_writes=maybe", WriteBufferOptions{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			query, err := url.ParseQuery(test.query)
+			require.NoError(t, err)
+
+			options, err := NewWriteBufferOptions(query, defaults)
+			if test.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expect, options)
+		})
+	}
+}
+
+type testAsyncWriter struct {
+	lock    sync.Mutex
+	written []string
+	failing int
+	release chan struct{}
+}
+
+func (w *testAsyncWriter) write(ctx context.Context, kvs []KV) error {
+	if w.release != nil {
+		<-w.release
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.failing > 0 {
+		w.failing--
+		return errors.New("unavailable")
+	}
+
+	for _, kv := range kvs {
+		w.written = append(w.written, string(kv.Key))
+	}
+	return nil
+}
+
+func (w *testAsyncWriter) writtenKeys() []string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return append([]string(nil), w.written...)
+}
+
+func TestWriteBuffer_AsyncTimeThreshold(t *testing.T) {
+	writer := &testAsyncWriter{}
+	buffer := NewWriteBuffer(WriteBufferOptions{Async: true, TimeThreshold: 10 * time.Millisecond}, writer.write)
+	defer buffer.Close()
+
+	require.NoError(t, buffer.Put(context.Background(), []byte("a"), []byte("1")))
+
+	assert.Eventually(t, func() bool { return len(writer.writtenKeys()) == 1 }, time.Second, 5*time.Millisecond, "quiet writer is flushed in the background")
+}
+
+func TestWriteBuffer_AsyncTinyTimeThreshold(t *testing.T) {
+	options, err := NewWriteBufferOptions(url.Values{"batch_async": {"true"}, "batch_time_threshold": {"1ns"}}, WriteBufferOptions{})
+	require.NoError(t, err)
+
+	writer := &testAsyncWriter{}
+	buffer := NewWriteBuffer(options, writer.write)
+	defer buffer.Close()
+
+	require.NoError(t, buffer.Put(context.Background(), []byte("a"), []byte("1")))
+
+	assert.Eventually(t, func() bool { return len(writer.writtenKeys()) == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestWriteBuffer_AsyncBackpressure(t *testing.T) {
+	writer := &testAsyncWriter{release: make(chan struct{})}
+	buffer := NewWriteBuffer(WriteBufferOptions{Async: true, OpsThreshold: 1, ReadYourWrites: true}, writer.write)
+	defer buffer.Close()
+
+	require.NoError(t, buffer.Put(context.Background(), []byte("a"), []byte("1")))
+	require.NoError(t, buffer.Put(context.Background(), []byte("b"), []byte("2")))
+
+	value, found := buffer.Get([]byte("a"))
+	assert.True(t, found, "puts being written are visible")
+	assert.Equal(t, []byte("1"), value)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, buffer.Put(ctx, []byte("c"), []byte("3")), "put blocks while a batch is in flight")
+
+	close(writer.release)
+	require.NoError(t, buffer.Put(context.Background(), []byte("c"), []byte("3")))
+	require.NoError(t, buffer.Flush(context.Background()))
+	assert.Equal(t, []string{"a", "b", "c"}, writer.writtenKeys())
+}
+
+func TestWriteBuffer_AsyncError(t *testing.T) {
+	writer := &testAsyncWriter{failing: 1}
+	buffer := NewWriteBuffer(WriteBufferOptions{Async: true, OpsThreshold: 1}, writer.write)
+	defer buffer.Close()
+
+	handled := make(chan error, 1)
+	buffer.SetErrorHandler(func(err error) { handled <- err })
+
+	require.NoError(t, buffer.Put(context.Background(), []byte("a"), []byte("1")))
+	require.NoError(t, buffer.Put(context.Background(), []byte("b"), []byte("2")))
+
+	select {
+	case err := <-handled:
+		assert.EqualError(t, err, "unavailable")
+	case <-time.After(time.Second):
+		t.Fatal("error handler not called")
+	}
+
+	assert.EqualError(t, buffer.Put(context.Background(), []byte("c"), []byte("3")), "background flush: unavailable", "next put reports the error")
+	assert.Equal(t, 2, buffer.Len(), "failed batch is pending again")
+
+	require.NoError(t, buffer.Put(context.Background(), []byte("c"), []byte("3")))
+	require.NoError(t, buffer.Flush(context.Background()))
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, writer.writtenKeys())
+}
+
+func TestWriteBuffer_AsyncClose(t *testing.T) {
+	writer := &testAsyncWriter{}
+	buffer := NewWriteBuffer(WriteBufferOptions{Async: true, OpsThreshold: 1, TimeThreshold: time.Hour}, writer.write)
+
+	require.NoError(t, buffer.Put(context.Background(), []byte("a"), []byte("1")))
+	require.NoError(t, buffer.Put(context.Background(), []byte("b"), []byte("2")))
+	assert.EqualError(t, buffer.Close(), "write buffer closed with 1 puts not written", "pending puts are reported")
+	require.NoError(t, buffer.Close())
+
+	assert.Equal(t, []string{"a"}, writer.writtenKeys(), "batches in flight are written on close")
+	assert.Error(t, buffer.Put(context.Background(), []byte("c"), []byte("3")))
+}
+
+func TestWriteBuffer_Close(t *testing.T) {
+	backend := newMemoryKVStore()
+	buffer, _ := newTestWriteBuffer(WriteBufferOptions{}, backend)
+
+	require.NoError(t, buffer.Put(context.Background(), []byte("a"), []byte("1")))
+	require.NoError(t, buffer.Flush(context.Background()))
+	require.NoError(t, buffer.Close())
+	assert.Error(t, buffer.Put(context.Background(), []byte("b"), []byte("2")), "put after close is rejected")
+
+	buffer, _ = newTestWriteBuffer(WriteBufferOptions{}, backend)
+	require.NoError(t, buffer.Put(context.Background(), []byte("b"), []byte("2")))
+	assert.EqualError(t, buffer.Close(), "write buffer closed with 1 puts not written")
+}
+
+func TestWriteBuffer_AsyncCloseStuckBackend(t *testing.T) {
+	buffer := NewWriteBuffer(WriteBufferOptions{Async: true, OpsThreshold: 1}, func(ctx context.Context, kvs []KV) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	require.NoError(t, buffer.Put(context.Background(), []byte("a"), []byte("1")))
+	require.NoError(t, buffer.Put(context.Background(), []byte("b"), []byte("2")))
+
+	closed := make(chan error, 1)
+	go func() { closed <- buffer.Close() }()
+
+	select {
+	case err := <-closed:
+		assert.EqualError(t, err, "write buffer closed with 2 puts not written", "cancelled batch is reported")
+	case <-time.After(time.Second):
+		t.Fatal("close blocked by the backend")
+	}
+}
+
+func TestWriteBuffer_AsyncErrorKeepsNewerPuts(t *testing.T) {
+	backend := newMemoryKVStore()
+	release := make(chan struct{})
+	writes := 0
+	buffer := NewWriteBuffer(WriteBufferOptions{Async: true, OpsThreshold: 1, MaxInFlightBytes: 1000}, func(ctx context.Context, kvs []KV) error {
+		writes++
+		if writes == 1 {
+			<-release
+			return errors.New("unavailable")
+		}
+
+		for _, kv := range kvs {
+			backend.set(kv.Key, kv.Value)
+		}
+		return nil
+	})
+	defer buffer.Close()
+
+	require.NoError(t, buffer.Put(context.Background(), []byte("a"), []byte("1")))
+	require.NoError(t, buffer.Put(context.Background(), []byte("a"), []byte("2")))
+	require.NoError(t, buffer.Put(context.Background(), []byte("c"), []byte("3")))
+	close(release)
+
+	assert.EqualError(t, buffer.Flush(context.Background()), "background flush: unavailable")
+	require.NoError(t, buffer.Flush(context.Background()))
+
+	value, err := backend.Get(context.Background(), []byte("a"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("2"), value, "failed batch does not overwrite a newer put")
+}
+
+func TestWriteBuffer_OverlayBatchGet(t *testing.T) {
+	backend := newMemoryKVStore(testKVs("a", "c")...)
+	buffer, _ := newTestWriteBuffer(WriteBufferOptions{ReadYourWrites: true}, backend)
+
+	for _, kv := range []KV{{Key: []byte("b"), Value: []byte("pending")}, {Key: []byte("c"), Value: []byte("pending")}} {
+		require.NoError(t, buffer.Put(context.Background(), kv.Key, kv.Value))
+	}
+
+	batchGet := func(keys ...string) ([]KV, error) {
+		rawKeys := make([][]byte, len(keys))
+		for i, key := range keys {
+			rawKeys[i] = []byte(key)
+		}
+		return Collect(buffer.OverlayBatchGet(context.Background(), rawKeys, backend.BatchGet))
+	}
+
+	kvs, err := batchGet("c", "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, []KV{
+		{Key: []byte("c"), Value: []byte("pending")},
+		{Key: []byte("a"), Value: []byte("va")},
+		{Key: []byte("b"), Value: []byte("pending")},
+	}, kvs)
+
+	kvs, err = batchGet("b", "z")
+	assert.Equal(t, ErrNotFound, err)
+	assert.Equal(t, []KV{{Key: []byte("b"), Value: []byte("pending")}}, kvs)
+}
+
+func TestWriteBuffer_OverlayBatchPrefix(t *testing.T) {
+	backend := newMemoryKVStore(testKVs("a1", "a3", "b1", "c1")...)
+	buffer, _ := newTestWriteBuffer(WriteBufferOptions{ReadYourWrites: true}, backend)
+
+	for _, key := range []string{"a2", "a3", "b0", "d1"} {
+		require.NoError(t, buffer.Put(context.Background(), []byte(key), []byte("pending")))
+	}
+
+	batchPrefix := func(limit int, prefixes ...string) []KV {
+		t.Helper()
+
+		rawPrefixes := make([][]byte, len(prefixes))
+		for i, prefix := range prefixes {
+			rawPrefixes[i] = []byte(prefix)
+		}
+
+		it := backend.BatchPrefix(context.Background(), rawPrefixes, limit)
+		kvs, err := Collect(buffer.OverlayBatchPrefix(it, rawPrefixes, limit, nil))
+		require.NoError(t, err)
+		return kvs
+	}
+
+	assert.Equal(t, []KV{
+		{Key: []byte("a1"), Value: []byte("va1")},
+		{Key: []byte("a2"), Value: []byte("pending")},
+		{Key: []byte("a3"), Value: []byte("pending")},
+		{Key: []byte("b0"), Value: []byte("pending")},
+		{Key: []byte("b1"), Value: []byte("vb1")},
+		{Key: []byte("d1"), Value: []byte("pending")},
+	}, batchPrefix(Unlimited, "a", "b", "d"))
+
+	assert.Equal(t, []KV{
+		{Key: []byte("a1"), Value: []byte("va1")},
+		{Key: []byte("a2"), Value: []byte("pending")},
+		{Key: []byte("a3"), Value: []byte("pending")},
+		{Key: []byte("c1"), Value: []byte("vc1")},
+	}, batchPrefix(4, "a", "c", "d"))
+
+	assert.Equal(t, []KV{{Key: []byte("d1"), Value: []byte("pending")}}, batchPrefix(Unlimited, "e", "d"), "prefixes without backend items")
+}